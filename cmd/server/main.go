@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/go-playground/validator"
 	"github.com/labstack/echo/v4"
@@ -45,6 +46,11 @@ func main() {
 
 	e := echo.New()
 	e.HideBanner = true
+	ipExtractor, err := SPMiddleware.IPExtractor(cfg.Server.TrustedProxies)
+	if err != nil {
+		log.Sugar().Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	e.IPExtractor = ipExtractor
 	e.Validator = &SPMiddleware.CustomValidator{Validator: validator.New()}
 	e.Use(middleware.RequestID())
 	e.Use(middleware.Recover())
@@ -68,6 +74,26 @@ func main() {
 
 	log.Sugar().Infof("Server started on port http://%s:%s", cfg.Server.Host, cfg.Server.Port)
 
+	// Periodic cleanup of expired tokens, codes and login attempts
+	cleanupCtx, stopCleanup := context.WithCancel(context.Background())
+	defer stopCleanup()
+	go func() {
+		ticker := time.NewTicker(cfg.Auth.CleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := container.CleanupExpiredRecords.Execute(cleanupCtx); err != nil && cleanupCtx.Err() == nil {
+				log.Sugar().Errorf("Failed to clean up expired records: %v", err)
+			}
+
+			select {
+			case <-cleanupCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
 	// Wait for interrupt signal to gracefully shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -25,8 +25,16 @@ type Config struct {
 	Spotify  SpotifyConfig
 	Apple    AppleConfig
 	Google   GoogleConfig
+	YouTube  YouTubeConfig
+	Deezer   DeezerConfig
+	Tidal    TidalConfig
 	JWT      JWTConfig
 	OAuth    OAuthConfig
+	Auth     AuthConfig
+	Mail     MailConfig
+	// TokenEncryption cifra los tokens de los proveedores OAuth guardados en accounts
+	// y los secretos TOTP
+	TokenEncryption TokenEncryptionConfig
 }
 
 type ServerConfig struct {
@@ -37,6 +45,9 @@ type ServerConfig struct {
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
 	FrontendURL     string
+	// TrustedProxies son los rangos CIDR (separados por comas) de los proxies
+	// cuyo X-Forwarded-For se acepta; vacío usa la IP de la conexión
+	TrustedProxies string
 }
 
 type DatabaseConfig struct {
@@ -63,10 +74,15 @@ type SpotifyConfig struct {
 }
 
 type AppleConfig struct {
+	// ClientID es el Services ID configurado para Sign in with Apple
+	ClientID    string
 	TeamID      string
 	KeyID       string
 	PrivateKey  string
 	RedirectURL string
+	// MusicAPIUrl es la base de la API de Apple Music; la misma key firma los
+	// developer tokens de MusicKit
+	MusicAPIUrl string
 }
 
 type GoogleConfig struct {
@@ -75,17 +91,112 @@ type GoogleConfig struct {
 	RedirectURL  string
 }
 
+// YouTubeConfig usa el cliente OAuth de Google; el flujo de vinculación de
+// youtube pide además el scope de YouTube y vuelve a su propio callback
+type YouTubeConfig struct {
+	RedirectURL string
+	APIUrl      string
+	// DailyQuota son las unidades diarias del proyecto en la YouTube Data API;
+	// las llamadas se rechazan antes de superarlas
+	DailyQuota int
+}
+
+// DeezerConfig usa el OAuth propio de Deezer (connect.deezer.com), que no es
+// OAuth 2.0 estándar; las URLs se pueden apuntar a un fake local
+type DeezerConfig struct {
+	AppID       string
+	Secret      string
+	RedirectURL string
+	ConnectURL  string
+	APIUrl      string
+}
+
+type TidalConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// LoginURL sirve la pantalla de autorización y AuthURL el endpoint de tokens
+	LoginURL string
+	AuthURL  string
+	APIUrl   string
+}
+
 type JWTConfig struct {
 	Secret                string
 	ExpirationTime        time.Duration
 	RefreshExpirationTime time.Duration
+	// KeysDir contiene las claves asimétricas (<kid>.pem); vacío usa HS256 con Secret
+	KeysDir     string
+	ActiveKeyID string
+	// AcceptLegacySecret sigue aceptando tokens HS256 sin kid durante la migración
+	AcceptLegacySecret bool
+}
+
+type TokenEncryptionConfig struct {
+	// Keys es una lista "<kid>:<clave base64 de 32 bytes>" separada por comas
+	Keys string
+	// ActiveKeyID cifra los valores nuevos; puede quedar vacío si hay una sola clave
+	ActiveKeyID string
 }
 
 type OAuthConfig struct {
-	TokenExpiration         time.Duration
+	TokenExpiration time.Duration
+	// FrontendTokenExpiration es la validez del código de un solo uso que el
+	// frontend canjea por los tokens en /v1/oauth/verify
 	FrontendTokenExpiration time.Duration
 }
 
+type AuthConfig struct {
+	// RequireVerifiedEmail impide iniciar sesión hasta verificar el email
+	RequireVerifiedEmail        bool
+	EmailVerificationExpiration time.Duration
+	// EmailVerificationURL es la página del frontend que recibe ?token=
+	EmailVerificationURL string
+	// PasswordResetURL es la página del frontend que recibe ?token= y pide la nueva contraseña
+	PasswordResetURL        string
+	PasswordResetExpiration time.Duration
+	// MFAIssuer es el nombre que muestran las apps de autenticación
+	MFAIssuer              string
+	MFAChallengeExpiration time.Duration
+	// LoginAttemptStore es "postgres" o "memory" (solo para una única instancia)
+	LoginAttemptStore string
+	// Límites de intentos fallidos de login por email y por IP
+	EmailLockout LockoutConfig
+	IPLockout    LockoutConfig
+	// Límites de emails pedidos (restablecer contraseña, reenviar verificación)
+	// por destinatario y por IP, para que no sirvan para inundar un buzón
+	MailEmailLimit LockoutConfig
+	MailIPLimit    LockoutConfig
+	// SessionMode es "token" (refresh token en el body) o "cookie" (refresh
+	// token en una cookie __Host- HttpOnly, protegida con un token CSRF)
+	SessionMode string
+	// CleanupInterval es cada cuánto se borran tokens, códigos e intentos de
+	// login caducados
+	CleanupInterval time.Duration
+}
+
+// LockoutConfig define el backoff exponencial y el bloqueo temporal tras
+// intentos fallidos de login
+type LockoutConfig struct {
+	FreeAttempts     int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	ResetAfter       time.Duration
+}
+
+type MailConfig struct {
+	// Driver es "smtp" o "outbox" (desarrollo: escribe los emails en OutboxDir o en el log)
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	OutboxDir    string
+}
+
 var (
 	instance *Config
 	once     sync.Once
@@ -95,6 +206,11 @@ func (c Config) IsDevelopment() bool {
 	return c.Server.Environment != "production"
 }
 
+// UsesCookieSessions indica si login y refresh entregan el refresh token en cookie
+func (c Config) UsesCookieSessions() bool {
+	return c.Auth.SessionMode == "cookie"
+}
+
 // Get returns the singleton instance of the config
 func Get() *Config {
 	once.Do(func() {
@@ -126,6 +242,7 @@ func load() (*Config, error) {
 			WriteTimeout:    parseDuration(getEnv("WRITE_TIMEOUT", "10s")),
 			ShutdownTimeout: parseDuration(getEnv("SHUTDOWN_TIMEOUT", "5s")),
 			FrontendURL:     getEnv("FRONTEND_URL", "http://localhost:3000"),
+			TrustedProxies:  getEnv("TRUSTED_PROXIES", ""),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -148,24 +265,106 @@ func load() (*Config, error) {
 			APIUrl:       getEnv("SPOTIFY_URL_API", "https://api.spotify.com/v1"),
 		},
 		Apple: AppleConfig{
+			ClientID:    getEnv("APPLE_CLIENT_ID", ""),
 			TeamID:      getEnv("APPLE_TEAM_ID", ""),
 			KeyID:       getEnv("APPLE_KEY_ID", ""),
 			PrivateKey:  getEnv("APPLE_PRIVATE_KEY", ""),
-			RedirectURL: getEnv("APPLE_REDIRECT_URL", "http://127.0.0.1:8080/v1/auth/apple/callback"),
+			RedirectURL: getEnv("APPLE_REDIRECT_URL", "http://127.0.0.1:8080/v1/oauth/apple/callback"),
+			MusicAPIUrl: getEnv("APPLE_MUSIC_URL_API", "https://api.music.apple.com/v1"),
 		},
 		Google: GoogleConfig{
 			ClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
 			ClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
 			RedirectURL:  getEnv("GOOGLE_REDIRECT_URL", "http://127.0.0.1:8080/v1/auth/google/callback"),
 		},
+		YouTube: YouTubeConfig{
+			RedirectURL: getEnv("YOUTUBE_REDIRECT_URL", "http://127.0.0.1:8080/v1/oauth/youtube/callback"),
+			APIUrl:      getEnv("YOUTUBE_URL_API", "https://www.googleapis.com/youtube/v3"),
+			DailyQuota:  parseInt(getEnv("YOUTUBE_DAILY_QUOTA", "10000")),
+		},
+		Deezer: DeezerConfig{
+			AppID:       getEnv("DEEZER_APP_ID", ""),
+			Secret:      getEnv("DEEZER_SECRET", ""),
+			RedirectURL: getEnv("DEEZER_REDIRECT_URL", "http://127.0.0.1:8080/v1/oauth/deezer/callback"),
+			ConnectURL:  getEnv("DEEZER_URL_CONNECT", "https://connect.deezer.com"),
+			APIUrl:      getEnv("DEEZER_URL_API", "https://api.deezer.com"),
+		},
+		Tidal: TidalConfig{
+			ClientID:     getEnv("TIDAL_CLIENT_ID", ""),
+			ClientSecret: getEnv("TIDAL_CLIENT_SECRET", ""),
+			RedirectURL:  getEnv("TIDAL_REDIRECT_URL", "http://127.0.0.1:8080/v1/oauth/tidal/callback"),
+			LoginURL:     getEnv("TIDAL_URL_LOGIN", "https://login.tidal.com"),
+			AuthURL:      getEnv("TIDAL_URL_AUTH", "https://auth.tidal.com/v1"),
+			APIUrl:       getEnv("TIDAL_URL_API", "https://openapi.tidal.com/v2"),
+		},
 		JWT: JWTConfig{
 			Secret:                getEnv("JWT_SECRET", "your-secret-key"),
 			ExpirationTime:        parseDuration(getEnv("JWT_EXPIRATION", "24h")),
 			RefreshExpirationTime: parseDuration(getEnv("JWT_REFRESH_EXPIRATION", "100h")),
+			KeysDir:               getEnv("JWT_KEYS_DIR", ""),
+			ActiveKeyID:           getEnv("JWT_ACTIVE_KEY_ID", ""),
+			AcceptLegacySecret:    parseBool(getEnv("JWT_ACCEPT_LEGACY_SECRET", "false")),
 		},
 		OAuth: OAuthConfig{
 			TokenExpiration:         parseDuration(getEnv("OAUTH_TOKEN_EXPIRATION", "5m")),
-			FrontendTokenExpiration: parseDuration(getEnv("FRONTEND_OAUTH_TOKEN_EXPIRATION", "15m")),
+			FrontendTokenExpiration: parseDuration(getEnv("FRONTEND_OAUTH_TOKEN_EXPIRATION", "2m")),
+		},
+		Auth: AuthConfig{
+			RequireVerifiedEmail:        parseBool(getEnv("AUTH_REQUIRE_VERIFIED_EMAIL", "false")),
+			EmailVerificationExpiration: parseDuration(getEnv("EMAIL_VERIFICATION_EXPIRATION", "24h")),
+			EmailVerificationURL:        getEnv("EMAIL_VERIFICATION_URL", getEnv("FRONTEND_URL", "http://localhost:3000")+"/verify-email"),
+			PasswordResetURL:            getEnv("PASSWORD_RESET_URL", getEnv("FRONTEND_URL", "http://localhost:3000")+"/reset-password"),
+			PasswordResetExpiration:     parseDuration(getEnv("PASSWORD_RESET_EXPIRATION", "1h")),
+			MFAIssuer:                   getEnv("MFA_ISSUER", "Sync Playlist"),
+			MFAChallengeExpiration:      parseDuration(getEnv("MFA_CHALLENGE_EXPIRATION", "5m")),
+			LoginAttemptStore:           getEnv("LOGIN_ATTEMPT_STORE", "postgres"),
+			EmailLockout: LockoutConfig{
+				FreeAttempts:     parseInt(getEnv("LOGIN_EMAIL_FREE_ATTEMPTS", "3")),
+				BaseDelay:        parseDuration(getEnv("LOGIN_EMAIL_BASE_DELAY", "1s")),
+				MaxDelay:         parseDuration(getEnv("LOGIN_EMAIL_MAX_DELAY", "5m")),
+				LockoutThreshold: parseInt(getEnv("LOGIN_EMAIL_LOCKOUT_THRESHOLD", "10")),
+				LockoutDuration:  parseDuration(getEnv("LOGIN_EMAIL_LOCKOUT_DURATION", "15m")),
+				ResetAfter:       parseDuration(getEnv("LOGIN_EMAIL_RESET_AFTER", "1h")),
+			},
+			IPLockout: LockoutConfig{
+				FreeAttempts:     parseInt(getEnv("LOGIN_IP_FREE_ATTEMPTS", "20")),
+				BaseDelay:        parseDuration(getEnv("LOGIN_IP_BASE_DELAY", "1s")),
+				MaxDelay:         parseDuration(getEnv("LOGIN_IP_MAX_DELAY", "5m")),
+				LockoutThreshold: parseInt(getEnv("LOGIN_IP_LOCKOUT_THRESHOLD", "100")),
+				LockoutDuration:  parseDuration(getEnv("LOGIN_IP_LOCKOUT_DURATION", "1h")),
+				ResetAfter:       parseDuration(getEnv("LOGIN_IP_RESET_AFTER", "1h")),
+			},
+			MailEmailLimit: LockoutConfig{
+				FreeAttempts:     parseInt(getEnv("MAIL_EMAIL_FREE_REQUESTS", "1")),
+				BaseDelay:        parseDuration(getEnv("MAIL_EMAIL_BASE_DELAY", "1m")),
+				MaxDelay:         parseDuration(getEnv("MAIL_EMAIL_MAX_DELAY", "1h")),
+				LockoutThreshold: parseInt(getEnv("MAIL_EMAIL_LOCKOUT_THRESHOLD", "0")),
+				LockoutDuration:  parseDuration(getEnv("MAIL_EMAIL_LOCKOUT_DURATION", "0s")),
+				ResetAfter:       parseDuration(getEnv("MAIL_EMAIL_RESET_AFTER", "1h")),
+			},
+			MailIPLimit: LockoutConfig{
+				FreeAttempts:     parseInt(getEnv("MAIL_IP_FREE_REQUESTS", "10")),
+				BaseDelay:        parseDuration(getEnv("MAIL_IP_BASE_DELAY", "30s")),
+				MaxDelay:         parseDuration(getEnv("MAIL_IP_MAX_DELAY", "1h")),
+				LockoutThreshold: parseInt(getEnv("MAIL_IP_LOCKOUT_THRESHOLD", "50")),
+				LockoutDuration:  parseDuration(getEnv("MAIL_IP_LOCKOUT_DURATION", "24h")),
+				ResetAfter:       parseDuration(getEnv("MAIL_IP_RESET_AFTER", "24h")),
+			},
+			SessionMode:     getEnv("SESSION_MODE", "token"),
+			CleanupInterval: parsePositiveDuration(getEnv("AUTH_CLEANUP_INTERVAL", "1h"), time.Hour),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "outbox"),
+			From:         getEnv("MAIL_FROM", "Sync Playlist <no-reply@localhost>"),
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			OutboxDir:    getEnv("MAIL_OUTBOX_DIR", ""),
+		},
+		TokenEncryption: TokenEncryptionConfig{
+			Keys:        getEnv("TOKEN_ENCRYPTION_KEYS", ""),
+			ActiveKeyID: getEnv("TOKEN_ENCRYPTION_ACTIVE_KEY_ID", ""),
 		},
 	}, nil
 }
@@ -188,7 +387,21 @@ func parseInt64(s string) int64 {
 	return i
 }
 
+func parseBool(s string) bool {
+	b, _ := strconv.ParseBool(s)
+	return b
+}
+
 func parseDuration(s string) time.Duration {
 	d, _ := time.ParseDuration(s)
 	return d
 }
+
+// parsePositiveDuration devuelve fallback cuando s no es una duración mayor
+// que cero, para valores que no admiten 0 (time.NewTicker entra en pánico)
+func parsePositiveDuration(s string, fallback time.Duration) time.Duration {
+	if d := parseDuration(s); d > 0 {
+		return d
+	}
+	return fallback
+}
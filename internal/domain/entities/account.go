@@ -15,15 +15,53 @@ const (
 	SpotifyProvider  AccountProvider = "spotify"
 	AppleProvider    AccountProvider = "apple"
 	GoogleProvider   AccountProvider = "google"
+	// YouTubeProvider is a Google account linked with the youtube scope to
+	// manage the user's playlists; it is not a way to sign in
+	YouTubeProvider AccountProvider = "youtube"
+	DeezerProvider  AccountProvider = "deezer"
+	TidalProvider   AccountProvider = "tidal"
+	// AppleMusicProvider holds the Music-User-Token MusicKit JS returns. It is
+	// authorized in the browser rather than through OAuth, does not depend on
+	// Sign in with Apple and is not a way to sign in
+	AppleMusicProvider AccountProvider = "apple_music"
+)
+
+// CanSignIn reports whether accounts of the provider are a login method
+func (p AccountProvider) CanSignIn() bool {
+	return p != YouTubeProvider && p != AppleMusicProvider
+}
+
+// TokenHealth summarizes whether the provider tokens of an account still work
+type TokenHealth string
+
+const (
+	// TokenHealthValid means the access token has not expired
+	TokenHealthValid TokenHealth = "valid"
+	// TokenHealthRefreshable means the access token expired but will be
+	// renewed with the refresh token on next use
+	TokenHealthRefreshable TokenHealth = "refreshable"
+	// TokenHealthReauthRequired means the user has to link the account again
+	TokenHealthReauthRequired TokenHealth = "reauth_required"
 )
 
 type Account struct {
-	id        valueobjects.AccountID
-	userID    valueobjects.UserID
-	provider  AccountProvider
-	password  valueobjects.HashedPassword
-	createdAt time.Time
-	updatedAt time.Time
+	id       valueobjects.AccountID
+	userID   valueobjects.UserID
+	provider AccountProvider
+	password valueobjects.HashedPassword
+	// subject is the provider's stable user ID. Empty for userpass accounts and
+	// for OAuth accounts created before it was stored, until their next login
+	subject string
+	// credentials are only set for OAuth providers
+	credentials ProviderCredentials
+	// reauthRequired is set when the provider revoked the refresh token
+	reauthRequired bool
+	// musicUserToken is the MusicKit token of apple_music accounts; Apple Music
+	// calls need it besides the developer token. apple accounts may still hold
+	// one stored before apple_music existed
+	musicUserToken string
+	createdAt      time.Time
+	updatedAt      time.Time
 }
 
 func NewUserpassAccount(userID valueobjects.UserID, password valueobjects.HashedPassword) *Account {
@@ -38,22 +76,49 @@ func NewUserpassAccount(userID valueobjects.UserID, password valueobjects.Hashed
 	}
 }
 
-func NewOAuthAccount(userID valueobjects.UserID, provider AccountProvider) (*Account, error) {
+func NewOAuthAccount(userID valueobjects.UserID, provider AccountProvider, subject string) (*Account, error) {
 	if provider == UserpassProvider {
 		return nil, errors.NewDomainError("invalid_provider", "Cannot create OAuth account with userpass provider")
 	}
 
+	if subject == "" {
+		return nil, errors.NewDomainError("missing_subject", "OAuth account requires the provider's user ID")
+	}
+
 	now := time.Now()
 	return &Account{
 		id:        valueobjects.NewAccountID(),
 		userID:    userID,
 		provider:  provider,
+		subject:   subject,
 		createdAt: now,
 		updatedAt: now,
 	}, nil
 }
 
-func ReconstructAccount(id, userID uuid.UUID, provider string, password string, createdAt, updatedAt time.Time) (*Account, error) {
+// NewAppleMusicAccount creates the account that stores the user's
+// Music-User-Token. It has no provider subject: MusicKit does not expose one
+func NewAppleMusicAccount(userID valueobjects.UserID) *Account {
+	now := time.Now()
+	return &Account{
+		id:        valueobjects.NewAccountID(),
+		userID:    userID,
+		provider:  AppleMusicProvider,
+		createdAt: now,
+		updatedAt: now,
+	}
+}
+
+func ReconstructAccount(
+	id, userID uuid.UUID,
+	provider string,
+	password string,
+	subject string,
+	credentials ProviderCredentials,
+	reauthRequired bool,
+	musicUserToken string,
+	createdAt, updatedAt time.Time,
+) (*Account, error) {
 	accountID, err := valueobjects.ReconstructAccountID(id)
 	if err != nil {
 		return nil, err
@@ -75,12 +140,16 @@ func ReconstructAccount(id, userID uuid.UUID, provider string, password string,
 	}
 
 	return &Account{
-		id:        accountID,
-		userID:    userIDVO,
-		provider:  accountProvider,
-		password:  hashedPassword,
-		createdAt: createdAt,
-		updatedAt: updatedAt,
+		id:             accountID,
+		userID:         userIDVO,
+		provider:       accountProvider,
+		password:       hashedPassword,
+		subject:        subject,
+		credentials:    credentials,
+		reauthRequired: reauthRequired,
+		musicUserToken: musicUserToken,
+		createdAt:      createdAt,
+		updatedAt:      updatedAt,
 	}, nil
 }
 
@@ -100,6 +169,22 @@ func (a *Account) Password() valueobjects.HashedPassword {
 	return a.password
 }
 
+func (a *Account) Subject() string {
+	return a.subject
+}
+
+func (a *Account) Credentials() ProviderCredentials {
+	return a.credentials
+}
+
+func (a *Account) ReauthRequired() bool {
+	return a.reauthRequired
+}
+
+func (a *Account) MusicUserToken() string {
+	return a.musicUserToken
+}
+
 func (a *Account) CreatedAt() time.Time {
 	return a.createdAt
 }
@@ -118,13 +203,99 @@ func (a *Account) ChangePassword(newPassword valueobjects.HashedPassword) error
 	return nil
 }
 
+// UpdateCredentials stores the tokens returned by the provider. Providers may
+// omit the refresh token when refreshing, in which case the current one is kept
+func (a *Account) UpdateCredentials(credentials ProviderCredentials) error {
+	if a.provider == UserpassProvider {
+		return errors.NewDomainError("invalid_operation", "Cannot store provider credentials for userpass account")
+	}
+
+	if credentials.refreshToken == "" {
+		credentials.refreshToken = a.credentials.refreshToken
+		credentials.refreshTokenExpiresAt = a.credentials.refreshTokenExpiresAt
+	}
+
+	a.credentials = credentials
+	a.reauthRequired = false
+	a.updatedAt = time.Now()
+	return nil
+}
+
+// SetMusicUserToken stores the token MusicKit returned when the user authorized
+// Apple Music; an empty token forgets it
+func (a *Account) SetMusicUserToken(token string) error {
+	if a.provider != AppleMusicProvider {
+		return errors.NewDomainError("invalid_operation", "Music user tokens can only be stored on apple_music accounts")
+	}
+
+	a.musicUserToken = token
+	a.updatedAt = time.Now()
+	return nil
+}
+
+// ForgetMusicUserToken discards a Music-User-Token Apple rejected, which also
+// clears one left on an apple account before apple_music existed
+func (a *Account) ForgetMusicUserToken() {
+	a.musicUserToken = ""
+	a.updatedAt = time.Now()
+}
+
+// AttachSubject records the provider's user ID on accounts created before it
+// was stored. An account never changes identity: a different subject means
+// another provider account and is rejected
+func (a *Account) AttachSubject(subject string) error {
+	if a.subject == subject {
+		return nil
+	}
+
+	if a.subject != "" {
+		return errors.NewDomainError("subject_mismatch", "The account is linked to a different provider identity")
+	}
+
+	a.subject = subject
+	a.updatedAt = time.Now()
+	return nil
+}
+
+// RequireReauth discards the credentials after the provider revoked them; the
+// user has to authorize the account again
+func (a *Account) RequireReauth() {
+	a.credentials = ProviderCredentials{scope: a.credentials.scope}
+	a.reauthRequired = true
+	a.updatedAt = time.Now()
+}
+
+// TokenHealth reports the state of the provider tokens; it is empty for
+// userpass accounts, which have none
+func (a *Account) TokenHealth(now time.Time) TokenHealth {
+	switch {
+	case a.provider == UserpassProvider:
+		return ""
+	case a.provider == AppleMusicProvider:
+		// Apple does not tell when a Music-User-Token expires
+		if a.musicUserToken == "" {
+			return TokenHealthReauthRequired
+		}
+		return TokenHealthValid
+	case a.reauthRequired:
+		return TokenHealthReauthRequired
+	case a.credentials.accessToken != "" && now.Before(a.credentials.accessTokenExpiresAt):
+		return TokenHealthValid
+	case a.credentials.refreshToken != "":
+		return TokenHealthRefreshable
+	default:
+		return TokenHealthReauthRequired
+	}
+}
+
 func (a *Account) IsUserpassAccount() bool {
 	return a.provider == UserpassProvider
 }
 
 func isValidProvider(provider AccountProvider) bool {
 	switch provider {
-	case UserpassProvider, SpotifyProvider, AppleProvider, GoogleProvider:
+	case UserpassProvider, SpotifyProvider, AppleProvider, GoogleProvider, YouTubeProvider,
+		DeezerProvider, TidalProvider, AppleMusicProvider:
 		return true
 	default:
 		return false
@@ -0,0 +1,213 @@
+package entities
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+const (
+	// APIKeyPrefix marks personal API keys, so they can be told apart from
+	// JWTs and picked up by secret scanners
+	APIKeyPrefix = "spk_"
+	// apiKeyDisplayLength is how much of the key is kept in clear to let the
+	// user identify it
+	apiKeyDisplayLength = len(APIKeyPrefix) + 8
+	apiKeyNameMaxLength = 100
+	// apiKeyUsageResolution bounds how often the last use is written, since
+	// every request authenticated with the key would update it otherwise
+	apiKeyUsageResolution = time.Minute
+)
+
+// apiKeyScopes are the scopes a key can be granted. Admin scopes are left out:
+// keys are meant for scripts acting on the user's own playlists and accounts
+var apiKeyScopes = []string{ScopePlaylistsRead, ScopePlaylistsWrite, ScopeMigrationsRead, ScopeMigrationsWrite, ScopeAccountsRead}
+
+// APIKey is a personal access token for non-interactive clients. Only a
+// SHA-256 hash of the key is stored; the keys carry 256 random bits, so a
+// fast hash is enough
+type APIKey struct {
+	id         valueobjects.APIKeyID
+	userID     valueobjects.UserID
+	name       string
+	prefix     string
+	hash       string
+	scopes     []string
+	expiresAt  *time.Time
+	lastUsedAt *time.Time
+	createdAt  time.Time
+	updatedAt  time.Time
+}
+
+// NewAPIKey creates a key for the user and returns it with its plain value,
+// which is shown once and cannot be recovered afterwards
+func NewAPIKey(userID valueobjects.UserID, name string, scopes []string, expiresAt *time.Time, now time.Time) (*APIKey, string, error) {
+	name, err := validateAPIKeyName(name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	scopes, err = validateAPIKeyScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, "", errors.NewValidationError("expiresAt", "invalid_expiration", "Expiration must be in the future")
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	key := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	return &APIKey{
+		id:        valueobjects.NewAPIKeyID(),
+		userID:    userID,
+		name:      name,
+		prefix:    key[:apiKeyDisplayLength],
+		hash:      HashAPIKey(key),
+		scopes:    scopes,
+		expiresAt: expiresAt,
+		createdAt: now,
+		updatedAt: now,
+	}, key, nil
+}
+
+func ReconstructAPIKey(
+	id valueobjects.APIKeyID,
+	userID valueobjects.UserID,
+	name, prefix, hash string,
+	scopes []string,
+	expiresAt, lastUsedAt *time.Time,
+	createdAt, updatedAt time.Time,
+) *APIKey {
+	return &APIKey{
+		id:         id,
+		userID:     userID,
+		name:       name,
+		prefix:     prefix,
+		hash:       hash,
+		scopes:     scopes,
+		expiresAt:  expiresAt,
+		lastUsedAt: lastUsedAt,
+		createdAt:  createdAt,
+		updatedAt:  updatedAt,
+	}
+}
+
+// HashAPIKey returns the value keys are looked up by
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (k *APIKey) ID() valueobjects.APIKeyID {
+	return k.id
+}
+
+func (k *APIKey) UserID() valueobjects.UserID {
+	return k.userID
+}
+
+func (k *APIKey) Name() string {
+	return k.name
+}
+
+// Prefix is the start of the key, safe to display
+func (k *APIKey) Prefix() string {
+	return k.prefix
+}
+
+func (k *APIKey) Hash() string {
+	return k.hash
+}
+
+func (k *APIKey) Scopes() []string {
+	return k.scopes
+}
+
+func (k *APIKey) ExpiresAt() *time.Time {
+	return k.expiresAt
+}
+
+func (k *APIKey) LastUsedAt() *time.Time {
+	return k.lastUsedAt
+}
+
+func (k *APIKey) CreatedAt() time.Time {
+	return k.createdAt
+}
+
+func (k *APIKey) UpdatedAt() time.Time {
+	return k.updatedAt
+}
+
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.expiresAt != nil && !now.Before(*k.expiresAt)
+}
+
+func (k *APIKey) CanAuthenticate(now time.Time) error {
+	if k.IsExpired(now) {
+		return errors.NewAuthenticationError("api_key_expired", "API key has expired")
+	}
+	return nil
+}
+
+func (k *APIKey) Rename(name string, now time.Time) error {
+	name, err := validateAPIKeyName(name)
+	if err != nil {
+		return err
+	}
+
+	k.name = name
+	k.updatedAt = now
+	return nil
+}
+
+// MarkUsed records a use of the key and reports whether it has to be saved
+func (k *APIKey) MarkUsed(now time.Time) bool {
+	if k.lastUsedAt != nil && now.Sub(*k.lastUsedAt) < apiKeyUsageResolution {
+		return false
+	}
+
+	k.lastUsedAt = &now
+	return true
+}
+
+func validateAPIKeyName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.NewValidationError("name", "required", "Name is required")
+	}
+	if len(name) > apiKeyNameMaxLength {
+		return "", errors.NewValidationError("name", "too_long", fmt.Sprintf("Name must be at most %d characters", apiKeyNameMaxLength))
+	}
+	return name, nil
+}
+
+func validateAPIKeyScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, errors.NewValidationError("scopes", "required", "At least one scope is required")
+	}
+
+	valid := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !slices.Contains(apiKeyScopes, scope) {
+			return nil, errors.NewValidationError("scopes", "invalid_scope", fmt.Sprintf("Unknown scope: %s", scope))
+		}
+		if !slices.Contains(valid, scope) {
+			valid = append(valid, scope)
+		}
+	}
+	return valid, nil
+}
@@ -0,0 +1,92 @@
+package entities
+
+import "time"
+
+// LockoutPolicy describes how failed logins slow down further attempts: after
+// FreeAttempts every failure doubles the wait starting at BaseDelay (capped at
+// MaxDelay), and reaching LockoutThreshold locks the key for LockoutDuration.
+// Failures are forgotten once ResetAfter passes without a new one
+type LockoutPolicy struct {
+	FreeAttempts     int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	ResetAfter       time.Duration
+}
+
+// maxBackoffShift keeps the exponential delay from overflowing
+const maxBackoffShift = 30
+
+// LoginAttempts tracks consecutive failed logins for a key (an email or an IP)
+type LoginAttempts struct {
+	key           string
+	failures      int
+	lastFailureAt time.Time
+	lockedUntil   time.Time
+}
+
+func NewLoginAttempts(key string) *LoginAttempts {
+	return &LoginAttempts{key: key}
+}
+
+func ReconstructLoginAttempts(key string, failures int, lastFailureAt, lockedUntil time.Time) *LoginAttempts {
+	return &LoginAttempts{
+		key:           key,
+		failures:      failures,
+		lastFailureAt: lastFailureAt,
+		lockedUntil:   lockedUntil,
+	}
+}
+
+func (la *LoginAttempts) Key() string {
+	return la.key
+}
+
+func (la *LoginAttempts) Failures() int {
+	return la.failures
+}
+
+func (la *LoginAttempts) LastFailureAt() time.Time {
+	return la.lastFailureAt
+}
+
+func (la *LoginAttempts) LockedUntil() time.Time {
+	return la.lockedUntil
+}
+
+// RetryAfter is how long the key must wait before the next attempt, zero when
+// it is not locked
+func (la *LoginAttempts) RetryAfter(now time.Time) time.Duration {
+	if now.Before(la.lockedUntil) {
+		return la.lockedUntil.Sub(now)
+	}
+	return 0
+}
+
+// RegisterFailure counts a failed login and computes the resulting lock
+func (la *LoginAttempts) RegisterFailure(now time.Time, policy LockoutPolicy) {
+	if !la.lastFailureAt.IsZero() && now.Sub(la.lastFailureAt) > policy.ResetAfter {
+		la.failures = 0
+		la.lockedUntil = time.Time{}
+	}
+
+	la.failures++
+	la.lastFailureAt = now
+
+	switch {
+	case policy.LockoutThreshold > 0 && la.failures >= policy.LockoutThreshold:
+		la.lockedUntil = now.Add(policy.LockoutDuration)
+	case la.failures > policy.FreeAttempts:
+		shift := la.failures - policy.FreeAttempts - 1
+		if shift > maxBackoffShift {
+			shift = maxBackoffShift
+		}
+
+		delay := policy.BaseDelay << shift
+		if delay > policy.MaxDelay || delay <= 0 {
+			delay = policy.MaxDelay
+		}
+		la.lockedUntil = now.Add(delay)
+	}
+}
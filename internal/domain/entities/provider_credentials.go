@@ -0,0 +1,66 @@
+package entities
+
+import "time"
+
+// ProviderCredentials are the OAuth tokens an account holds for calling the
+// provider's API on the user's behalf
+type ProviderCredentials struct {
+	accessToken           string
+	refreshToken          string
+	accessTokenExpiresAt  time.Time
+	refreshTokenExpiresAt time.Time // zero when the provider does not expire refresh tokens
+	scope                 string
+}
+
+func NewProviderCredentials(accessToken, refreshToken string, accessTokenExpiresAt time.Time, scope string) ProviderCredentials {
+	return ProviderCredentials{
+		accessToken:          accessToken,
+		refreshToken:         refreshToken,
+		accessTokenExpiresAt: accessTokenExpiresAt,
+		scope:                scope,
+	}
+}
+
+func ReconstructProviderCredentials(
+	accessToken, refreshToken string,
+	accessTokenExpiresAt, refreshTokenExpiresAt time.Time,
+	scope string,
+) ProviderCredentials {
+	return ProviderCredentials{
+		accessToken:           accessToken,
+		refreshToken:          refreshToken,
+		accessTokenExpiresAt:  accessTokenExpiresAt,
+		refreshTokenExpiresAt: refreshTokenExpiresAt,
+		scope:                 scope,
+	}
+}
+
+func (c ProviderCredentials) AccessToken() string {
+	return c.accessToken
+}
+
+func (c ProviderCredentials) RefreshToken() string {
+	return c.refreshToken
+}
+
+func (c ProviderCredentials) AccessTokenExpiresAt() time.Time {
+	return c.accessTokenExpiresAt
+}
+
+func (c ProviderCredentials) RefreshTokenExpiresAt() time.Time {
+	return c.refreshTokenExpiresAt
+}
+
+func (c ProviderCredentials) Scope() string {
+	return c.scope
+}
+
+func (c ProviderCredentials) IsEmpty() bool {
+	return c.accessToken == "" && c.refreshToken == ""
+}
+
+// AccessTokenExpiresWithin reports whether the access token is expired or will
+// be within margin
+func (c ProviderCredentials) AccessTokenExpiresWithin(margin time.Duration, now time.Time) bool {
+	return c.accessTokenExpiresAt.IsZero() || !now.Add(margin).Before(c.accessTokenExpiresAt)
+}
@@ -7,15 +7,28 @@ import (
 	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
 )
 
+// RefreshToken is the current credential of a session. Rotating it keeps the
+// session ID, device and creation time, so a session outlives its tokens
 type RefreshToken struct {
-	id        valueobjects.TokenID
-	userID    valueobjects.UserID
-	token     string
-	expiresAt time.Time
-	createdAt time.Time
+	id            valueobjects.TokenID
+	sessionID     valueobjects.SessionID
+	userID        valueobjects.UserID
+	token         string
+	accessTokenID string // jti of the access token issued alongside this refresh token
+	device        valueobjects.DeviceInfo
+	expiresAt     time.Time
+	createdAt     time.Time
+	lastUsedAt    time.Time
 }
 
-func NewRefreshToken(userID valueobjects.UserID, token string, expiresAt time.Time) (*RefreshToken, error) {
+// NewRefreshToken creates the refresh token of a brand new session
+func NewRefreshToken(
+	userID valueobjects.UserID,
+	token string,
+	accessTokenID string,
+	device valueobjects.DeviceInfo,
+	expiresAt time.Time,
+) (*RefreshToken, error) {
 	if token == "" {
 		return nil, errors.NewDomainError("empty_token", "Token cannot be empty")
 	}
@@ -26,32 +39,68 @@ func NewRefreshToken(userID valueobjects.UserID, token string, expiresAt time.Ti
 
 	now := time.Now()
 	return &RefreshToken{
-		id:        valueobjects.NewTokenID(),
-		userID:    userID,
-		token:     token,
-		expiresAt: expiresAt,
-		createdAt: now,
+		id:            valueobjects.NewTokenID(),
+		sessionID:     valueobjects.NewSessionID(),
+		userID:        userID,
+		token:         token,
+		accessTokenID: accessTokenID,
+		device:        device,
+		expiresAt:     expiresAt,
+		createdAt:     now,
+		lastUsedAt:    now,
 	}, nil
 }
 
-func ReconstructRefreshToken(userID valueobjects.UserID, token string, expiresAt, createdAt time.Time) (*RefreshToken, error) {
+func ReconstructRefreshToken(
+	sessionID valueobjects.SessionID,
+	userID valueobjects.UserID,
+	token string,
+	accessTokenID string,
+	device valueobjects.DeviceInfo,
+	expiresAt, createdAt, lastUsedAt time.Time,
+) (*RefreshToken, error) {
 	if token == "" {
 		return nil, errors.NewDomainError("empty_token", "Token cannot be empty")
 	}
 
 	return &RefreshToken{
-		id:        valueobjects.NewTokenID(),
-		userID:    userID,
-		token:     token,
-		expiresAt: expiresAt,
-		createdAt: createdAt,
+		id:            valueobjects.NewTokenID(),
+		sessionID:     sessionID,
+		userID:        userID,
+		token:         token,
+		accessTokenID: accessTokenID,
+		device:        device,
+		expiresAt:     expiresAt,
+		createdAt:     createdAt,
+		lastUsedAt:    lastUsedAt,
 	}, nil
 }
 
+// Rotate creates the successor of this refresh token within the same session
+func (rt *RefreshToken) Rotate(
+	token string,
+	accessTokenID string,
+	device valueobjects.DeviceInfo,
+	expiresAt time.Time,
+) (*RefreshToken, error) {
+	rotated, err := NewRefreshToken(rt.userID, token, accessTokenID, device, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated.sessionID = rt.sessionID
+	rotated.createdAt = rt.createdAt
+	return rotated, nil
+}
+
 func (rt *RefreshToken) ID() valueobjects.TokenID {
 	return rt.id
 }
 
+func (rt *RefreshToken) SessionID() valueobjects.SessionID {
+	return rt.sessionID
+}
+
 func (rt *RefreshToken) UserID() valueobjects.UserID {
 	return rt.userID
 }
@@ -60,6 +109,14 @@ func (rt *RefreshToken) Token() string {
 	return rt.token
 }
 
+func (rt *RefreshToken) AccessTokenID() string {
+	return rt.accessTokenID
+}
+
+func (rt *RefreshToken) Device() valueobjects.DeviceInfo {
+	return rt.device
+}
+
 func (rt *RefreshToken) ExpiresAt() time.Time {
 	return rt.expiresAt
 }
@@ -68,6 +125,10 @@ func (rt *RefreshToken) CreatedAt() time.Time {
 	return rt.createdAt
 }
 
+func (rt *RefreshToken) LastUsedAt() time.Time {
+	return rt.lastUsedAt
+}
+
 func (rt *RefreshToken) IsExpired() bool {
 	return time.Now().After(rt.expiresAt)
 }
@@ -81,4 +142,4 @@ func (rt *RefreshToken) TimeUntilExpiration() time.Duration {
 		return 0
 	}
 	return time.Until(rt.expiresAt)
-}
\ No newline at end of file
+}
@@ -0,0 +1,266 @@
+package entities
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+const recoveryCodeCount = 10
+
+// RecoveryCode is a single-use backup code, stored only as a SHA-256 hash.
+// The codes carry 50 random bits, so a fast hash is enough
+type RecoveryCode struct {
+	hash   string
+	usedAt *time.Time
+}
+
+func ReconstructRecoveryCode(hash string, usedAt *time.Time) RecoveryCode {
+	return RecoveryCode{hash: hash, usedAt: usedAt}
+}
+
+func (rc RecoveryCode) Hash() string {
+	return rc.hash
+}
+
+func (rc RecoveryCode) UsedAt() *time.Time {
+	return rc.usedAt
+}
+
+func (rc RecoveryCode) IsUsed() bool {
+	return rc.usedAt != nil
+}
+
+// TwoFactor holds a user's TOTP enrollment. It is created pending and only
+// enforced at login once the user confirms a code from their authenticator
+type TwoFactor struct {
+	userID        valueobjects.UserID
+	secret        valueobjects.TOTPSecret
+	enabled       bool
+	lastUsedStep  int64 // TOTP step of the last accepted code, to reject replays
+	recoveryCodes []RecoveryCode
+	// usedRecoveryCode is the recovery code the last Verify accepted, nil
+	// when it accepted a TOTP code
+	usedRecoveryCode *RecoveryCode
+	confirmedAt      *time.Time
+	createdAt        time.Time
+	updatedAt        time.Time
+}
+
+// NewTwoFactor starts an enrollment with a freshly generated secret
+func NewTwoFactor(userID valueobjects.UserID, now time.Time) (*TwoFactor, error) {
+	secret, err := valueobjects.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TwoFactor{
+		userID:    userID,
+		secret:    secret,
+		createdAt: now,
+		updatedAt: now,
+	}, nil
+}
+
+func ReconstructTwoFactor(
+	userID valueobjects.UserID,
+	secret valueobjects.TOTPSecret,
+	enabled bool,
+	lastUsedStep int64,
+	recoveryCodes []RecoveryCode,
+	confirmedAt *time.Time,
+	createdAt, updatedAt time.Time,
+) *TwoFactor {
+	return &TwoFactor{
+		userID:        userID,
+		secret:        secret,
+		enabled:       enabled,
+		lastUsedStep:  lastUsedStep,
+		recoveryCodes: recoveryCodes,
+		confirmedAt:   confirmedAt,
+		createdAt:     createdAt,
+		updatedAt:     updatedAt,
+	}
+}
+
+func (tf *TwoFactor) UserID() valueobjects.UserID {
+	return tf.userID
+}
+
+func (tf *TwoFactor) Secret() valueobjects.TOTPSecret {
+	return tf.secret
+}
+
+func (tf *TwoFactor) IsEnabled() bool {
+	return tf.enabled
+}
+
+func (tf *TwoFactor) LastUsedStep() int64 {
+	return tf.lastUsedStep
+}
+
+func (tf *TwoFactor) RecoveryCodes() []RecoveryCode {
+	return tf.recoveryCodes
+}
+
+func (tf *TwoFactor) RemainingRecoveryCodes() int {
+	remaining := 0
+	for _, code := range tf.recoveryCodes {
+		if !code.IsUsed() {
+			remaining++
+		}
+	}
+	return remaining
+}
+
+// UsedRecoveryCode returns the recovery code consumed by the last Verify, or
+// nil if it accepted a TOTP code
+func (tf *TwoFactor) UsedRecoveryCode() *RecoveryCode {
+	return tf.usedRecoveryCode
+}
+
+func (tf *TwoFactor) ConfirmedAt() *time.Time {
+	return tf.confirmedAt
+}
+
+func (tf *TwoFactor) CreatedAt() time.Time {
+	return tf.createdAt
+}
+
+func (tf *TwoFactor) UpdatedAt() time.Time {
+	return tf.updatedAt
+}
+
+// Confirm enables two-factor authentication once the user proves their
+// authenticator is set up. It returns the plain recovery codes, which are
+// shown once and never stored
+func (tf *TwoFactor) Confirm(code string, now time.Time) ([]string, error) {
+	if tf.enabled {
+		return nil, errors.NewDomainError("mfa_already_enabled", "Two-factor authentication is already enabled")
+	}
+
+	if err := tf.verifyTOTP(code, now); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := tf.regenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	tf.enabled = true
+	tf.confirmedAt = &now
+	tf.updatedAt = now
+	return recoveryCodes, nil
+}
+
+// Verify accepts either a TOTP code or an unused recovery code
+func (tf *TwoFactor) Verify(code string, now time.Time) error {
+	if !tf.enabled {
+		return errors.NewDomainError("mfa_not_enabled", "Two-factor authentication is not enabled")
+	}
+
+	tf.usedRecoveryCode = nil
+
+	var err error
+	if isTOTPCode(code) {
+		err = tf.verifyTOTP(code, now)
+	} else {
+		err = tf.useRecoveryCode(code, now)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	tf.updatedAt = now
+	return nil
+}
+
+func (tf *TwoFactor) verifyTOTP(code string, now time.Time) error {
+	step, ok := tf.secret.Verify(code, now)
+	if !ok {
+		return errors.NewAuthenticationError("invalid_mfa_code", "Invalid two-factor authentication code")
+	}
+
+	// A code can only be used once, even within its validity window
+	if step <= tf.lastUsedStep {
+		return errors.NewAuthenticationError("mfa_code_reused", "This code has already been used")
+	}
+
+	tf.lastUsedStep = step
+	return nil
+}
+
+func (tf *TwoFactor) useRecoveryCode(code string, now time.Time) error {
+	hash := hashRecoveryCode(code)
+
+	for i, recoveryCode := range tf.recoveryCodes {
+		if recoveryCode.IsUsed() {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(recoveryCode.hash), []byte(hash)) == 1 {
+			usedAt := now
+			tf.recoveryCodes[i].usedAt = &usedAt
+			tf.usedRecoveryCode = &tf.recoveryCodes[i]
+			return nil
+		}
+	}
+
+	return errors.NewAuthenticationError("invalid_mfa_code", "Invalid two-factor authentication code")
+}
+
+func (tf *TwoFactor) regenerateRecoveryCodes() ([]string, error) {
+	plain := make([]string, 0, recoveryCodeCount)
+	hashed := make([]RecoveryCode, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, errors.NewDomainError("recovery_code_generation_failed", "Failed to generate recovery codes")
+		}
+		plain = append(plain, code)
+		hashed = append(hashed, RecoveryCode{hash: hashRecoveryCode(code)})
+	}
+
+	tf.recoveryCodes = hashed
+	return plain, nil
+}
+
+// generateRecoveryCode returns a code like "k3j9x-p2m8q"
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	encoded := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))[:10]
+	return encoded[:5] + "-" + encoded[5:], nil
+}
+
+// hashRecoveryCode normalizes user input (case, dashes, spaces) before hashing
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func isTOTPCode(code string) bool {
+	code = strings.TrimSpace(code)
+	if len(code) != 6 {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
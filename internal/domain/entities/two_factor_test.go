@@ -0,0 +1,175 @@
+package entities
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+// rfc6238Secret is the SHA-1 secret of the RFC 6238 test vectors
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func newEnabledTwoFactor(t *testing.T, lastUsedStep int64) *TwoFactor {
+	t.Helper()
+
+	secret, err := valueobjects.ReconstructTOTPSecret(rfc6238Secret)
+	if err != nil {
+		t.Fatalf("ReconstructTOTPSecret: %v", err)
+	}
+
+	now := time.Unix(0, 0)
+	return ReconstructTwoFactor(valueobjects.NewUserID(), secret, true, lastUsedStep, nil, &now, now, now)
+}
+
+func TestTOTPSecretMatchesRFC6238Vectors(t *testing.T) {
+	secret, err := valueobjects.ReconstructTOTPSecret(rfc6238Secret)
+	if err != nil {
+		t.Fatalf("ReconstructTOTPSecret: %v", err)
+	}
+
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		if got := secret.GenerateCode(time.Unix(tt.unix, 0)); got != tt.code {
+			t.Errorf("GenerateCode(%d) = %s, want %s", tt.unix, got, tt.code)
+		}
+	}
+}
+
+func TestTwoFactorVerifyWindow(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	secret, _ := valueobjects.ReconstructTOTPSecret(rfc6238Secret)
+
+	tests := []struct {
+		name     string
+		codeAt   time.Time
+		wantCode string
+	}{
+		{"current period", now, ""},
+		{"previous period", now.Add(-30 * time.Second), ""},
+		{"next period", now.Add(30 * time.Second), ""},
+		{"two periods behind", now.Add(-60 * time.Second), "invalid_mfa_code"},
+		{"two periods ahead", now.Add(60 * time.Second), "invalid_mfa_code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			twoFactor := newEnabledTwoFactor(t, 0)
+
+			err := twoFactor.Verify(secret.GenerateCode(tt.codeAt), now)
+			assertErrorCode(t, err, tt.wantCode)
+
+			if twoFactor.UsedRecoveryCode() != nil {
+				t.Error("a TOTP code reported a used recovery code")
+			}
+
+			if tt.wantCode == "" && twoFactor.LastUsedStep() != valueobjects.TOTPStep(tt.codeAt) {
+				t.Errorf("LastUsedStep = %d, want %d", twoFactor.LastUsedStep(), valueobjects.TOTPStep(tt.codeAt))
+			}
+		})
+	}
+}
+
+func TestTwoFactorVerifyRejectsReplays(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	step := valueobjects.TOTPStep(now)
+	secret, _ := valueobjects.ReconstructTOTPSecret(rfc6238Secret)
+
+	tests := []struct {
+		name         string
+		lastUsedStep int64
+		codeAt       time.Time
+		wantCode     string
+	}{
+		{"fresh code", step - 1, now, ""},
+		{"same code again", step, now, "mfa_code_reused"},
+		{"older code after a newer one", step + 1, now, "mfa_code_reused"},
+		{"previous period after the current one", step, now.Add(-30 * time.Second), "mfa_code_reused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			twoFactor := newEnabledTwoFactor(t, tt.lastUsedStep)
+
+			err := twoFactor.Verify(secret.GenerateCode(tt.codeAt), now)
+			assertErrorCode(t, err, tt.wantCode)
+
+			if twoFactor.UsedRecoveryCode() != nil {
+				t.Error("a TOTP code reported a used recovery code")
+			}
+		})
+	}
+}
+
+func TestTwoFactorRecoveryCodesAreSingleUse(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	twoFactor, err := NewTwoFactor(valueobjects.NewUserID(), now)
+	if err != nil {
+		t.Fatalf("NewTwoFactor: %v", err)
+	}
+
+	codes, err := twoFactor.Confirm(twoFactor.Secret().GenerateCode(now), now)
+	if err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		wantCode string
+	}{
+		{"unused code", codes[0], ""},
+		{"same code again", codes[0], "invalid_mfa_code"},
+		{"code typed in uppercase without dash", "  " + strings.ToUpper(strings.ReplaceAll(codes[1], "-", "")) + " ", ""},
+		{"unknown code", "aaaaa-bbbbb", "invalid_mfa_code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertErrorCode(t, twoFactor.Verify(tt.code, now), tt.wantCode)
+
+			// The repository consumes this code with a conditional update
+			used := twoFactor.UsedRecoveryCode()
+			if tt.wantCode == "" && (used == nil || !used.IsUsed()) {
+				t.Errorf("UsedRecoveryCode = %v, want the accepted code", used)
+			}
+			if tt.wantCode != "" && used != nil {
+				t.Errorf("UsedRecoveryCode = %v after a rejected code", used)
+			}
+		})
+	}
+
+	if got := twoFactor.RemainingRecoveryCodes(); got != recoveryCodeCount-2 {
+		t.Errorf("RemainingRecoveryCodes = %d, want %d", got, recoveryCodeCount-2)
+	}
+}
+
+func assertErrorCode(t *testing.T, err error, want string) {
+	t.Helper()
+
+	if want == "" {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+
+	authErr, ok := err.(*errors.AuthenticationError)
+	if !ok {
+		t.Fatalf("error = %v, want authentication error %s", err, want)
+	}
+	if authErr.Code() != want {
+		t.Errorf("error code = %s, want %s", authErr.Code(), want)
+	}
+}
@@ -1,6 +1,8 @@
 package entities
 
 import (
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,10 +10,56 @@ import (
 	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
 )
 
+// Role grants access to the support and admin endpoints
+type Role string
+
+const (
+	RoleUser    Role = "user"
+	RoleSupport Role = "support"
+	RoleAdmin   Role = "admin"
+)
+
+// Scopes embedded in access tokens next to the role, so routes can require a
+// permission instead of a list of roles. API keys carry a subset of them
+const (
+	ScopePlaylistsRead   = "playlists:read"
+	ScopePlaylistsWrite  = "playlists:write"
+	ScopeMigrationsRead  = "migrations:read"
+	ScopeMigrationsWrite = "migrations:write"
+	ScopeAccountsRead    = "accounts:read"
+	ScopeUsersRead       = "users:read"
+	ScopeUsersWrite      = "users:write"
+)
+
+// userScopes are granted to every role
+var userScopes = []string{ScopePlaylistsRead, ScopePlaylistsWrite, ScopeMigrationsRead, ScopeMigrationsWrite, ScopeAccountsRead}
+
+func ParseRole(role string) (Role, error) {
+	switch r := Role(role); r {
+	case RoleUser, RoleSupport, RoleAdmin:
+		return r, nil
+	default:
+		return "", errors.NewDomainError("invalid_role", fmt.Sprintf("Unknown role: %s", role))
+	}
+}
+
+// Scopes lists the permissions the role grants
+func (r Role) Scopes() []string {
+	switch r {
+	case RoleAdmin:
+		return append(slices.Clone(userScopes), ScopeUsersRead, ScopeUsersWrite)
+	case RoleSupport:
+		return append(slices.Clone(userScopes), ScopeUsersRead)
+	default:
+		return slices.Clone(userScopes)
+	}
+}
+
 type User struct {
 	id              valueobjects.UserID
 	email           valueobjects.Email
 	profile         valueobjects.UserProfile
+	role            Role
 	isEmailVerified bool
 	createdAt       time.Time
 	updatedAt       time.Time
@@ -35,13 +83,14 @@ func NewUser(email string, name string, lastName string) (*User, error) {
 		id:              userID,
 		email:           emailVO,
 		profile:         profile,
+		role:            RoleUser,
 		isEmailVerified: false,
 		createdAt:       now,
 		updatedAt:       now,
 	}, nil
 }
 
-func ReconstructUser(id uuid.UUID, email, name, lastName string, isEmailVerified bool, createdAt, updatedAt time.Time) (*User, error) {
+func ReconstructUser(id uuid.UUID, email, name, lastName string, role Role, isEmailVerified bool, createdAt, updatedAt time.Time) (*User, error) {
 	userID, err := valueobjects.ReconstructUserID(id)
 	if err != nil {
 		return nil, err
@@ -61,6 +110,7 @@ func ReconstructUser(id uuid.UUID, email, name, lastName string, isEmailVerified
 		id:              userID,
 		email:           emailVO,
 		profile:         profile,
+		role:            role,
 		isEmailVerified: isEmailVerified,
 		createdAt:       createdAt,
 		updatedAt:       updatedAt,
@@ -79,6 +129,10 @@ func (u *User) Profile() valueobjects.UserProfile {
 	return u.profile
 }
 
+func (u *User) Role() Role {
+	return u.role
+}
+
 func (u *User) IsEmailVerified() bool {
 	return u.isEmailVerified
 }
@@ -107,6 +161,22 @@ func (u *User) VerifyEmail() {
 	u.updatedAt = time.Now()
 }
 
+// ChangeRole reports whether the role changed. The caller must end the user's
+// sessions, since their tokens still carry the previous role
+func (u *User) ChangeRole(role Role) (bool, error) {
+	if _, err := ParseRole(string(role)); err != nil {
+		return false, err
+	}
+
+	if u.role == role {
+		return false, nil
+	}
+
+	u.role = role
+	u.updatedAt = time.Now()
+	return true, nil
+}
+
 func (u *User) ChangeEmail(email string) error {
 	emailVO, err := valueobjects.NewEmail(email)
 	if err != nil {
@@ -119,10 +189,15 @@ func (u *User) ChangeEmail(email string) error {
 	return nil
 }
 
-func (u *User) CanAuthenticate() error {
-	return nil // Placeholder for future authentication checks
-	if !u.isEmailVerified {
-		return errors.NewDomainError("email_not_verified", "Email must be verified before authentication")
+// AuthenticationPolicy holds the deployment-specific requirements a user has
+// to meet before tokens are issued
+type AuthenticationPolicy struct {
+	RequireVerifiedEmail bool
+}
+
+func (u *User) CanAuthenticate(policy AuthenticationPolicy) error {
+	if policy.RequireVerifiedEmail && !u.isEmailVerified {
+		return errors.NewAuthenticationError("email_not_verified", "Email must be verified before authentication")
 	}
 	return nil
 }
@@ -2,6 +2,8 @@ package entities
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
 	"time"
 
@@ -16,43 +18,133 @@ const (
 	OAuthStateToken VerificationTokenType = "oauth_state"
 	// Frontend verification - medium lived (10 minutes)
 	FrontendVerificationToken VerificationTokenType = "frontend_verification"
+	// Email verification - long lived (sent by email after registration)
+	EmailVerificationToken VerificationTokenType = "email_verification"
+	// Password reset - short lived (sent by email on forgot-password)
+	PasswordResetToken VerificationTokenType = "password_reset"
+	// MFA challenge - short lived (5 minutes), issued after the password step of a login
+	MFAChallengeToken VerificationTokenType = "mfa_challenge"
+)
+
+// OAuthIntent tells the OAuth callback what the flow was started for
+type OAuthIntent string
+
+const (
+	// OAuthLoginIntent signs the user in (or up) with the provider
+	OAuthLoginIntent OAuthIntent = "login"
+	// OAuthLinkIntent attaches the provider account to a logged-in user
+	OAuthLinkIntent OAuthIntent = "link"
 )
 
 type VerificationToken struct {
 	id        valueobjects.TokenID
 	token     string // For OAuth: this is the state; For Frontend: this is a generated token
 	tokenType VerificationTokenType
-	userID    *valueobjects.UserID // Only set for frontend verification tokens
-	expiresAt time.Time
-	createdAt time.Time
-	usedAt    *time.Time
+	userID    *valueobjects.UserID // Set for user tokens and OAuth link states
+	intent    OAuthIntent          // Only set for OAuth state tokens
+	// codeVerifier is the PKCE verifier of an OAuth flow
+	codeVerifier string
+	// provider and browserNonce bind an OAuth state to the provider it was
+	// issued for and to the browser that started the flow
+	provider     AccountProvider
+	browserNonce string
+	// clientChallenge binds a login state, and the one-time code it leads to,
+	// to a client that cannot keep the nonce cookie (native and mobile apps).
+	// It is the S256 challenge of a verifier only that client knows
+	clientChallenge string
+	expiresAt       time.Time
+	createdAt       time.Time
+	usedAt          *time.Time
 }
 
 // NewOAuthStateToken creates a verification token for OAuth state validation
 // The state parameter itself serves as the token
-func NewOAuthStateToken(expiration time.Duration) (*VerificationToken, error) {
+func NewOAuthStateToken(provider AccountProvider, expiration time.Duration) (*VerificationToken, error) {
 	// Generate a secure state token
 	state, err := generateSecureToken()
 	if err != nil {
 		return nil, errors.NewDomainError("token_generation_failed", "Failed to generate state token")
 	}
 
+	codeVerifier, err := generateCodeVerifier()
+	if err != nil {
+		return nil, errors.NewDomainError("token_generation_failed", "Failed to generate code verifier")
+	}
+
+	browserNonce, err := generateSecureToken()
+	if err != nil {
+		return nil, errors.NewDomainError("token_generation_failed", "Failed to generate browser nonce")
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(expiration)
+
+	return &VerificationToken{
+		id:           valueobjects.NewTokenID(),
+		token:        state, // The state IS the token
+		tokenType:    OAuthStateToken,
+		userID:       nil,
+		intent:       OAuthLoginIntent,
+		codeVerifier: codeVerifier,
+		provider:     provider,
+		browserNonce: browserNonce,
+		expiresAt:    expiresAt,
+		createdAt:    now,
+		usedAt:       nil,
+	}, nil
+}
+
+// NewOAuthLinkStateToken creates the state of a flow started by a logged-in
+// user to link a provider account; the callback links it to that user
+func NewOAuthLinkStateToken(userID valueobjects.UserID, provider AccountProvider, expiration time.Duration) (*VerificationToken, error) {
+	verificationToken, err := NewOAuthStateToken(provider, expiration)
+	if err != nil {
+		return nil, err
+	}
+
+	verificationToken.userID = &userID
+	verificationToken.intent = OAuthLinkIntent
+	return verificationToken, nil
+}
+
+// NewFrontendVerificationToken creates a verification token for frontend validation
+func NewFrontendVerificationToken(userID valueobjects.UserID, expiration time.Duration) (*VerificationToken, error) {
+	token, err := generateSecureToken()
+	if err != nil {
+		return nil, errors.NewDomainError("token_generation_failed", "Failed to generate verification token")
+	}
+
 	now := time.Now()
 	expiresAt := now.Add(expiration)
 
 	return &VerificationToken{
 		id:        valueobjects.NewTokenID(),
-		token:     state, // The state IS the token
-		tokenType: OAuthStateToken,
-		userID:    nil,
+		token:     token,
+		tokenType: FrontendVerificationToken,
+		userID:    &userID,
 		expiresAt: expiresAt,
 		createdAt: now,
 		usedAt:    nil,
 	}, nil
 }
 
-// NewFrontendVerificationToken creates a verification token for frontend validation
-func NewFrontendVerificationToken(userID valueobjects.UserID, expiration time.Duration) (*VerificationToken, error) {
+// NewEmailVerificationToken creates a single-use token proving ownership of the user's email
+func NewEmailVerificationToken(userID valueobjects.UserID, expiration time.Duration) (*VerificationToken, error) {
+	return newUserToken(EmailVerificationToken, userID, expiration)
+}
+
+// NewPasswordResetToken creates a single-use token allowing the user to set a new password
+func NewPasswordResetToken(userID valueobjects.UserID, expiration time.Duration) (*VerificationToken, error) {
+	return newUserToken(PasswordResetToken, userID, expiration)
+}
+
+// NewMFAChallengeToken creates the token that links the password step of a
+// login to the second factor step
+func NewMFAChallengeToken(userID valueobjects.UserID, expiration time.Duration) (*VerificationToken, error) {
+	return newUserToken(MFAChallengeToken, userID, expiration)
+}
+
+func newUserToken(tokenType VerificationTokenType, userID valueobjects.UserID, expiration time.Duration) (*VerificationToken, error) {
 	token, err := generateSecureToken()
 	if err != nil {
 		return nil, errors.NewDomainError("token_generation_failed", "Failed to generate verification token")
@@ -64,7 +156,7 @@ func NewFrontendVerificationToken(userID valueobjects.UserID, expiration time.Du
 	return &VerificationToken{
 		id:        valueobjects.NewTokenID(),
 		token:     token,
-		tokenType: FrontendVerificationToken,
+		tokenType: tokenType,
 		userID:    &userID,
 		expiresAt: expiresAt,
 		createdAt: now,
@@ -77,6 +169,11 @@ func ReconstructVerificationToken(
 	token string,
 	tokenType VerificationTokenType,
 	userID *valueobjects.UserID,
+	intent OAuthIntent,
+	codeVerifier string,
+	provider AccountProvider,
+	browserNonce string,
+	clientChallenge string,
 	expiresAt time.Time,
 	createdAt time.Time,
 	usedAt *time.Time,
@@ -86,13 +183,18 @@ func ReconstructVerificationToken(
 	}
 
 	return &VerificationToken{
-		id:        valueobjects.NewTokenID(),
-		token:     token,
-		tokenType: tokenType,
-		userID:    userID,
-		expiresAt: expiresAt,
-		createdAt: createdAt,
-		usedAt:    usedAt,
+		id:              valueobjects.NewTokenID(),
+		token:           token,
+		tokenType:       tokenType,
+		userID:          userID,
+		intent:          intent,
+		codeVerifier:    codeVerifier,
+		provider:        provider,
+		browserNonce:    browserNonce,
+		clientChallenge: clientChallenge,
+		expiresAt:       expiresAt,
+		createdAt:       createdAt,
+		usedAt:          usedAt,
 	}, nil
 }
 
@@ -121,6 +223,68 @@ func (vt *VerificationToken) UserID() *valueobjects.UserID {
 	return vt.userID
 }
 
+// Intent returns what an OAuth state token was issued for
+func (vt *VerificationToken) Intent() OAuthIntent {
+	return vt.intent
+}
+
+// CodeVerifier returns the PKCE verifier of an OAuth state token. Empty for
+// flows started before PKCE was enabled
+func (vt *VerificationToken) CodeVerifier() string {
+	return vt.codeVerifier
+}
+
+// Provider returns the provider an OAuth state token was issued for
+func (vt *VerificationToken) Provider() AccountProvider {
+	return vt.provider
+}
+
+// BrowserNonce returns the value the browser that started an OAuth flow keeps
+// in a cookie, to be presented again on the callback
+func (vt *VerificationToken) BrowserNonce() string {
+	return vt.browserNonce
+}
+
+// IssuedToBrowser reports whether browserNonce is the nonce handed to the
+// browser that started the flow. States without a nonce match no browser
+func (vt *VerificationToken) IssuedToBrowser(browserNonce string) bool {
+	if vt.browserNonce == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(vt.browserNonce), []byte(browserNonce)) == 1
+}
+
+// BindToClient binds a login state or a one-time code to the client holding
+// the verifier of challenge, the unpadded base64url SHA-256 of the verifier
+// as in PKCE (RFC 7636, S256)
+func (vt *VerificationToken) BindToClient(challenge string) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(challenge)
+	if err != nil || len(decoded) != sha256.Size {
+		return errors.NewValidationError("code_challenge", "invalid", "Code challenge must be the base64url SHA-256 of the code verifier")
+	}
+
+	vt.clientChallenge = challenge
+	return nil
+}
+
+// ClientChallenge returns the challenge set by BindToClient, or "" for flows
+// bound to a browser
+func (vt *VerificationToken) ClientChallenge() string {
+	return vt.clientChallenge
+}
+
+// IssuedToClient reports whether codeVerifier is the verifier of the client
+// the token was bound to. Tokens not bound to a client match any caller
+func (vt *VerificationToken) IssuedToClient(codeVerifier string) bool {
+	if vt.clientChallenge == "" {
+		return true
+	}
+
+	sum := sha256.Sum256([]byte(codeVerifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(vt.clientChallenge), []byte(challenge)) == 1
+}
+
 func (vt *VerificationToken) ExpiresAt() time.Time {
 	return vt.expiresAt
 }
@@ -161,12 +325,17 @@ func (vt *VerificationToken) MarkAsUsed() error {
 }
 
 // ValidateForOAuth validates the token for OAuth flow
-// The state parameter should match the token itself
-func (vt *VerificationToken) ValidateForOAuth() error {
+// The state parameter should match the token itself, and the callback must
+// come from the provider the flow was started with
+func (vt *VerificationToken) ValidateForOAuth(provider AccountProvider) error {
 	if vt.tokenType != OAuthStateToken {
 		return errors.NewAuthenticationError("invalid_token_type", "Token is not an OAuth state token")
 	}
 
+	if vt.provider != provider {
+		return errors.NewAuthenticationError("invalid_state", "OAuth state was issued for another provider")
+	}
+
 	if !vt.IsValid() {
 		if vt.IsExpired() {
 			return errors.NewAuthenticationError("token_expired", "Verification token has expired")
@@ -177,6 +346,10 @@ func (vt *VerificationToken) ValidateForOAuth() error {
 		return errors.NewAuthenticationError("invalid_token", "Invalid verification token")
 	}
 
+	if vt.intent == OAuthLinkIntent && vt.userID == nil {
+		return errors.NewAuthenticationError("missing_user_id", "OAuth link state must have a user ID")
+	}
+
 	return nil
 }
 
@@ -203,6 +376,43 @@ func (vt *VerificationToken) ValidateForFrontend() error {
 	return nil
 }
 
+// ValidateForEmailVerification validates the token sent by email after registration
+func (vt *VerificationToken) ValidateForEmailVerification() error {
+	return vt.validateUserToken(EmailVerificationToken, "Token is not an email verification token")
+}
+
+// ValidateForPasswordReset validates the token sent by email on forgot-password
+func (vt *VerificationToken) ValidateForPasswordReset() error {
+	return vt.validateUserToken(PasswordResetToken, "Token is not a password reset token")
+}
+
+// ValidateForMFAChallenge validates the token returned by a login that requires a second factor
+func (vt *VerificationToken) ValidateForMFAChallenge() error {
+	return vt.validateUserToken(MFAChallengeToken, "Token is not an MFA challenge token")
+}
+
+func (vt *VerificationToken) validateUserToken(tokenType VerificationTokenType, invalidTypeMessage string) error {
+	if vt.tokenType != tokenType {
+		return errors.NewAuthenticationError("invalid_token_type", invalidTypeMessage)
+	}
+
+	if !vt.IsValid() {
+		if vt.IsExpired() {
+			return errors.NewAuthenticationError("token_expired", "Verification token has expired")
+		}
+		if vt.IsUsed() {
+			return errors.NewAuthenticationError("token_used", "Verification token has already been used")
+		}
+		return errors.NewAuthenticationError("invalid_token", "Invalid verification token")
+	}
+
+	if vt.userID == nil {
+		return errors.NewAuthenticationError("missing_user_id", "Verification token must have a user ID")
+	}
+
+	return nil
+}
+
 // generateSecureToken generates a cryptographically secure random token
 // for OAuth state parameters and verification tokens.
 // Uses 256 bits of entropy to prevent CSRF and replay attacks.
@@ -215,3 +425,13 @@ func generateSecureToken() (string, error) {
 	// URL-safe encoding (no padding needed for OAuth state)
 	return base64.URLEncoding.EncodeToString(b), nil
 }
+
+// generateCodeVerifier generates a PKCE code verifier (RFC 7636): 43 characters
+// from the unreserved set, which unpadded base64url of 32 bytes satisfies
+func generateCodeVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,84 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+func TestOAuthStateBinding(t *testing.T) {
+	state, err := NewOAuthStateToken(GoogleProvider, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("NewOAuthStateToken: %v", err)
+	}
+
+	legacy, err := ReconstructVerificationToken(state.Token(), OAuthStateToken, nil, OAuthLoginIntent, "", "", "", "", state.ExpiresAt(), state.CreatedAt(), nil)
+	if err != nil {
+		t.Fatalf("ReconstructVerificationToken: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		state        *VerificationToken
+		provider     AccountProvider
+		browserNonce string
+		wantCode     string
+		wantBrowser  bool
+	}{
+		{"same provider and browser", state, GoogleProvider, state.BrowserNonce(), "", true},
+		{"callback of another provider", state, AppleProvider, state.BrowserNonce(), "invalid_state", true},
+		{"another browser", state, GoogleProvider, "other", "", false},
+		{"browser without the cookie", state, GoogleProvider, "", "", false},
+		{"state issued before the binding", legacy, GoogleProvider, "", "invalid_state", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertErrorCode(t, tt.state.ValidateForOAuth(tt.provider), tt.wantCode)
+
+			if got := tt.state.IssuedToBrowser(tt.browserNonce); got != tt.wantBrowser {
+				t.Errorf("IssuedToBrowser = %v, want %v", got, tt.wantBrowser)
+			}
+		})
+	}
+}
+
+func TestOAuthClientBinding(t *testing.T) {
+	// challenge is the unpadded base64url SHA-256 of verifier
+	const verifier = "dBjftJeZ4CVP-mJ92K9qB3Tw8sSm5nZvKSNHA0j4DGs"
+	const challenge = "bJmvh5DMtRuVnPp4btAGfgzWUPtt7ZfB0zn5FqXvlVk"
+
+	code, err := NewFrontendVerificationToken(valueobjects.NewUserID(), 10*time.Minute)
+	if err != nil {
+		t.Fatalf("NewFrontendVerificationToken: %v", err)
+	}
+
+	if !code.IssuedToClient("") {
+		t.Error("a code of a browser flow should not need a verifier")
+	}
+
+	if err := code.BindToClient("plain"); err == nil {
+		t.Error("BindToClient accepted a challenge that is not a SHA-256")
+	}
+	assertErrorCode(t, code.BindToClient(challenge), "")
+
+	tests := []struct {
+		name     string
+		verifier string
+		want     bool
+	}{
+		{"matching verifier", verifier, true},
+		{"another verifier", "another-verifier-that-is-long-enough-for-pkce-1234", false},
+		{"no verifier", "", false},
+		{"the challenge itself", challenge, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := code.IssuedToClient(tt.verifier); got != tt.want {
+				t.Errorf("IssuedToClient = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
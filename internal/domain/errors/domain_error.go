@@ -1,6 +1,9 @@
 package errors
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type DomainError struct {
 	code    string
@@ -32,6 +35,8 @@ func (e *DomainError) IsDomainError() bool {
 
 type AuthenticationError struct {
 	*DomainError
+	retryAfter time.Duration
+	cause      error
 }
 
 func NewAuthenticationError(code, message string) *AuthenticationError {
@@ -40,6 +45,49 @@ func NewAuthenticationError(code, message string) *AuthenticationError {
 	}
 }
 
+// NewAuthenticationErrorWithCause keeps the underlying error for logs; only
+// message is meant for clients, as the cause may carry upstream details
+func NewAuthenticationErrorWithCause(code, message string, cause error) *AuthenticationError {
+	return &AuthenticationError{
+		DomainError: NewDomainError(code, message),
+		cause:       cause,
+	}
+}
+
+func (e *AuthenticationError) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%s: %v", e.DomainError.Error(), e.cause)
+	}
+	return e.DomainError.Error()
+}
+
+func (e *AuthenticationError) Unwrap() error {
+	return e.cause
+}
+
+// NewAccountLockedError reports too many failed logins; retryAfter tells the
+// client when it may try again
+func NewAccountLockedError(retryAfter time.Duration) *AuthenticationError {
+	return &AuthenticationError{
+		DomainError: NewDomainError("account_locked", "Too many failed login attempts, try again later"),
+		retryAfter:  retryAfter,
+	}
+}
+
+// NewRateLimitedError reports too many requests of an action that sends emails;
+// retryAfter tells the client when it may try again
+func NewRateLimitedError(retryAfter time.Duration) *AuthenticationError {
+	return &AuthenticationError{
+		DomainError: NewDomainError("rate_limited", "Too many requests, try again later"),
+		retryAfter:  retryAfter,
+	}
+}
+
+// RetryAfter is only set for account_locked and rate_limited errors
+func (e *AuthenticationError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
 type ValidationError struct {
 	*DomainError
 	field string
@@ -0,0 +1,9 @@
+package providers
+
+import "time"
+
+// Clock abstracts the current time so time-based logic (TOTP codes, lockouts)
+// can be exercised with a fake clock
+type Clock interface {
+	Now() time.Time
+}
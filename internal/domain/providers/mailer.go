@@ -0,0 +1,14 @@
+package providers
+
+import "context"
+
+type EmailMessage struct {
+	To      string
+	Subject string
+	Body    string // plain text
+}
+
+// Mailer delivers transactional emails (verification links, password resets...)
+type Mailer interface {
+	Send(ctx context.Context, message EmailMessage) error
+}
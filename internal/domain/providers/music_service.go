@@ -0,0 +1,122 @@
+package providers
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+var (
+	// ErrOperationNotSupported is returned by MusicService implementations for
+	// operations the streaming service does not offer (e.g. removing tracks
+	// from an Apple Music library playlist)
+	ErrOperationNotSupported = stderrors.New("operation not supported by the music service")
+	// ErrQuotaExhausted is returned once the app's API quota with the service
+	// is spent; callers should stop and resume after it resets
+	ErrQuotaExhausted = stderrors.New("music service quota exhausted")
+)
+
+// PageRequest asks for one page of a listing. Cursor is empty for the first
+// page and otherwise the Next value of the previous Page; Limit 0 uses the
+// service default and larger values are capped to the service maximum
+type PageRequest struct {
+	Cursor string
+	Limit  int
+}
+
+// Page is one page of a listing
+type Page[T any] struct {
+	Items []T
+	// Next is the cursor of the following page, empty on the last one
+	Next string
+	// Total is the size of the whole listing, or -1 when the service does
+	// not report it
+	Total int
+}
+
+// TrackQuery describes the track to look for. ISRC matches are exact and
+// preferred when set; otherwise Title, Artist and Album are combined using
+// the service's field filters where it has them
+type TrackQuery struct {
+	ISRC   string
+	Title  string
+	Artist string
+	Album  string
+	// Limit bounds the number of results; 0 uses the service default
+	Limit int
+}
+
+// NewPlaylist describes a playlist to create
+type NewPlaylist struct {
+	Name        string
+	Description string
+	Public      bool
+}
+
+// TrackMove moves Length tracks starting at position From so they end up
+// before the track that is at position InsertBefore. Positions are zero based
+// and refer to the playlist before the move
+type TrackMove struct {
+	From         int
+	Length       int
+	InsertBefore int
+}
+
+// MusicService is the catalog and library of a streaming service, acting on
+// behalf of a user. Implementations get the user's provider credentials
+// themselves, so a user without a linked account gets a NotFoundError and one
+// whose grant was revoked a provider_reauth_required AuthenticationError.
+// Playlist and track IDs are the service's own
+type MusicService interface {
+	// ListPlaylists lists the playlists in the user's library, including the
+	// ones they follow
+	ListPlaylists(ctx context.Context, userID valueobjects.UserID, page PageRequest) (*Page[valueobjects.Playlist], error)
+	// GetPlaylistTracks lists the tracks of a playlist in playlist order.
+	// Entries that are not tracks (podcast episodes, local files, removed
+	// songs) are skipped
+	GetPlaylistTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, page PageRequest) (*Page[valueobjects.Track], error)
+	// SearchTracks looks the track up in the service catalog, best match first
+	SearchTracks(ctx context.Context, userID valueobjects.UserID, query TrackQuery) ([]valueobjects.Track, error)
+	CreatePlaylist(ctx context.Context, userID valueobjects.UserID, playlist NewPlaylist) (*valueobjects.Playlist, error)
+	// AddTracks appends the tracks in order, batching as the service requires
+	AddTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, trackIDs []string) error
+	// RemoveTracks removes every occurrence of the tracks
+	RemoveTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, trackIDs []string) error
+	ReorderTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, move TrackMove) error
+}
+
+// MusicServiceRegistry holds the configured music services by account provider
+type MusicServiceRegistry struct {
+	services map[entities.AccountProvider]MusicService
+}
+
+func NewMusicServiceRegistry() *MusicServiceRegistry {
+	return &MusicServiceRegistry{
+		services: make(map[entities.AccountProvider]MusicService),
+	}
+}
+
+func (r *MusicServiceRegistry) Register(name entities.AccountProvider, service MusicService) {
+	r.services[name] = service
+}
+
+// Get returns the service registered under name, or a NotFoundError for
+// unknown or unconfigured services
+func (r *MusicServiceRegistry) Get(name entities.AccountProvider) (MusicService, error) {
+	service, ok := r.services[name]
+	if !ok {
+		return nil, errors.NewNotFoundError("music_service", "Music service not supported")
+	}
+	return service, nil
+}
+
+// MusicDeveloperTokenProvider issues the developer token that identifies the
+// app to a music service; client SDKs such as MusicKit JS need it to ask the
+// user for access
+type MusicDeveloperTokenProvider interface {
+	DeveloperToken() (token string, expiresAt time.Time, err error)
+}
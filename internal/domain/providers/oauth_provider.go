@@ -0,0 +1,85 @@
+package providers
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+)
+
+// OAuthTokens are the credentials returned by a provider's token endpoint
+type OAuthTokens struct {
+	AccessToken  string
+	RefreshToken string
+	// IDToken is only set by OpenID Connect providers (e.g. Apple)
+	IDToken   string
+	ExpiresAt time.Time
+	// Scope lists the granted scopes, space separated
+	Scope string
+}
+
+// OAuthIdentity is the user as described by a provider, normalized so login
+// and linking do not depend on each provider's user info format
+type OAuthIdentity struct {
+	// SubjectID is the provider's stable identifier for the user
+	SubjectID     string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+	AvatarURL     string
+}
+
+// OAuthProvider is an authorization code flow against a third-party identity
+// provider. codeVerifier is the PKCE verifier of the flow: the URL carries its
+// S256 challenge and the exchange sends it back
+type OAuthProvider interface {
+	GetAuthURL(state, codeVerifier string) string
+	ExchangeCode(ctx context.Context, code, codeVerifier string) (*OAuthTokens, error)
+	// GetIdentity describes the user the tokens were issued for
+	GetIdentity(ctx context.Context, tokens *OAuthTokens) (*OAuthIdentity, error)
+}
+
+// ErrRefreshTokenRevoked is returned by OAuthTokenRefresher when the provider
+// no longer accepts the refresh token (revoked by the user, expired, rotated)
+var ErrRefreshTokenRevoked = stderrors.New("refresh token revoked by provider")
+
+// OAuthTokenRefresher is implemented by providers whose access tokens can be
+// renewed with a refresh token. The returned refresh token is empty when the
+// provider keeps the previous one valid
+type OAuthTokenRefresher interface {
+	RefreshToken(ctx context.Context, refreshToken string) (*OAuthTokens, error)
+}
+
+// OAuthTokenRevoker is implemented by providers that let apps revoke the
+// user's grant, so unlinking an account also disconnects it upstream
+type OAuthTokenRevoker interface {
+	RevokeToken(ctx context.Context, tokens *OAuthTokens) error
+}
+
+// OAuthProviderRegistry holds the configured OAuth providers by account provider
+type OAuthProviderRegistry struct {
+	providers map[entities.AccountProvider]OAuthProvider
+}
+
+func NewOAuthProviderRegistry() *OAuthProviderRegistry {
+	return &OAuthProviderRegistry{
+		providers: make(map[entities.AccountProvider]OAuthProvider),
+	}
+}
+
+func (r *OAuthProviderRegistry) Register(name entities.AccountProvider, provider OAuthProvider) {
+	r.providers[name] = provider
+}
+
+// Get returns the provider registered under name, or a NotFoundError for
+// unknown or unconfigured providers
+func (r *OAuthProviderRegistry) Get(name entities.AccountProvider) (OAuthProvider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, errors.NewNotFoundError("oauth_provider", "OAuth provider not supported")
+	}
+	return provider, nil
+}
@@ -1,12 +0,0 @@
-package providers
-
-import (
-	"context"
-	"time"
-)
-
-type SpotifyOAuthProvider interface {
-	GetAuthURL(state string) string
-	ExchangeCode(ctx context.Context, code string) (accessToken, refreshToken string, expiresAt time.Time, err error)
-	GetUserInfo(ctx context.Context, accessToken string) (email, displayName string, err error)
-}
@@ -9,6 +9,9 @@ import (
 
 type AccountRepository interface {
 	Save(ctx context.Context, account *entities.Account) error
+	// FindByProviderSubject finds the account of a provider identity, whichever user owns it
+	FindByProviderSubject(ctx context.Context, provider entities.AccountProvider, subject string) (*entities.Account, error)
+	FindByUserID(ctx context.Context, userID valueobjects.UserID) ([]*entities.Account, error)
 	FindByUserIDAndProvider(ctx context.Context, userID valueobjects.UserID, provider entities.AccountProvider) (*entities.Account, error)
 	FindUserpassAccountByEmail(ctx context.Context, email valueobjects.Email) (*entities.Account, error)
 	Delete(ctx context.Context, id valueobjects.AccountID) error
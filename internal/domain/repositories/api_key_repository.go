@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+type APIKeyRepository interface {
+	Save(ctx context.Context, key *entities.APIKey) error
+	FindByID(ctx context.Context, id valueobjects.APIKeyID) (*entities.APIKey, error)
+	FindByHash(ctx context.Context, hash string) (*entities.APIKey, error)
+	FindByUserID(ctx context.Context, userID valueobjects.UserID) ([]*entities.APIKey, error)
+	Delete(ctx context.Context, id valueobjects.APIKeyID) error
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+)
+
+type LoginAttemptRepository interface {
+	// Find returns the failures recorded for key; unknown keys yield an empty record
+	Find(ctx context.Context, key string) (*entities.LoginAttempts, error)
+
+	// RegisterFailure atomically applies a failed attempt to the record of key,
+	// so concurrent requests cannot slip past the limits, and returns the result
+	RegisterFailure(ctx context.Context, key string, now time.Time, policy entities.LockoutPolicy) (*entities.LoginAttempts, error)
+
+	// Reset forgets the failures of key
+	Reset(ctx context.Context, key string) error
+
+	// CleanupExpired removes records with no failure since olderThan and no active lock
+	CleanupExpired(ctx context.Context, olderThan time.Time) error
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+// RevokedTokenRepository is the denylist of access tokens (by jti) that must be
+// rejected before their natural expiry
+type RevokedTokenRepository interface {
+	// Revoke adds an access token ID to the denylist until expiresAt
+	Revoke(ctx context.Context, tokenID string, userID valueobjects.UserID, expiresAt time.Time) error
+
+	// IsRevoked reports whether the access token ID is on the denylist
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+
+	// CleanupExpired removes entries whose tokens have expired anyway
+	CleanupExpired(ctx context.Context) error
+}
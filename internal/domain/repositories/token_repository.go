@@ -10,7 +10,19 @@ import (
 type TokenRepository interface {
 	SaveRefreshToken(ctx context.Context, token *entities.RefreshToken) error
 	FindRefreshToken(ctx context.Context, token string) (*entities.RefreshToken, error)
+	FindUserRefreshTokens(ctx context.Context, userID valueobjects.UserID) ([]*entities.RefreshToken, error)
+	FindSession(ctx context.Context, sessionID valueobjects.SessionID) (*entities.RefreshToken, error)
+	// RotateRefreshToken deletes previous and saves next atomically, keeping a
+	// record of previous until it expires. It returns a NotFoundError when
+	// previous was already consumed, so concurrent rotations of the same token
+	// cannot both succeed
+	RotateRefreshToken(ctx context.Context, previous string, next *entities.RefreshToken) error
+	// FindRotatedSession returns the session of a token replaced by
+	// RotateRefreshToken, or a NotFoundError for tokens that were never
+	// rotated, such as those deleted on logout
+	FindRotatedSession(ctx context.Context, token string) (valueobjects.SessionID, error)
 	DeleteRefreshToken(ctx context.Context, token string) error
 	DeleteUserRefreshTokens(ctx context.Context, userID valueobjects.UserID) error
+	DeleteSession(ctx context.Context, sessionID valueobjects.SessionID) error
 	CleanupExpiredTokens(ctx context.Context) error
 }
\ No newline at end of file
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+type TwoFactorRepository interface {
+	// Save stores the enrollment together with its recovery codes
+	Save(ctx context.Context, twoFactor *entities.TwoFactor) error
+	// RecordUse stores the code accepted by TwoFactor.Verify only if it is still
+	// unused, so of two concurrent logins with the same code exactly one
+	// succeeds. The other gets mfa_code_reused
+	RecordUse(ctx context.Context, twoFactor *entities.TwoFactor) error
+	FindByUserID(ctx context.Context, userID valueobjects.UserID) (*entities.TwoFactor, error)
+	Delete(ctx context.Context, userID valueobjects.UserID) error
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
 )
 
 type VerificationRepository interface {
@@ -13,12 +14,18 @@ type VerificationRepository interface {
 	// FindByToken retrieves a verification token by its token string
 	FindByToken(ctx context.Context, token string) (*entities.VerificationToken, error)
 
-	// Update updates a verification token (e.g., marking as used)
+	// Update marks a verification token as used. It fails with
+	// token_already_used when the token was consumed in the meantime, so
+	// callers must run it before acting on the token
 	Update(ctx context.Context, token *entities.VerificationToken) error
 
 	// Delete removes a verification token
 	Delete(ctx context.Context, token string) error
 
+	// DeleteByUser removes every token of the given type issued for a user,
+	// invalidating outstanding links when a new one is sent
+	DeleteByUser(ctx context.Context, userID valueobjects.UserID, tokenType entities.VerificationTokenType) error
+
 	// CleanupExpired removes all expired tokens
 	CleanupExpired(ctx context.Context) error
 }
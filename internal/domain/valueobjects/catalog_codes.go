@@ -0,0 +1,82 @@
+package valueobjects
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+)
+
+// ISRC identifies a recording across streaming services, which makes it the
+// most reliable key to match a track from one catalog in another
+type ISRC struct {
+	value string
+}
+
+// country (2) + registrant (3) + year (2) + designation (5)
+var isrcRegex = regexp.MustCompile(`^[A-Z]{2}[A-Z0-9]{3}[0-9]{7}$`)
+
+// NewISRC normalizes the code, which providers return with or without dashes
+// and in either case
+func NewISRC(code string) (ISRC, error) {
+	code = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+
+	if !isrcRegex.MatchString(code) {
+		return ISRC{}, errors.NewDomainError("invalid_isrc", "ISRC format is invalid")
+	}
+
+	return ISRC{value: code}, nil
+}
+
+func (i ISRC) Value() string {
+	return i.value
+}
+
+func (i ISRC) String() string {
+	return i.value
+}
+
+func (i ISRC) IsEmpty() bool {
+	return i.value == ""
+}
+
+func (i ISRC) Equals(other ISRC) bool {
+	return i.value == other.value
+}
+
+// UPC identifies a release (album, single). Providers return UPC-A (12 digits),
+// EAN-13 or GTIN-14 codes; they are kept as returned, without leading zeros
+// being added or removed
+type UPC struct {
+	value string
+}
+
+var upcRegex = regexp.MustCompile(`^[0-9]{12,14}$`)
+
+func NewUPC(code string) (UPC, error) {
+	code = strings.TrimSpace(code)
+
+	if !upcRegex.MatchString(code) {
+		return UPC{}, errors.NewDomainError("invalid_upc", "UPC format is invalid")
+	}
+
+	return UPC{value: code}, nil
+}
+
+func (u UPC) Value() string {
+	return u.value
+}
+
+func (u UPC) String() string {
+	return u.value
+}
+
+func (u UPC) IsEmpty() bool {
+	return u.value == ""
+}
+
+// Equals compares the codes ignoring the zero padding that tells UPC-A from
+// EAN-13 and GTIN-14
+func (u UPC) Equals(other UPC) bool {
+	return strings.TrimLeft(u.value, "0") == strings.TrimLeft(other.value, "0")
+}
@@ -0,0 +1,127 @@
+package valueobjects
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxUserAgentLength   = 512
+	maxDeviceLabelLength = 100
+)
+
+// DeviceInfo describes the client a session was started from
+type DeviceInfo struct {
+	userAgent string
+	ipAddress string
+	label     string
+}
+
+// NewDeviceInfo builds the device information from the raw request data,
+// deriving a human readable label from the user agent
+func NewDeviceInfo(userAgent, ipAddress string) DeviceInfo {
+	// Headers may carry any bytes; the database only stores valid UTF-8
+	userAgent = truncate(strings.ToValidUTF8(strings.TrimSpace(userAgent), ""), maxUserAgentLength)
+
+	return DeviceInfo{
+		userAgent: userAgent,
+		ipAddress: strings.TrimSpace(ipAddress),
+		label:     deviceLabelFromUserAgent(userAgent),
+	}
+}
+
+func ReconstructDeviceInfo(userAgent, ipAddress, label string) DeviceInfo {
+	return DeviceInfo{
+		userAgent: userAgent,
+		ipAddress: ipAddress,
+		label:     label,
+	}
+}
+
+func (d DeviceInfo) UserAgent() string {
+	return d.userAgent
+}
+
+func (d DeviceInfo) IPAddress() string {
+	return d.ipAddress
+}
+
+func (d DeviceInfo) Label() string {
+	return d.label
+}
+
+func deviceLabelFromUserAgent(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	platform := detectPlatform(userAgent)
+	browser := detectBrowser(userAgent)
+
+	var label string
+	switch {
+	case browser != "" && platform != "":
+		label = browser + " on " + platform
+	case platform != "":
+		label = platform
+	case browser != "":
+		label = browser
+	default:
+		label = "Unknown device"
+	}
+
+	return truncate(label, maxDeviceLabelLength)
+}
+
+func detectPlatform(userAgent string) string {
+	// Order matters: iOS and Android user agents also mention other platforms
+	switch {
+	case strings.Contains(userAgent, "iPhone"):
+		return "iPhone"
+	case strings.Contains(userAgent, "iPad"):
+		return "iPad"
+	case strings.Contains(userAgent, "Android"):
+		return "Android"
+	case strings.Contains(userAgent, "CrOS"):
+		return "ChromeOS"
+	case strings.Contains(userAgent, "Windows"):
+		return "Windows"
+	case strings.Contains(userAgent, "Macintosh"), strings.Contains(userAgent, "Mac OS X"):
+		return "macOS"
+	case strings.Contains(userAgent, "Linux"):
+		return "Linux"
+	default:
+		return ""
+	}
+}
+
+func detectBrowser(userAgent string) string {
+	// Order matters: Edge and Chrome user agents also mention Safari
+	switch {
+	case strings.Contains(userAgent, "Edg/"):
+		return "Edge"
+	case strings.Contains(userAgent, "OPR/"):
+		return "Opera"
+	case strings.Contains(userAgent, "Firefox/"):
+		return "Firefox"
+	case strings.Contains(userAgent, "Chrome/"), strings.Contains(userAgent, "CriOS/"):
+		return "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		return "Safari"
+	default:
+		return ""
+	}
+}
+
+// truncate cuts value to at most maxLength bytes without splitting a character
+func truncate(value string, maxLength int) string {
+	if len(value) <= maxLength {
+		return value
+	}
+
+	cut := maxLength
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut]
+}
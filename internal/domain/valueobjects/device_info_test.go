@@ -0,0 +1,34 @@
+package valueobjects
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNewDeviceInfoKeepsUserAgentValidUTF8(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		wantLen   int
+	}{
+		{"short", "Mozilla/5.0 Firefox/120.0", 25},
+		{"long ASCII", strings.Repeat("a", maxUserAgentLength+10), maxUserAgentLength},
+		// "é" takes two bytes, so the limit falls in the middle of one
+		{"long non-ASCII", "a" + strings.Repeat("é", maxUserAgentLength), maxUserAgentLength - 1},
+		{"invalid bytes", "Mozilla\xff/5.0", 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device := NewDeviceInfo(tt.userAgent, "203.0.113.7")
+
+			if !utf8.ValidString(device.UserAgent()) {
+				t.Fatalf("user agent %q is not valid UTF-8", device.UserAgent())
+			}
+			if got := len(device.UserAgent()); got != tt.wantLen {
+				t.Errorf("user agent length = %d, want %d", got, tt.wantLen)
+			}
+		})
+	}
+}
@@ -57,6 +57,8 @@ type (
 	UserID    ID
 	AccountID ID
 	TokenID   ID
+	SessionID ID
+	APIKeyID  ID
 )
 
 // UserID specific constructors and methods
@@ -168,4 +170,78 @@ func (id TokenID) Equals(other TokenID) bool {
 
 func (id TokenID) IsEmpty() bool {
 	return ID(id).IsEmpty()
-}
\ No newline at end of file
+}
+
+// SessionID specific constructors and methods
+func NewSessionID() SessionID {
+	return SessionID(NewID())
+}
+
+func ReconstructSessionID(id uuid.UUID) (SessionID, error) {
+	baseID, err := ReconstructID(id)
+	if err != nil {
+		return SessionID{}, err
+	}
+	return SessionID(baseID), nil
+}
+
+func ParseSessionID(s string) (SessionID, error) {
+	baseID, err := ParseID(s)
+	if err != nil {
+		return SessionID{}, err
+	}
+	return SessionID(baseID), nil
+}
+
+func (id SessionID) Value() uuid.UUID {
+	return ID(id).Value()
+}
+
+func (id SessionID) String() string {
+	return ID(id).String()
+}
+
+func (id SessionID) Equals(other SessionID) bool {
+	return ID(id).Equals(ID(other))
+}
+
+func (id SessionID) IsEmpty() bool {
+	return ID(id).IsEmpty()
+}
+
+// APIKeyID specific constructors and methods
+func NewAPIKeyID() APIKeyID {
+	return APIKeyID(NewID())
+}
+
+func ReconstructAPIKeyID(id uuid.UUID) (APIKeyID, error) {
+	baseID, err := ReconstructID(id)
+	if err != nil {
+		return APIKeyID{}, err
+	}
+	return APIKeyID(baseID), nil
+}
+
+func ParseAPIKeyID(s string) (APIKeyID, error) {
+	baseID, err := ParseID(s)
+	if err != nil {
+		return APIKeyID{}, err
+	}
+	return APIKeyID(baseID), nil
+}
+
+func (id APIKeyID) Value() uuid.UUID {
+	return ID(id).Value()
+}
+
+func (id APIKeyID) String() string {
+	return ID(id).String()
+}
+
+func (id APIKeyID) Equals(other APIKeyID) bool {
+	return ID(id).Equals(ID(other))
+}
+
+func (id APIKeyID) IsEmpty() bool {
+	return ID(id).IsEmpty()
+}
@@ -0,0 +1,63 @@
+package valueobjects
+
+import (
+	"strings"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+)
+
+// Playlist is a user playlist normalized across streaming services. id is the
+// service's identifier; trackCount is as reported by the service when listing
+type Playlist struct {
+	id          string
+	name        string
+	description string
+	ownerID     string
+	trackCount  int
+	public      bool
+}
+
+func NewPlaylist(id, name, description, ownerID string, trackCount int, public bool) (Playlist, error) {
+	if id == "" {
+		return Playlist{}, errors.NewDomainError("empty_playlist_id", "Playlist ID cannot be empty")
+	}
+
+	if trackCount < 0 {
+		return Playlist{}, errors.NewDomainError("invalid_track_count", "Track count cannot be negative")
+	}
+
+	return Playlist{
+		id:          id,
+		name:        strings.TrimSpace(name),
+		description: strings.TrimSpace(description),
+		ownerID:     ownerID,
+		trackCount:  trackCount,
+		public:      public,
+	}, nil
+}
+
+func (p Playlist) ID() string {
+	return p.id
+}
+
+func (p Playlist) Name() string {
+	return p.name
+}
+
+func (p Playlist) Description() string {
+	return p.description
+}
+
+// OwnerID is the service's identifier of the owner; playlists the user follows
+// but does not own cannot be modified
+func (p Playlist) OwnerID() string {
+	return p.ownerID
+}
+
+func (p Playlist) TrackCount() int {
+	return p.trackCount
+}
+
+func (p Playlist) IsPublic() bool {
+	return p.public
+}
@@ -0,0 +1,123 @@
+package valueobjects
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+)
+
+// TOTP parameters (RFC 6238 defaults, understood by every authenticator app)
+const (
+	totpSecretSize = 20 // 160 bits, as recommended by RFC 4226
+	totpDigits     = 6
+	totpPeriod     = 30 * time.Second
+	// totpSkew accepts codes from the previous and next period to absorb
+	// clock drift between the server and the user's device
+	totpSkew = 1
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPSecret is the shared key of a time-based one-time password generator
+type TOTPSecret struct {
+	value []byte
+}
+
+func NewTOTPSecret() (TOTPSecret, error) {
+	secret := make([]byte, totpSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return TOTPSecret{}, errors.NewDomainError("totp_secret_generation_failed", "Failed to generate TOTP secret")
+	}
+	return TOTPSecret{value: secret}, nil
+}
+
+// ReconstructTOTPSecret decodes a secret stored in its base32 form
+func ReconstructTOTPSecret(encoded string) (TOTPSecret, error) {
+	secret, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(encoded, "=")))
+	if err != nil || len(secret) == 0 {
+		return TOTPSecret{}, errors.NewDomainError("invalid_totp_secret", "Invalid TOTP secret")
+	}
+	return TOTPSecret{value: secret}, nil
+}
+
+// String returns the base32 encoding users type into authenticator apps
+func (s TOTPSecret) String() string {
+	return totpEncoding.EncodeToString(s.value)
+}
+
+func (s TOTPSecret) IsEmpty() bool {
+	return len(s.value) == 0
+}
+
+// ProvisioningURI builds the otpauth:// URI encoded in enrollment QR codes
+func (s TOTPSecret) ProvisioningURI(issuer, accountName string) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(accountName)
+
+	query := url.Values{}
+	query.Set("secret", s.String())
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprintf("%d", totpDigits))
+	query.Set("period", fmt.Sprintf("%d", int(totpPeriod.Seconds())))
+
+	// Some authenticator apps show "+" literally, so spaces are percent-encoded
+	return "otpauth://totp/" + label + "?" + strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+// GenerateCode returns the code valid at the given time
+func (s TOTPSecret) GenerateCode(at time.Time) string {
+	return s.codeForStep(TOTPStep(at))
+}
+
+// Verify checks a code against the periods around the given time and returns
+// the matching time step, which callers persist to reject replays
+func (s TOTPSecret) Verify(code string, at time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := TOTPStep(at)
+	for offset := int64(-totpSkew); offset <= totpSkew; offset++ {
+		step := current + offset
+		if subtle.ConstantTimeCompare([]byte(s.codeForStep(step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// TOTPStep is the number of TOTP periods elapsed since the Unix epoch
+func TOTPStep(at time.Time) int64 {
+	return at.Unix() / int64(totpPeriod.Seconds())
+}
+
+// codeForStep implements HOTP (RFC 4226) with dynamic truncation
+func (s TOTPSecret) codeForStep(step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, s.value)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	binaryCode := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%0*d", totpDigits, binaryCode%modulo)
+}
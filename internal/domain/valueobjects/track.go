@@ -0,0 +1,138 @@
+package valueobjects
+
+import (
+	"strings"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+)
+
+// Artist is an artist as listed by a streaming service. The ID is only
+// meaningful within that service
+type Artist struct {
+	id   string
+	name string
+}
+
+func NewArtist(id, name string) (Artist, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Artist{}, errors.NewDomainError("empty_artist_name", "Artist name cannot be empty")
+	}
+
+	return Artist{id: id, name: name}, nil
+}
+
+func (a Artist) ID() string {
+	return a.id
+}
+
+func (a Artist) Name() string {
+	return a.name
+}
+
+// Album is the release a track belongs to. upc is empty when the service does
+// not expose it
+type Album struct {
+	id   string
+	name string
+	upc  UPC
+}
+
+func NewAlbum(id, name string, upc UPC) Album {
+	return Album{id: id, name: strings.TrimSpace(name), upc: upc}
+}
+
+func (a Album) ID() string {
+	return a.id
+}
+
+func (a Album) Name() string {
+	return a.name
+}
+
+func (a Album) UPC() UPC {
+	return a.upc
+}
+
+// Track is a song normalized across streaming services. id is the service's
+// identifier, used to add or remove it from playlists there; isrc is empty
+// when the service does not expose it
+type Track struct {
+	id       string
+	name     string
+	artists  []Artist
+	album    Album
+	duration time.Duration
+	explicit bool
+	isrc     ISRC
+}
+
+func NewTrack(
+	id string,
+	name string,
+	artists []Artist,
+	album Album,
+	duration time.Duration,
+	explicit bool,
+	isrc ISRC,
+) (Track, error) {
+	if id == "" {
+		return Track{}, errors.NewDomainError("empty_track_id", "Track ID cannot be empty")
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Track{}, errors.NewDomainError("empty_track_name", "Track name cannot be empty")
+	}
+
+	if duration < 0 {
+		return Track{}, errors.NewDomainError("invalid_track_duration", "Track duration cannot be negative")
+	}
+
+	return Track{
+		id:       id,
+		name:     name,
+		artists:  append([]Artist(nil), artists...),
+		album:    album,
+		duration: duration,
+		explicit: explicit,
+		isrc:     isrc,
+	}, nil
+}
+
+func (t Track) ID() string {
+	return t.id
+}
+
+func (t Track) Name() string {
+	return t.name
+}
+
+func (t Track) Artists() []Artist {
+	return append([]Artist(nil), t.artists...)
+}
+
+// PrimaryArtist is the first credited artist, or an empty Artist
+func (t Track) PrimaryArtist() Artist {
+	if len(t.artists) == 0 {
+		return Artist{}
+	}
+	return t.artists[0]
+}
+
+func (t Track) Album() Album {
+	return t.album
+}
+
+func (t Track) Duration() time.Duration {
+	return t.duration
+}
+
+func (t Track) IsExplicit() bool {
+	return t.explicit
+}
+
+func (t Track) ISRC() ISRC {
+	return t.isrc
+}
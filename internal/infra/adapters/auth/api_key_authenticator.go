@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/middleware"
+	usecases_auth "github.com/zandomed/sync-playlist-api/internal/usecases/auth"
+)
+
+// APIKeyAuthenticator adapts AuthenticateAPIKeyUseCase to the middleware
+type APIKeyAuthenticator struct {
+	uc *usecases_auth.AuthenticateAPIKeyUseCase
+}
+
+func NewAPIKeyAuthenticator(uc *usecases_auth.AuthenticateAPIKeyUseCase) middleware.APIKeyAuthenticator {
+	return &APIKeyAuthenticator{uc: uc}
+}
+
+func (a *APIKeyAuthenticator) AuthenticateAPIKey(ctx context.Context, key string) (*middleware.Claims, error) {
+	response, err := a.uc.Execute(ctx, usecases_auth.AuthenticateAPIKeyRequest{Key: key})
+	if err != nil {
+		// Unknown or expired keys and locked users are rejected, not failures
+		if _, ok := err.(*errors.AuthenticationError); ok {
+			return nil, nil
+		}
+		if _, ok := err.(*errors.NotFoundError); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	userID, err := uuid.Parse(response.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &middleware.Claims{
+		UserID:   userID,
+		Email:    response.Email,
+		Scope:    strings.Join(response.Scopes, " "),
+		APIKeyID: response.KeyID,
+	}, nil
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	services "github.com/zandomed/sync-playlist-api/internal/infra/services/auth"
+)
+
+type AppleOAuthAdapter struct {
+	service *services.AppleOAuthService
+}
+
+func NewAppleOAuthAdapter(service *services.AppleOAuthService) providers.OAuthProvider {
+	return &AppleOAuthAdapter{
+		service: service,
+	}
+}
+
+// GetAuthURL ignores the PKCE verifier: Sign in with Apple does not support
+// PKCE, and the client secret JWT already authenticates the exchange
+func (a *AppleOAuthAdapter) GetAuthURL(state, codeVerifier string) string {
+	return a.service.GetAuthURL(state)
+}
+
+func (a *AppleOAuthAdapter) ExchangeCode(ctx context.Context, code, codeVerifier string) (*providers.OAuthTokens, error) {
+	token, err := a.service.ExchangeCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := toOAuthTokens(token)
+	if tokens.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+	return tokens, nil
+}
+
+func (a *AppleOAuthAdapter) RevokeToken(ctx context.Context, tokens *providers.OAuthTokens) error {
+	if tokens.RefreshToken != "" {
+		return a.service.RevokeToken(ctx, tokens.RefreshToken, "refresh_token")
+	}
+	return a.service.RevokeToken(ctx, tokens.AccessToken, "access_token")
+}
+
+// GetIdentity reads the user from the signed id_token: Apple has no userinfo
+// endpoint, and shares the name only in the first callback
+func (a *AppleOAuthAdapter) GetIdentity(ctx context.Context, tokens *providers.OAuthTokens) (*providers.OAuthIdentity, error) {
+	userInfo, err := a.service.VerifyIDToken(ctx, tokens.IDToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &providers.OAuthIdentity{
+		SubjectID:     userInfo.Subject,
+		Email:         userInfo.Email,
+		EmailVerified: userInfo.EmailVerified,
+	}, nil
+}
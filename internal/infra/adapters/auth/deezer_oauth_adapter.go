@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	services "github.com/zandomed/sync-playlist-api/internal/infra/services/auth"
+)
+
+// deezerOfflineTokenLifetime stands in for the expiry of offline_access
+// tokens, which never expire, so they are not taken for expired ones
+const deezerOfflineTokenLifetime = 10 * 365 * 24 * time.Hour
+
+type DeezerOAuthAdapter struct {
+	service *services.DeezerOAuthService
+}
+
+func NewDeezerOAuthAdapter(service *services.DeezerOAuthService) providers.OAuthProvider {
+	return &DeezerOAuthAdapter{
+		service: service,
+	}
+}
+
+// GetAuthURL ignores the PKCE verifier: Deezer does not support PKCE, and the
+// app secret authenticates the exchange
+func (a *DeezerOAuthAdapter) GetAuthURL(state, codeVerifier string) string {
+	return a.service.GetAuthURL(state)
+}
+
+// ExchangeCode returns no refresh token: Deezer has none, and a user whose
+// token stops working has to link the account again
+func (a *DeezerOAuthAdapter) ExchangeCode(ctx context.Context, code, codeVerifier string) (*providers.OAuthTokens, error) {
+	token, err := a.service.ExchangeCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	lifetime := deezerOfflineTokenLifetime
+	if seconds, err := strconv.Atoi(token.Expires.String()); err == nil && seconds > 0 {
+		lifetime = time.Duration(seconds) * time.Second
+	}
+
+	return &providers.OAuthTokens{
+		AccessToken: token.AccessToken,
+		ExpiresAt:   time.Now().Add(lifetime),
+		Scope:       strings.Join(services.DeezerPermissions, " "),
+	}, nil
+}
+
+func (a *DeezerOAuthAdapter) GetIdentity(ctx context.Context, tokens *providers.OAuthTokens) (*providers.OAuthIdentity, error) {
+	userInfo, err := a.service.GetUserInfo(ctx, tokens.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	firstName, lastName := userInfo.FirstName, userInfo.LastName
+	if firstName == "" && lastName == "" {
+		firstName, lastName = splitDisplayName(userInfo.Name)
+	}
+
+	return &providers.OAuthIdentity{
+		SubjectID: strconv.FormatInt(userInfo.ID, 10),
+		Email:     userInfo.Email,
+		// Deezer does not report whether the email address was confirmed
+		EmailVerified: false,
+		FirstName:     firstName,
+		LastName:      lastName,
+		AvatarURL:     userInfo.Picture,
+	}, nil
+}
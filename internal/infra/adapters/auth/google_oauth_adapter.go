@@ -2,6 +2,8 @@ package auth
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
@@ -13,39 +15,90 @@ type GoogleOAuthAdapter struct {
 	service *services.GoogleOAuthService
 }
 
-func NewGoogleOAuthAdapter(service *services.GoogleOAuthService) providers.GoogleOAuthProvider {
+func NewGoogleOAuthAdapter(service *services.GoogleOAuthService) providers.OAuthProvider {
 	return &GoogleOAuthAdapter{
 		service: service,
 	}
 }
 
-func (a *GoogleOAuthAdapter) GetAuthURL(state string) string {
-	return a.service.GetAuthURL(state)
+func (a *GoogleOAuthAdapter) GetAuthURL(state, codeVerifier string) string {
+	return a.service.GetAuthURL(state, codeVerifier)
 }
 
-func (a *GoogleOAuthAdapter) ExchangeCode(ctx context.Context, code string) (accessToken, refreshToken string, expiresAt time.Time, err error) {
-	token, err := a.service.ExchangeCode(ctx, code)
+func (a *GoogleOAuthAdapter) ExchangeCode(ctx context.Context, code, codeVerifier string) (*providers.OAuthTokens, error) {
+	token, err := a.service.ExchangeCode(ctx, code, codeVerifier)
 	if err != nil {
-		return "", "", time.Time{}, err
+		return nil, err
 	}
 
-	expiry := token.Expiry
-	if expiry.IsZero() {
-		expiry = time.Now().Add(time.Hour)
+	return toOAuthTokens(token), nil
+}
+
+func (a *GoogleOAuthAdapter) RefreshToken(ctx context.Context, refreshToken string) (*providers.OAuthTokens, error) {
+	token, err := a.service.RefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, toRefreshError(err)
 	}
 
-	return token.AccessToken, token.RefreshToken, expiry, nil
+	return toOAuthTokens(token), nil
 }
 
-func (a *GoogleOAuthAdapter) GetUserInfo(ctx context.Context, accessToken string) (email, name, familyName string, err error) {
+// RevokeToken revokes the refresh token when there is one, which also ends
+// the access tokens issued from it
+func (a *GoogleOAuthAdapter) RevokeToken(ctx context.Context, tokens *providers.OAuthTokens) error {
+	token := tokens.RefreshToken
+	if token == "" {
+		token = tokens.AccessToken
+	}
+	return a.service.RevokeToken(ctx, token)
+}
+
+func (a *GoogleOAuthAdapter) GetIdentity(ctx context.Context, tokens *providers.OAuthTokens) (*providers.OAuthIdentity, error) {
 	token := &oauth2.Token{
-		AccessToken: accessToken,
+		AccessToken: tokens.AccessToken,
 	}
 
 	userInfo, err := a.service.GetUserInfo(ctx, token)
 	if err != nil {
-		return "", "", "", err
+		return nil, err
 	}
 
-	return userInfo.Email, userInfo.GivenName, userInfo.FamilyName, nil
+	return &providers.OAuthIdentity{
+		SubjectID:     userInfo.ID,
+		Email:         userInfo.Email,
+		EmailVerified: userInfo.VerifiedEmail,
+		FirstName:     userInfo.GivenName,
+		LastName:      userInfo.FamilyName,
+		AvatarURL:     userInfo.Picture,
+	}, nil
+}
+
+// toOAuthTokens converts an oauth2 token, assuming one hour of validity when
+// the provider does not report an expiry
+func toOAuthTokens(token *oauth2.Token) *providers.OAuthTokens {
+	expiry := token.Expiry
+	if expiry.IsZero() {
+		expiry = time.Now().Add(time.Hour)
+	}
+
+	idToken, _ := token.Extra("id_token").(string)
+	scope, _ := token.Extra("scope").(string)
+
+	return &providers.OAuthTokens{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		IDToken:      idToken,
+		ExpiresAt:    expiry,
+		Scope:        scope,
+	}
+}
+
+// toRefreshError reports invalid_grant, the OAuth 2.0 error for a refresh
+// token that is revoked or expired, as providers.ErrRefreshTokenRevoked
+func toRefreshError(err error) error {
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant" {
+		return fmt.Errorf("%w: %s", providers.ErrRefreshTokenRevoked, retrieveErr.ErrorDescription)
+	}
+	return err
 }
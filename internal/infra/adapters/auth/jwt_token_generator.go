@@ -1,22 +1,32 @@
 package auth
 
 import (
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
 	usecases_auth "github.com/zandomed/sync-playlist-api/internal/usecases/auth"
+	"github.com/zandomed/sync-playlist-api/pkg/jwtkeys"
+)
+
+// Values of the token_use claim. Both token types are signed by the same key
+// ring, so the claim is what keeps one from being accepted as the other
+const (
+	accessTokenUse  = "access"
+	refreshTokenUse = "refresh"
 )
 
 type JWTTokenGenerator struct {
-	secretKey              string
+	keyRing                *jwtkeys.KeyRing
 	accessTokenExpiration  time.Duration
 	refreshTokenExpiration time.Duration
 }
 
-func NewJWTTokenGenerator(secretKey string, accessTokenExp, refreshTokenExp time.Duration) usecases_auth.TokenGenerator {
+func NewJWTTokenGenerator(keyRing *jwtkeys.KeyRing, accessTokenExp, refreshTokenExp time.Duration) usecases_auth.TokenGenerator {
 	return &JWTTokenGenerator{
-		secretKey:              secretKey,
+		keyRing:                keyRing,
 		accessTokenExpiration:  accessTokenExp,
 		refreshTokenExpiration: refreshTokenExp,
 	}
@@ -25,20 +35,29 @@ func NewJWTTokenGenerator(secretKey string, accessTokenExp, refreshTokenExp time
 type AccessTokenClaims struct {
 	UserID string `json:"user_id"`
 	Email  string `json:"email"`
+	Role   string `json:"role"`
+	// Scope is space separated, as in OAuth 2.0
+	Scope    string `json:"scope,omitempty"`
+	TokenUse string `json:"token_use"`
 	jwt.RegisteredClaims
 }
 
 type RefreshTokenClaims struct {
-	UserID string `json:"user_id"`
+	UserID   string `json:"user_id"`
+	TokenUse string `json:"token_use"`
 	jwt.RegisteredClaims
 }
 
-func (g *JWTTokenGenerator) GenerateAccessToken(userID string, email string) (string, error) {
+func (g *JWTTokenGenerator) GenerateAccessToken(tokenID string, userID string, email string, role string, scopes []string) (string, error) {
 
 	claims := &AccessTokenClaims{
-		UserID: userID,
-		Email:  email,
+		UserID:   userID,
+		Email:    email,
+		Role:     role,
+		Scope:    strings.Join(scopes, " "),
+		TokenUse: accessTokenUse,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(g.accessTokenExpiration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -46,8 +65,7 @@ func (g *JWTTokenGenerator) GenerateAccessToken(userID string, email string) (st
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(g.secretKey))
+	tokenString, err := g.keyRing.Sign(claims)
 	if err != nil {
 		return "", errors.NewDomainError("token_generation_failed", "Failed to generate access token")
 	}
@@ -57,8 +75,12 @@ func (g *JWTTokenGenerator) GenerateAccessToken(userID string, email string) (st
 
 func (g *JWTTokenGenerator) GenerateRefreshToken(userID string) (string, error) {
 	claims := &RefreshTokenClaims{
-		UserID: userID,
+		UserID:   userID,
+		TokenUse: refreshTokenUse,
 		RegisteredClaims: jwt.RegisteredClaims{
+			// A unique ID keeps tokens issued within the same second distinct,
+			// which rotation relies on
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(g.refreshTokenExpiration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -66,8 +88,7 @@ func (g *JWTTokenGenerator) GenerateRefreshToken(userID string) (string, error)
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(g.secretKey))
+	tokenString, err := g.keyRing.Sign(claims)
 	if err != nil {
 		return "", errors.NewDomainError("token_generation_failed", "Failed to generate refresh token")
 	}
@@ -75,23 +96,37 @@ func (g *JWTTokenGenerator) GenerateRefreshToken(userID string) (string, error)
 	return tokenString, nil
 }
 
+func (g *JWTTokenGenerator) GetAccessTokenExpiration() int64 {
+	return int64(g.accessTokenExpiration.Seconds())
+}
+
 func (g *JWTTokenGenerator) GetRefreshTokenExpiration() int64 {
 	return int64(g.refreshTokenExpiration.Seconds())
 }
 
+func (g *JWTTokenGenerator) ValidateRefreshToken(tokenString string) (string, error) {
+	token, err := g.keyRing.Parse(tokenString, &RefreshTokenClaims{}, jwt.WithExpirationRequired())
+
+	if err != nil {
+		return "", errors.NewAuthenticationError("invalid_refresh_token", "Invalid or expired refresh token")
+	}
+
+	// Access tokens carry a jti too and would otherwise pass as refresh tokens
+	if claims, ok := token.Claims.(*RefreshTokenClaims); ok && token.Valid && claims.TokenUse == refreshTokenUse {
+		return claims.UserID, nil
+	}
+
+	return "", errors.NewAuthenticationError("invalid_refresh_token", "Invalid refresh token claims")
+}
+
 func (g *JWTTokenGenerator) ValidateAccessToken(tokenString string) (*AccessTokenClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &AccessTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.NewDomainError("invalid_signing_method", "Invalid signing method")
-		}
-		return []byte(g.secretKey), nil
-	})
+	token, err := g.keyRing.Parse(tokenString, &AccessTokenClaims{}, jwt.WithExpirationRequired())
 
 	if err != nil {
 		return nil, errors.NewDomainError("invalid_token", "Invalid or expired token")
 	}
 
-	if claims, ok := token.Claims.(*AccessTokenClaims); ok && token.Valid {
+	if claims, ok := token.Claims.(*AccessTokenClaims); ok && token.Valid && claims.TokenUse == accessTokenUse {
 		return claims, nil
 	}
 
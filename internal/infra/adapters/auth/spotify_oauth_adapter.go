@@ -3,7 +3,6 @@ package auth
 import (
 	"context"
 	"strings"
-	"time"
 
 	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
 	services "github.com/zandomed/sync-playlist-api/internal/infra/services/auth"
@@ -14,46 +13,74 @@ type SpotifyOAuthAdapter struct {
 	service *services.SpotifyOAuthService
 }
 
-func NewSpotifyOAuthAdapter(service *services.SpotifyOAuthService) providers.SpotifyOAuthProvider {
+func NewSpotifyOAuthAdapter(service *services.SpotifyOAuthService) providers.OAuthProvider {
 	return &SpotifyOAuthAdapter{
 		service: service,
 	}
 }
 
-func (a *SpotifyOAuthAdapter) GetAuthURL(state string) string {
-	return a.service.GetAuthURL(state)
+func (a *SpotifyOAuthAdapter) GetAuthURL(state, codeVerifier string) string {
+	return a.service.GetAuthURL(state, codeVerifier)
 }
 
-func (a *SpotifyOAuthAdapter) ExchangeCode(ctx context.Context, code string) (accessToken, refreshToken string, expiresAt time.Time, err error) {
-	token, err := a.service.ExchangeCode(ctx, code)
+func (a *SpotifyOAuthAdapter) ExchangeCode(ctx context.Context, code, codeVerifier string) (*providers.OAuthTokens, error) {
+	token, err := a.service.ExchangeCode(ctx, code, codeVerifier)
 	if err != nil {
-		return "", "", time.Time{}, err
+		return nil, err
 	}
 
-	expiry := token.Expiry
-	if expiry.IsZero() {
-		expiry = time.Now().Add(time.Hour)
+	return toOAuthTokens(token), nil
+}
+
+func (a *SpotifyOAuthAdapter) RefreshToken(ctx context.Context, refreshToken string) (*providers.OAuthTokens, error) {
+	token, err := a.service.RefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, toRefreshError(err)
 	}
 
-	return token.AccessToken, token.RefreshToken, expiry, nil
+	return toOAuthTokens(token), nil
 }
 
-func (a *SpotifyOAuthAdapter) GetUserInfo(ctx context.Context, accessToken string) (email, displayName string, err error) {
+func (a *SpotifyOAuthAdapter) GetIdentity(ctx context.Context, tokens *providers.OAuthTokens) (*providers.OAuthIdentity, error) {
 	token := &oauth2.Token{
-		AccessToken: accessToken,
+		AccessToken: tokens.AccessToken,
 	}
 
 	userInfo, err := a.service.GetUserInfo(ctx, token)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
 	// Spotify display name might be empty or contain full name
-	// Extract first and last name from display name
 	name := userInfo.DisplayName
 	if name == "" {
 		name = strings.Split(userInfo.Email, "@")[0]
 	}
+	firstName, lastName := splitDisplayName(name)
+
+	var avatarURL string
+	if len(userInfo.Images) > 0 {
+		avatarURL = userInfo.Images[0].URL
+	}
 
-	return userInfo.Email, name, nil
+	return &providers.OAuthIdentity{
+		SubjectID: userInfo.ID,
+		Email:     userInfo.Email,
+		// Spotify does not report whether the email address was confirmed
+		EmailVerified: false,
+		FirstName:     firstName,
+		LastName:      lastName,
+		AvatarURL:     avatarURL,
+	}, nil
+}
+
+func splitDisplayName(fullName string) (firstName, lastName string) {
+	parts := strings.Fields(fullName)
+	if len(parts) == 0 {
+		return "", ""
+	}
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], strings.Join(parts[1:], " ")
 }
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	services "github.com/zandomed/sync-playlist-api/internal/infra/services/auth"
+	"golang.org/x/oauth2"
+)
+
+type TidalOAuthAdapter struct {
+	service *services.TidalOAuthService
+}
+
+func NewTidalOAuthAdapter(service *services.TidalOAuthService) providers.OAuthProvider {
+	return &TidalOAuthAdapter{
+		service: service,
+	}
+}
+
+func (a *TidalOAuthAdapter) GetAuthURL(state, codeVerifier string) string {
+	return a.service.GetAuthURL(state, codeVerifier)
+}
+
+func (a *TidalOAuthAdapter) ExchangeCode(ctx context.Context, code, codeVerifier string) (*providers.OAuthTokens, error) {
+	token, err := a.service.ExchangeCode(ctx, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return toOAuthTokens(token), nil
+}
+
+func (a *TidalOAuthAdapter) RefreshToken(ctx context.Context, refreshToken string) (*providers.OAuthTokens, error) {
+	token, err := a.service.RefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, toRefreshError(err)
+	}
+
+	return toOAuthTokens(token), nil
+}
+
+func (a *TidalOAuthAdapter) GetIdentity(ctx context.Context, tokens *providers.OAuthTokens) (*providers.OAuthIdentity, error) {
+	token := &oauth2.Token{
+		AccessToken: tokens.AccessToken,
+	}
+
+	userInfo, err := a.service.GetUserInfo(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := userInfo.Data.Attributes
+	firstName, lastName := attributes.FirstName, attributes.LastName
+	if firstName == "" && lastName == "" {
+		firstName = attributes.Username
+	}
+
+	return &providers.OAuthIdentity{
+		SubjectID:     userInfo.Data.ID,
+		Email:         attributes.Email,
+		EmailVerified: attributes.EmailVerified,
+		FirstName:     firstName,
+		LastName:      lastName,
+	}, nil
+}
@@ -0,0 +1,17 @@
+package clock
+
+import (
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+)
+
+type SystemClock struct{}
+
+func NewSystemClock() providers.Clock {
+	return SystemClock{}
+}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,40 @@
+package mail
+
+import (
+	"context"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/pkg/logger"
+)
+
+// asyncSendTimeout bounds a delivery that no request is waiting for
+const asyncSendTimeout = time.Minute
+
+// AsyncMailer hands emails to another mailer in the background and logs the
+// failures. Endpoints that must answer the same way whether or not an account
+// exists use it, so neither delivery time nor delivery errors show through
+type AsyncMailer struct {
+	mailer providers.Mailer
+	logger *logger.Logger
+}
+
+func NewAsyncMailer(mailer providers.Mailer, logger *logger.Logger) providers.Mailer {
+	return &AsyncMailer{
+		mailer: mailer,
+		logger: logger,
+	}
+}
+
+func (m *AsyncMailer) Send(ctx context.Context, message providers.EmailMessage) error {
+	// The request context ends with the response
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), asyncSendTimeout)
+
+	go func() {
+		defer cancel()
+		if err := m.mailer.Send(ctx, message); err != nil {
+			m.logger.Sugar().Errorf("Failed to send %q email: %v", message.Subject, err)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,53 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/pkg/logger"
+)
+
+// OutboxMailer is meant for local development: instead of delivering emails it
+// writes them as .eml files to a directory, or logs them when no directory is set
+type OutboxMailer struct {
+	dir    string
+	from   string
+	logger *logger.Logger
+}
+
+func NewOutboxMailer(dir, from string, logger *logger.Logger) providers.Mailer {
+	return &OutboxMailer{
+		dir:    dir,
+		from:   from,
+		logger: logger,
+	}
+}
+
+func (m *OutboxMailer) Send(ctx context.Context, message providers.EmailMessage) error {
+	body, err := buildMessage(m.from, message)
+	if err != nil {
+		return err
+	}
+
+	if m.dir == "" {
+		m.logger.Sugar().Infof("Outbox email to %s:\n%s", message.To, body)
+		return nil
+	}
+
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s.eml", time.Now().Format("20060102T150405.000000000"))
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, body, 0o600); err != nil {
+		return err
+	}
+
+	m.logger.Sugar().Infof("Outbox email to %s written to %s", message.To, path)
+	return nil
+}
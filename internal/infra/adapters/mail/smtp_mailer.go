@@ -0,0 +1,58 @@
+package mail
+
+import (
+	"context"
+	"net"
+	"net/mail"
+	"net/smtp"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+)
+
+type SMTPMailer struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPMailer(host, port, username, password, from string) providers.Mailer {
+	return &SMTPMailer{
+		addr:     net.JoinHostPort(host, port),
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+// Send delivers the message through the configured relay. smtp.SendMail
+// upgrades the connection with STARTTLS whenever the server supports it
+func (m *SMTPMailer) Send(ctx context.Context, message providers.EmailMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	sender, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return err
+	}
+
+	recipient, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return err
+	}
+
+	body, err := buildMessage(m.from, message)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	return smtp.SendMail(m.addr, auth, sender.Address, []string{recipient.Address}, body)
+}
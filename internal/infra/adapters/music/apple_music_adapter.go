@@ -0,0 +1,314 @@
+package music
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+	services "github.com/zandomed/sync-playlist-api/internal/infra/services/music"
+)
+
+const (
+	applePageLimit          = 100
+	appleDefaultSearchLimit = 5
+	appleMaxSearchLimit     = 25
+	// appleStorefrontTTL is how long a user's storefront is reused; it only
+	// changes when they move their account to another country
+	appleStorefrontTTL = time.Hour
+)
+
+// MusicUserTokenSource hands out the Music-User-Token stored for the user and
+// forgets it through RequireReauth once Apple rejects it
+type MusicUserTokenSource interface {
+	GetMusicUserToken(ctx context.Context, userID valueobjects.UserID) (string, error)
+	RequireReauth(ctx context.Context, userID valueobjects.UserID, provider entities.AccountProvider) error
+}
+
+// AppleMusicAdapter works on the user's Apple Music library. Only library
+// playlists are listed and edited, and Apple Music offers no way for apps to
+// remove or reorder their tracks
+type AppleMusicAdapter struct {
+	client      *services.AppleMusicAPIClient
+	tokens      MusicUserTokenSource
+	storefronts *userCache[string]
+}
+
+func NewAppleMusicAdapter(client *services.AppleMusicAPIClient, tokens MusicUserTokenSource, clock providers.Clock) providers.MusicService {
+	return &AppleMusicAdapter{
+		client:      client,
+		tokens:      tokens,
+		storefronts: newUserCache[string](clock, appleStorefrontTTL),
+	}
+}
+
+func (a *AppleMusicAdapter) ListPlaylists(ctx context.Context, userID valueobjects.UserID, page providers.PageRequest) (*providers.Page[valueobjects.Playlist], error) {
+	offset, err := parseOffsetCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := a.tokens.GetMusicUserToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := a.client.GetLibraryPlaylists(ctx, token, offset, pageLimit(page.Limit, applePageLimit, applePageLimit))
+	if err != nil {
+		return nil, a.toError(ctx, userID, err, "playlist", "Playlist not found")
+	}
+
+	playlists := make([]valueobjects.Playlist, 0, len(result.Data))
+	for _, item := range result.Data {
+		playlist, err := toApplePlaylist(item)
+		if err != nil {
+			continue
+		}
+		playlists = append(playlists, playlist)
+	}
+
+	return &providers.Page[valueobjects.Playlist]{
+		Items: playlists,
+		Next:  nextOffsetCursor(result.Next, offset, len(result.Data)),
+		Total: appleTotal(result.Meta.Total, result.Next, offset, len(result.Data)),
+	}, nil
+}
+
+func (a *AppleMusicAdapter) GetPlaylistTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, page providers.PageRequest) (*providers.Page[valueobjects.Track], error) {
+	offset, err := parseOffsetCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := a.tokens.GetMusicUserToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := a.client.GetLibraryPlaylistTracks(ctx, token, playlistID, offset, pageLimit(page.Limit, applePageLimit, applePageLimit))
+	if err != nil {
+		// Apple answers 404 for the tracks of an empty playlist
+		var apiErr *services.APIError
+		if offset == 0 && stderrors.As(err, &apiErr) && apiErr.StatusCode == 404 {
+			return &providers.Page[valueobjects.Track]{Items: []valueobjects.Track{}, Total: 0}, nil
+		}
+		return nil, a.toError(ctx, userID, err, "playlist", "Playlist not found")
+	}
+
+	tracks := make([]valueobjects.Track, 0, len(result.Data))
+	for _, song := range result.Data {
+		track, err := toAppleTrack(song)
+		if err != nil {
+			continue
+		}
+		tracks = append(tracks, track)
+	}
+
+	return &providers.Page[valueobjects.Track]{
+		Items: tracks,
+		Next:  nextOffsetCursor(result.Next, offset, len(result.Data)),
+		Total: appleTotal(result.Meta.Total, result.Next, offset, len(result.Data)),
+	}, nil
+}
+
+func (a *AppleMusicAdapter) SearchTracks(ctx context.Context, userID valueobjects.UserID, query providers.TrackQuery) ([]valueobjects.Track, error) {
+	isrc, isrcErr := valueobjects.NewISRC(query.ISRC)
+	term := appleSearchTerm(query)
+	if isrcErr != nil && term == "" {
+		return []valueobjects.Track{}, nil
+	}
+
+	token, err := a.tokens.GetMusicUserToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	storefront, err := a.storefront(ctx, userID, token)
+	if err != nil {
+		return nil, a.toError(ctx, userID, err, "storefront", "Apple Music storefront not found")
+	}
+
+	limit := pageLimit(query.Limit, appleDefaultSearchLimit, appleMaxSearchLimit)
+
+	var songs []services.AppleSong
+	if isrcErr == nil {
+		songs, err = a.client.GetCatalogSongsByISRC(ctx, token, storefront, isrc.Value())
+	} else {
+		songs, err = a.client.SearchCatalogSongs(ctx, token, storefront, term, limit)
+	}
+	if err != nil {
+		return nil, a.toError(ctx, userID, err, "track", "Track not found")
+	}
+
+	tracks := make([]valueobjects.Track, 0, len(songs))
+	for _, song := range songs {
+		track, err := toAppleTrack(song)
+		if err != nil {
+			continue
+		}
+		tracks = append(tracks, track)
+	}
+
+	if len(tracks) > limit {
+		tracks = tracks[:limit]
+	}
+	return tracks, nil
+}
+
+// CreatePlaylist creates a library playlist; Public is ignored because Apple
+// Music does not let apps share playlists
+func (a *AppleMusicAdapter) CreatePlaylist(ctx context.Context, userID valueobjects.UserID, playlist providers.NewPlaylist) (*valueobjects.Playlist, error) {
+	token, err := a.tokens.GetMusicUserToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := a.client.CreateLibraryPlaylist(ctx, token, playlist.Name, playlist.Description)
+	if err != nil {
+		return nil, a.toError(ctx, userID, err, "playlist", "Playlist not found")
+	}
+
+	result, err := toApplePlaylist(*created)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (a *AppleMusicAdapter) AddTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, trackIDs []string) error {
+	token, err := a.tokens.GetMusicUserToken(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, batch := range batches(trackIDs, services.AppleMaxBatchSize) {
+		references := make([]services.AppleTrackReference, 0, len(batch))
+		for _, id := range batch {
+			references = append(references, appleTrackReference(id))
+		}
+
+		if err := a.client.AddLibraryPlaylistTracks(ctx, token, playlistID, references); err != nil {
+			return a.toError(ctx, userID, err, "playlist", "Playlist not found")
+		}
+	}
+	return nil
+}
+
+func (a *AppleMusicAdapter) RemoveTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, trackIDs []string) error {
+	return providers.ErrOperationNotSupported
+}
+
+func (a *AppleMusicAdapter) ReorderTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, move providers.TrackMove) error {
+	return providers.ErrOperationNotSupported
+}
+
+// storefront returns the catalog country of the user, which catalog requests
+// are scoped to
+func (a *AppleMusicAdapter) storefront(ctx context.Context, userID valueobjects.UserID, musicUserToken string) (string, error) {
+	key := userID.String()
+	if cached, ok := a.storefronts.get(key); ok {
+		return cached, nil
+	}
+
+	id, err := a.client.Storefront(ctx, musicUserToken)
+	if err != nil {
+		return "", err
+	}
+
+	a.storefronts.set(key, id)
+	return id, nil
+}
+
+// toError also forgets a Music-User-Token Apple rejects with 403, so the user
+// is asked to authorize Apple Music again. A 401 is about the developer
+// token, which is shared by every user and must not discard their tokens
+func (a *AppleMusicAdapter) toError(ctx context.Context, userID valueobjects.UserID, err error, resource, message string) error {
+	var apiErr *services.APIError
+	if stderrors.As(err, &apiErr) && apiErr.StatusCode == 403 {
+		return a.tokens.RequireReauth(ctx, userID, entities.AppleMusicProvider)
+	}
+	return toMusicError(err, resource, message)
+}
+
+// appleTotal returns the reported total, or -1 when Apple left it out and it
+// cannot be told from the page
+func appleTotal(total int, next string, offset, count int) int {
+	if total > 0 {
+		return total
+	}
+	if next == "" {
+		return offset + count
+	}
+	return -1
+}
+
+// appleSearchTerm joins the fields, as Apple Music has no field filters
+func appleSearchTerm(query providers.TrackQuery) string {
+	var parts []string
+	for _, field := range []string{query.Title, query.Artist, query.Album} {
+		if field = strings.TrimSpace(field); field != "" {
+			parts = append(parts, field)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// appleTrackReference tells library song IDs, which start with "i.", from
+// catalog ones
+func appleTrackReference(id string) services.AppleTrackReference {
+	if strings.HasPrefix(id, "i.") {
+		return services.AppleTrackReference{ID: id, Type: "library-songs"}
+	}
+	return services.AppleTrackReference{ID: id, Type: "songs"}
+}
+
+func toApplePlaylist(playlist services.ApplePlaylist) (valueobjects.Playlist, error) {
+	var description string
+	if playlist.Attributes.Description != nil {
+		description = playlist.Attributes.Description.Standard
+	}
+
+	// Library listings carry neither the owner nor the track count
+	return valueobjects.NewPlaylist(
+		playlist.ID,
+		playlist.Attributes.Name,
+		description,
+		"",
+		0,
+		playlist.Attributes.IsPublic,
+	)
+}
+
+// toAppleTrack converts a catalog or library song. Library songs are reported
+// with their catalog ID and ISRC when Apple links them to the catalog, so they
+// can be matched and added to other playlists
+func toAppleTrack(song services.AppleSong) (valueobjects.Track, error) {
+	id := song.ID
+	isrc := song.Attributes.ISRC
+
+	if song.Relationships.Catalog != nil && len(song.Relationships.Catalog.Data) > 0 {
+		catalog := song.Relationships.Catalog.Data[0]
+		id = catalog.ID
+		isrc = catalog.Attributes.ISRC
+	} else if song.Attributes.PlayParams != nil && song.Attributes.PlayParams.CatalogID != "" {
+		id = song.Attributes.PlayParams.CatalogID
+	}
+
+	var artists []valueobjects.Artist
+	if artist, err := valueobjects.NewArtist("", song.Attributes.ArtistName); err == nil {
+		artists = append(artists, artist)
+	}
+
+	return valueobjects.NewTrack(
+		id,
+		song.Attributes.Name,
+		artists,
+		valueobjects.NewAlbum("", song.Attributes.AlbumName, valueobjects.UPC{}),
+		time.Duration(song.Attributes.DurationInMillis)*time.Millisecond,
+		song.Attributes.ContentRating == "explicit",
+		optionalISRC(isrc),
+	)
+}
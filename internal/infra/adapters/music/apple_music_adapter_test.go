@@ -0,0 +1,133 @@
+package music
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+	services "github.com/zandomed/sync-playlist-api/internal/infra/services/music"
+)
+
+// musicUserTokens records the accounts whose Music-User-Token was rejected
+type musicUserTokens struct {
+	forgotten []entities.AccountProvider
+}
+
+func (s *musicUserTokens) GetMusicUserToken(ctx context.Context, userID valueobjects.UserID) (string, error) {
+	return "music-user-token", nil
+}
+
+func (s *musicUserTokens) RequireReauth(ctx context.Context, userID valueobjects.UserID, provider entities.AccountProvider) error {
+	s.forgotten = append(s.forgotten, provider)
+	return errors.NewAuthenticationError("apple_music_authorization_required", "Apple Music must be authorized")
+}
+
+func newTestDeveloperTokens(t *testing.T) *services.AppleDeveloperTokenGenerator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+	}
+
+	developerTokens, err := services.NewAppleDeveloperTokenGenerator("TEAM", "KEY", string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})))
+	if err != nil {
+		t.Fatalf("NewAppleDeveloperTokenGenerator: %v", err)
+	}
+	return developerTokens
+}
+
+func TestAppleRejectedMusicUserTokenRequiresReauth(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		wantForgotten bool
+	}{
+		{"music user token rejected", http.StatusForbidden, true},
+		{"developer token rejected", http.StatusUnauthorized, false},
+		{"playlists", http.StatusOK, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"data":[]}`))
+			}))
+			defer server.Close()
+
+			tokens := &musicUserTokens{}
+			client := services.NewAppleMusicAPIClient(server.Client(), server.URL, newTestDeveloperTokens(t))
+			adapter := NewAppleMusicAdapter(client, tokens, &fakeClock{now: time.Now()})
+
+			_, err := adapter.ListPlaylists(context.Background(), valueobjects.NewUserID(), providers.PageRequest{})
+
+			if !tt.wantForgotten {
+				if len(tokens.forgotten) != 0 {
+					t.Errorf("forgot the token of %v", tokens.forgotten)
+				}
+				return
+			}
+
+			if len(tokens.forgotten) != 1 || tokens.forgotten[0] != entities.AppleMusicProvider {
+				t.Fatalf("forgotten = %v, want [apple_music]", tokens.forgotten)
+			}
+			authErr, ok := err.(*errors.AuthenticationError)
+			if !ok || authErr.Code() != "apple_music_authorization_required" {
+				t.Errorf("ListPlaylists error = %v, want apple_music_authorization_required", err)
+			}
+		})
+	}
+}
+
+func TestAppleStorefrontIsCachedPerUser(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/me/storefront" {
+			requests++
+			_, _ = w.Write([]byte(`{"data":[{"id":"us"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	client := services.NewAppleMusicAPIClient(server.Client(), server.URL, newTestDeveloperTokens(t))
+	adapter := NewAppleMusicAdapter(client, &musicUserTokens{}, clock)
+	userID := valueobjects.NewUserID()
+
+	search := func() {
+		t.Helper()
+		if _, err := adapter.SearchTracks(context.Background(), userID, providers.TrackQuery{Title: "song"}); err != nil {
+			t.Fatalf("SearchTracks: %v", err)
+		}
+	}
+
+	search()
+	search()
+	if requests != 1 {
+		t.Fatalf("storefront requests = %d, want 1 within the TTL", requests)
+	}
+
+	clock.now = clock.now.Add(appleStorefrontTTL)
+	search()
+	if requests != 2 {
+		t.Errorf("storefront requests = %d, want 2 after the TTL", requests)
+	}
+}
@@ -0,0 +1,298 @@
+package music
+
+import (
+	"context"
+	stderrors "errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+	services "github.com/zandomed/sync-playlist-api/internal/infra/services/music"
+)
+
+const (
+	deezerPageLimit          = 100
+	deezerDefaultSearchLimit = 5
+	deezerMaxSearchLimit     = 25
+)
+
+// ReauthCredentialSource is a CredentialSource that can also flag an account
+// whose access token the service rejected, so the user links it again
+type ReauthCredentialSource interface {
+	CredentialSource
+	RequireReauth(ctx context.Context, userID valueobjects.UserID, provider entities.AccountProvider) error
+}
+
+// DeezerMusicAdapter works on the user's Deezer playlists. Playlist entries
+// come without ISRC, which Deezer only returns for tracks fetched on their own
+type DeezerMusicAdapter struct {
+	client      *services.DeezerAPIClient
+	credentials ReauthCredentialSource
+}
+
+func NewDeezerMusicAdapter(client *services.DeezerAPIClient, credentials ReauthCredentialSource) providers.MusicService {
+	return &DeezerMusicAdapter{
+		client:      client,
+		credentials: credentials,
+	}
+}
+
+func (a *DeezerMusicAdapter) ListPlaylists(ctx context.Context, userID valueobjects.UserID, page providers.PageRequest) (*providers.Page[valueobjects.Playlist], error) {
+	offset, err := parseOffsetCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := a.client.GetMyPlaylists(ctx, token, offset, pageLimit(page.Limit, deezerPageLimit, deezerPageLimit))
+	if err != nil {
+		return nil, a.toError(ctx, userID, err, "playlist", "Playlist not found")
+	}
+
+	playlists := make([]valueobjects.Playlist, 0, len(result.Data))
+	for _, item := range result.Data {
+		playlist, err := toDeezerPlaylist(item)
+		if err != nil {
+			continue
+		}
+		playlists = append(playlists, playlist)
+	}
+
+	return &providers.Page[valueobjects.Playlist]{
+		Items: playlists,
+		Next:  nextOffsetCursor(result.Next, offset, len(result.Data)),
+		Total: result.Total,
+	}, nil
+}
+
+func (a *DeezerMusicAdapter) GetPlaylistTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, page providers.PageRequest) (*providers.Page[valueobjects.Track], error) {
+	offset, err := parseOffsetCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := a.client.GetPlaylistTracks(ctx, token, playlistID, offset, pageLimit(page.Limit, deezerPageLimit, deezerPageLimit))
+	if err != nil {
+		return nil, a.toError(ctx, userID, err, "playlist", "Playlist not found")
+	}
+
+	tracks := make([]valueobjects.Track, 0, len(result.Data))
+	for _, item := range result.Data {
+		if item.Type != "" && item.Type != "track" {
+			continue
+		}
+
+		track, err := toDeezerTrack(item)
+		if err != nil {
+			continue
+		}
+		tracks = append(tracks, track)
+	}
+
+	return &providers.Page[valueobjects.Track]{
+		Items: tracks,
+		Next:  nextOffsetCursor(result.Next, offset, len(result.Data)),
+		Total: result.Total,
+	}, nil
+}
+
+// SearchTracks looks an ISRC up directly, which gives at most one track;
+// otherwise it searches with the title, artist and album filters
+func (a *DeezerMusicAdapter) SearchTracks(ctx context.Context, userID valueobjects.UserID, query providers.TrackQuery) ([]valueobjects.Track, error) {
+	isrc, isrcErr := valueobjects.NewISRC(query.ISRC)
+	q := deezerSearchQuery(query)
+	if isrcErr != nil && q == "" {
+		return []valueobjects.Track{}, nil
+	}
+
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if isrcErr == nil {
+		result, err := a.client.GetTrackByISRC(ctx, token, isrc.Value())
+		if err != nil {
+			err = a.toError(ctx, userID, err, "track", "Track not found")
+			if _, ok := err.(*errors.NotFoundError); ok {
+				return []valueobjects.Track{}, nil
+			}
+			return nil, err
+		}
+
+		track, err := toDeezerTrack(*result)
+		if err != nil {
+			return []valueobjects.Track{}, nil
+		}
+		return []valueobjects.Track{track}, nil
+	}
+
+	results, err := a.client.SearchTracks(ctx, token, q, pageLimit(query.Limit, deezerDefaultSearchLimit, deezerMaxSearchLimit))
+	if err != nil {
+		return nil, a.toError(ctx, userID, err, "track", "Track not found")
+	}
+
+	tracks := make([]valueobjects.Track, 0, len(results))
+	for _, result := range results {
+		track, err := toDeezerTrack(result)
+		if err != nil {
+			continue
+		}
+		tracks = append(tracks, track)
+	}
+	return tracks, nil
+}
+
+// CreatePlaylist creates the playlist and then sets its description and
+// visibility, as Deezer only takes the title on creation
+func (a *DeezerMusicAdapter) CreatePlaylist(ctx context.Context, userID valueobjects.UserID, playlist providers.NewPlaylist) (*valueobjects.Playlist, error) {
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	playlistID, err := a.client.CreatePlaylist(ctx, token, playlist.Name)
+	if err != nil {
+		return nil, a.toError(ctx, userID, err, "playlist", "Playlist not found")
+	}
+
+	if err := a.client.UpdatePlaylist(ctx, token, playlistID, playlist.Description, playlist.Public); err != nil {
+		return nil, a.toError(ctx, userID, err, "playlist", "Playlist not found")
+	}
+
+	created, err := a.client.GetPlaylist(ctx, token, playlistID)
+	if err != nil {
+		return nil, a.toError(ctx, userID, err, "playlist", "Playlist not found")
+	}
+
+	result, err := toDeezerPlaylist(*created)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (a *DeezerMusicAdapter) AddTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, trackIDs []string) error {
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, batch := range batches(trackIDs, services.DeezerMaxBatchSize) {
+		if err := a.client.AddTracks(ctx, token, playlistID, batch); err != nil {
+			return a.toError(ctx, userID, err, "playlist", "Playlist not found")
+		}
+	}
+	return nil
+}
+
+func (a *DeezerMusicAdapter) RemoveTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, trackIDs []string) error {
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, batch := range batches(trackIDs, services.DeezerMaxBatchSize) {
+		if err := a.client.RemoveTracks(ctx, token, playlistID, batch); err != nil {
+			return a.toError(ctx, userID, err, "playlist", "Playlist not found")
+		}
+	}
+	return nil
+}
+
+// ReorderTracks is not offered: Deezer only takes the complete new order of
+// the playlist, which would undo edits made while it was being computed
+func (a *DeezerMusicAdapter) ReorderTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, move providers.TrackMove) error {
+	return providers.ErrOperationNotSupported
+}
+
+func (a *DeezerMusicAdapter) accessToken(ctx context.Context, userID valueobjects.UserID) (string, error) {
+	return a.credentials.GetAccessToken(ctx, userID, entities.DeezerProvider)
+}
+
+// toError also flags the account when Deezer rejects its access token: there
+// are no refresh tokens to recover with, so the user has to link it again
+func (a *DeezerMusicAdapter) toError(ctx context.Context, userID valueobjects.UserID, err error, resource, message string) error {
+	var apiErr *services.APIError
+	if stderrors.As(err, &apiErr) && apiErr.StatusCode == 401 {
+		return a.credentials.RequireReauth(ctx, userID, entities.DeezerProvider)
+	}
+	return toMusicError(err, resource, message)
+}
+
+// deezerSearchQuery builds the q parameter with Deezer's advanced search
+// filters, whose values are quoted
+func deezerSearchQuery(query providers.TrackQuery) string {
+	var filters []string
+	for _, filter := range []struct{ name, value string }{
+		{"track", query.Title},
+		{"artist", query.Artist},
+		{"album", query.Album},
+	} {
+		value := strings.TrimSpace(strings.ReplaceAll(filter.value, `"`, ""))
+		if value != "" {
+			filters = append(filters, filter.name+`:"`+value+`"`)
+		}
+	}
+	return strings.Join(filters, " ")
+}
+
+func toDeezerPlaylist(playlist services.DeezerPlaylist) (valueobjects.Playlist, error) {
+	return valueobjects.NewPlaylist(
+		deezerID(playlist.ID),
+		playlist.Title,
+		playlist.Description,
+		deezerID(playlist.Creator.ID),
+		playlist.NbTracks,
+		playlist.Public,
+	)
+}
+
+// toDeezerTrack takes the artists from the contributors when the track has
+// them, and otherwise the main artist
+func toDeezerTrack(track services.DeezerTrack) (valueobjects.Track, error) {
+	contributors := track.Contributors
+	if len(contributors) == 0 {
+		contributors = []services.DeezerArtist{track.Artist}
+	}
+
+	artists := make([]valueobjects.Artist, 0, len(contributors))
+	for _, a := range contributors {
+		artist, err := valueobjects.NewArtist(deezerID(a.ID), a.Name)
+		if err != nil {
+			continue
+		}
+		artists = append(artists, artist)
+	}
+
+	return valueobjects.NewTrack(
+		deezerID(track.ID),
+		track.Title,
+		artists,
+		valueobjects.NewAlbum(deezerID(track.Album.ID), track.Album.Title, optionalUPC(track.Album.UPC)),
+		time.Duration(track.Duration)*time.Second,
+		track.ExplicitLyrics,
+		optionalISRC(track.ISRC),
+	)
+}
+
+// deezerID formats Deezer's numeric IDs, leaving unset ones empty
+func deezerID(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,66 @@
+package music
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+	services "github.com/zandomed/sync-playlist-api/internal/infra/services/music"
+)
+
+// reauthRecorder records the accounts flagged for relinking
+type reauthRecorder struct {
+	staticCredentials
+	flagged []entities.AccountProvider
+}
+
+func (c *reauthRecorder) RequireReauth(ctx context.Context, userID valueobjects.UserID, provider entities.AccountProvider) error {
+	c.flagged = append(c.flagged, provider)
+	return errors.NewAuthenticationError("provider_reauth_required", "The provider account must be linked again")
+}
+
+func TestDeezerRejectedTokenRequiresReauth(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantFlagged bool
+	}{
+		{"invalid token", `{"error":{"type":"OAuthException","message":"Invalid OAuth access token.","code":300}}`, true},
+		{"permission denied", `{"error":{"type":"OAuthException","message":"Permission denied","code":200}}`, false},
+		{"playlists", `{"data":[],"total":0}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			credentials := &reauthRecorder{staticCredentials: staticCredentials{token: "token"}}
+			adapter := NewDeezerMusicAdapter(services.NewDeezerAPIClient(server.Client(), server.URL), credentials)
+
+			_, err := adapter.ListPlaylists(context.Background(), valueobjects.NewUserID(), providers.PageRequest{})
+
+			if !tt.wantFlagged {
+				if len(credentials.flagged) != 0 {
+					t.Errorf("flagged %v for relinking", credentials.flagged)
+				}
+				return
+			}
+
+			if len(credentials.flagged) != 1 || credentials.flagged[0] != entities.DeezerProvider {
+				t.Fatalf("flagged = %v, want [deezer]", credentials.flagged)
+			}
+			authErr, ok := err.(*errors.AuthenticationError)
+			if !ok || authErr.Code() != "provider_reauth_required" {
+				t.Errorf("ListPlaylists error = %v, want provider_reauth_required", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package music
+
+import (
+	"context"
+	stderrors "errors"
+	"strconv"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+	services "github.com/zandomed/sync-playlist-api/internal/infra/services/music"
+)
+
+// CredentialSource hands out a valid access token for the user's linked
+// account, refreshing it when needed
+type CredentialSource interface {
+	GetAccessToken(ctx context.Context, userID valueobjects.UserID, provider entities.AccountProvider) (string, error)
+}
+
+// parseOffsetCursor reads the cursor of services paginated by offset
+func parseOffsetCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	offset, err := strconv.Atoi(cursor)
+	if err != nil || offset < 0 {
+		return 0, errors.NewValidationError("cursor", "invalid_cursor", "Invalid page cursor")
+	}
+	return offset, nil
+}
+
+// nextOffsetCursor returns the cursor of the page after the one at offset.
+// count includes skipped entries, so the next page starts right after the
+// last item the service returned
+func nextOffsetCursor(next string, offset, count int) string {
+	if next == "" || count == 0 {
+		return ""
+	}
+	return strconv.Itoa(offset + count)
+}
+
+// pageLimit applies the service default and maximum to a requested limit
+func pageLimit(requested, defaultLimit, maxLimit int) int {
+	if requested <= 0 {
+		return defaultLimit
+	}
+	return min(requested, maxLimit)
+}
+
+// batches splits ids into consecutive chunks of at most size elements
+func batches(ids []string, size int) [][]string {
+	var chunks [][]string
+	for start := 0; start < len(ids); start += size {
+		chunks = append(chunks, ids[start:min(start+size, len(ids))])
+	}
+	return chunks
+}
+
+// toMusicError maps API answers the caller can act on to domain errors
+func toMusicError(err error, resource, message string) error {
+	var apiErr *services.APIError
+	if !stderrors.As(err, &apiErr) {
+		return err
+	}
+
+	switch apiErr.StatusCode {
+	case 404:
+		return errors.NewNotFoundError(resource, message)
+	case 429:
+		return errors.NewDomainError("music_service_rate_limited", "The music service is rate limiting requests, try again later")
+	}
+	return err
+}
+
+// optionalISRC drops codes the service returns malformed; a missing ISRC
+// only makes the track harder to match
+func optionalISRC(code string) valueobjects.ISRC {
+	isrc, err := valueobjects.NewISRC(code)
+	if err != nil {
+		return valueobjects.ISRC{}
+	}
+	return isrc
+}
+
+func optionalUPC(code string) valueobjects.UPC {
+	upc, err := valueobjects.NewUPC(code)
+	if err != nil {
+		return valueobjects.UPC{}
+	}
+	return upc
+}
@@ -0,0 +1,257 @@
+package music
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+	services "github.com/zandomed/sync-playlist-api/internal/infra/services/music"
+)
+
+const (
+	spotifyPageLimit          = 50
+	spotifyDefaultSearchLimit = 5
+	spotifyMaxSearchLimit     = 10
+)
+
+type SpotifyMusicAdapter struct {
+	client      *services.SpotifyAPIClient
+	credentials CredentialSource
+}
+
+func NewSpotifyMusicAdapter(client *services.SpotifyAPIClient, credentials CredentialSource) providers.MusicService {
+	return &SpotifyMusicAdapter{
+		client:      client,
+		credentials: credentials,
+	}
+}
+
+func (a *SpotifyMusicAdapter) ListPlaylists(ctx context.Context, userID valueobjects.UserID, page providers.PageRequest) (*providers.Page[valueobjects.Playlist], error) {
+	offset, err := parseOffsetCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := a.client.GetMyPlaylists(ctx, token, offset, pageLimit(page.Limit, spotifyPageLimit, spotifyPageLimit))
+	if err != nil {
+		return nil, toMusicError(err, "playlist", "Playlist not found")
+	}
+
+	playlists := make([]valueobjects.Playlist, 0, len(result.Items))
+	for _, item := range result.Items {
+		playlist, err := toSpotifyPlaylist(item)
+		if err != nil {
+			continue
+		}
+		playlists = append(playlists, playlist)
+	}
+
+	return &providers.Page[valueobjects.Playlist]{
+		Items: playlists,
+		Next:  nextOffsetCursor(result.Next, offset, len(result.Items)),
+		Total: result.Total,
+	}, nil
+}
+
+func (a *SpotifyMusicAdapter) GetPlaylistTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, page providers.PageRequest) (*providers.Page[valueobjects.Track], error) {
+	offset, err := parseOffsetCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := a.client.GetPlaylistItems(ctx, token, playlistID, offset, pageLimit(page.Limit, spotifyPageLimit, spotifyPageLimit))
+	if err != nil {
+		return nil, toMusicError(err, "playlist", "Playlist not found")
+	}
+
+	tracks := make([]valueobjects.Track, 0, len(result.Items))
+	for _, item := range result.Items {
+		entry := item.Entry()
+		if entry == nil || entry.Type != "track" || entry.IsLocal {
+			continue
+		}
+
+		track, err := toSpotifyTrack(*entry)
+		if err != nil {
+			continue
+		}
+		tracks = append(tracks, track)
+	}
+
+	return &providers.Page[valueobjects.Track]{
+		Items: tracks,
+		Next:  nextOffsetCursor(result.Next, offset, len(result.Items)),
+		Total: result.Total,
+	}, nil
+}
+
+func (a *SpotifyMusicAdapter) SearchTracks(ctx context.Context, userID valueobjects.UserID, query providers.TrackQuery) ([]valueobjects.Track, error) {
+	q := spotifySearchQuery(query)
+	if q == "" {
+		return []valueobjects.Track{}, nil
+	}
+
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := a.client.SearchTracks(ctx, token, q, pageLimit(query.Limit, spotifyDefaultSearchLimit, spotifyMaxSearchLimit))
+	if err != nil {
+		return nil, toMusicError(err, "track", "Track not found")
+	}
+
+	tracks := make([]valueobjects.Track, 0, len(results))
+	for _, result := range results {
+		track, err := toSpotifyTrack(result)
+		if err != nil {
+			continue
+		}
+		tracks = append(tracks, track)
+	}
+	return tracks, nil
+}
+
+func (a *SpotifyMusicAdapter) CreatePlaylist(ctx context.Context, userID valueobjects.UserID, playlist providers.NewPlaylist) (*valueobjects.Playlist, error) {
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := a.client.CreatePlaylist(ctx, token, playlist.Name, playlist.Description, playlist.Public)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := toSpotifyPlaylist(*created)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (a *SpotifyMusicAdapter) AddTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, trackIDs []string) error {
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, batch := range batches(spotifyTrackURIs(trackIDs), services.SpotifyMaxBatchSize) {
+		if err := a.client.AddItems(ctx, token, playlistID, batch); err != nil {
+			return toMusicError(err, "playlist", "Playlist not found")
+		}
+	}
+	return nil
+}
+
+func (a *SpotifyMusicAdapter) RemoveTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, trackIDs []string) error {
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, batch := range batches(spotifyTrackURIs(trackIDs), services.SpotifyMaxBatchSize) {
+		if err := a.client.RemoveItems(ctx, token, playlistID, batch); err != nil {
+			return toMusicError(err, "playlist", "Playlist not found")
+		}
+	}
+	return nil
+}
+
+func (a *SpotifyMusicAdapter) ReorderTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, move providers.TrackMove) error {
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := a.client.ReorderItems(ctx, token, playlistID, move.From, move.Length, move.InsertBefore); err != nil {
+		return toMusicError(err, "playlist", "Playlist not found")
+	}
+	return nil
+}
+
+func (a *SpotifyMusicAdapter) accessToken(ctx context.Context, userID valueobjects.UserID) (string, error) {
+	return a.credentials.GetAccessToken(ctx, userID, entities.SpotifyProvider)
+}
+
+// spotifySearchQuery builds the q parameter. An ISRC lookup is exact, so the
+// other fields are only used without one
+func spotifySearchQuery(query providers.TrackQuery) string {
+	if isrc, err := valueobjects.NewISRC(query.ISRC); err == nil {
+		return "isrc:" + isrc.Value()
+	}
+
+	var filters []string
+	if title := strings.TrimSpace(query.Title); title != "" {
+		filters = append(filters, "track:"+title)
+	}
+	if artist := strings.TrimSpace(query.Artist); artist != "" {
+		filters = append(filters, "artist:"+artist)
+	}
+	if album := strings.TrimSpace(query.Album); album != "" {
+		filters = append(filters, "album:"+album)
+	}
+	return strings.Join(filters, " ")
+}
+
+func spotifyTrackURIs(trackIDs []string) []string {
+	uris := make([]string, 0, len(trackIDs))
+	for _, id := range trackIDs {
+		if strings.HasPrefix(id, "spotify:") {
+			uris = append(uris, id)
+			continue
+		}
+		uris = append(uris, "spotify:track:"+id)
+	}
+	return uris
+}
+
+func toSpotifyPlaylist(playlist services.SpotifyPlaylist) (valueobjects.Playlist, error) {
+	return valueobjects.NewPlaylist(
+		playlist.ID,
+		playlist.Name,
+		playlist.Description,
+		playlist.Owner.ID,
+		playlist.TrackCount(),
+		playlist.Public,
+	)
+}
+
+func toSpotifyTrack(track services.SpotifyTrack) (valueobjects.Track, error) {
+	artists := make([]valueobjects.Artist, 0, len(track.Artists))
+	for _, a := range track.Artists {
+		artist, err := valueobjects.NewArtist(a.ID, a.Name)
+		if err != nil {
+			continue
+		}
+		artists = append(artists, artist)
+	}
+
+	upc := track.Album.ExternalIDs.UPC
+	if upc == "" {
+		upc = track.Album.ExternalIDs.EAN
+	}
+
+	return valueobjects.NewTrack(
+		track.ID,
+		track.Name,
+		artists,
+		valueobjects.NewAlbum(track.Album.ID, track.Album.Name, optionalUPC(upc)),
+		time.Duration(track.DurationMS)*time.Millisecond,
+		track.Explicit,
+		optionalISRC(track.ExternalIDs.ISRC),
+	)
+}
@@ -0,0 +1,108 @@
+package music
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+	services "github.com/zandomed/sync-playlist-api/internal/infra/services/music"
+)
+
+type staticCredentials struct {
+	token string
+}
+
+func (c staticCredentials) GetAccessToken(ctx context.Context, userID valueobjects.UserID, provider entities.AccountProvider) (string, error) {
+	return c.token, nil
+}
+
+func trackIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("track%d", i)
+	}
+	return ids
+}
+
+func TestSpotifyAddTracksBatches(t *testing.T) {
+	tests := []struct {
+		name        string
+		tracks      int
+		rateLimited bool
+		wantBatches []int
+	}{
+		{"nothing to add", 0, false, nil},
+		{"single track", 1, false, []int{1}},
+		{"exactly one batch", 100, false, []int{100}},
+		{"one over a batch", 101, false, []int{100, 1}},
+		{"several batches", 250, false, []int{100, 100, 50}},
+		{"rate limited batch is retried", 150, true, []int{100, 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var batches [][]string
+			limited := tt.rateLimited
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/playlists/p1/items" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				if got := r.Header.Get("Authorization"); got != "Bearer token" {
+					t.Errorf("Authorization = %q", got)
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				if limited {
+					limited = false
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+
+				var body struct {
+					URIs []string `json:"uris"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("decode body: %v", err)
+				}
+				batches = append(batches, body.URIs)
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"snapshot_id":"s"}`))
+			}))
+			defer server.Close()
+
+			adapter := NewSpotifyMusicAdapter(services.NewSpotifyAPIClient(server.Client(), server.URL), staticCredentials{token: "token"})
+
+			ids := trackIDs(tt.tracks)
+			if err := adapter.AddTracks(context.Background(), valueobjects.NewUserID(), "p1", ids); err != nil {
+				t.Fatalf("AddTracks: %v", err)
+			}
+
+			if len(batches) != len(tt.wantBatches) {
+				t.Fatalf("batches = %d, want %d", len(batches), len(tt.wantBatches))
+			}
+
+			sent := 0
+			for i, batch := range batches {
+				if len(batch) != tt.wantBatches[i] {
+					t.Errorf("batch %d has %d items, want %d", i, len(batch), tt.wantBatches[i])
+				}
+				for _, uri := range batch {
+					if want := "spotify:track:" + ids[sent]; uri != want {
+						t.Fatalf("item %d = %s, want %s", sent, uri, want)
+					}
+					sent++
+				}
+			}
+		})
+	}
+}
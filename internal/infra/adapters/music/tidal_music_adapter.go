@@ -0,0 +1,363 @@
+package music
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+	services "github.com/zandomed/sync-playlist-api/internal/infra/services/music"
+)
+
+const (
+	tidalDefaultSearchLimit = 5
+	tidalMaxSearchLimit     = services.TidalMaxBatchSize
+	// tidalUserTTL is how long a user's TIDAL ID and country are reused
+	tidalUserTTL = time.Hour
+)
+
+// TidalMusicAdapter works on the playlists the user owns on TIDAL. Catalog
+// requests are scoped to the country of the user's account. TIDAL sets its
+// own page sizes, so PageRequest.Limit is not used and totals are not reported
+type TidalMusicAdapter struct {
+	client      *services.TidalAPIClient
+	credentials CredentialSource
+	users       *userCache[tidalUser]
+}
+
+type tidalUser struct {
+	id      string
+	country string
+}
+
+func NewTidalMusicAdapter(client *services.TidalAPIClient, credentials CredentialSource, clock providers.Clock) providers.MusicService {
+	return &TidalMusicAdapter{
+		client:      client,
+		credentials: credentials,
+		users:       newUserCache[tidalUser](clock, tidalUserTTL),
+	}
+}
+
+// ListPlaylists pages with TIDAL's page cursors
+func (a *TidalMusicAdapter) ListPlaylists(ctx context.Context, userID valueobjects.UserID, page providers.PageRequest) (*providers.Page[valueobjects.Playlist], error) {
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.user(ctx, userID, token)
+	if err != nil {
+		return nil, toMusicError(err, "user", "TIDAL user not found")
+	}
+
+	result, err := a.client.GetUserPlaylists(ctx, token, user.id, user.country, page.Cursor)
+	if err != nil {
+		return nil, toMusicError(err, "playlist", "Playlist not found")
+	}
+
+	playlists := make([]valueobjects.Playlist, 0, len(result.Data))
+	for _, item := range result.Data {
+		playlist, err := toTidalPlaylist(item, user.id)
+		if err != nil {
+			continue
+		}
+		playlists = append(playlists, playlist)
+	}
+
+	return &providers.Page[valueobjects.Playlist]{
+		Items: playlists,
+		Next:  result.Links.NextCursor(),
+		Total: -1,
+	}, nil
+}
+
+func (a *TidalMusicAdapter) GetPlaylistTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, page providers.PageRequest) (*providers.Page[valueobjects.Track], error) {
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.user(ctx, userID, token)
+	if err != nil {
+		return nil, toMusicError(err, "user", "TIDAL user not found")
+	}
+
+	result, err := a.client.GetPlaylistItems(ctx, token, playlistID, user.country, page.Cursor)
+	if err != nil {
+		return nil, toMusicError(err, "playlist", "Playlist not found")
+	}
+
+	trackIDs := make([]string, 0, len(result.Data))
+	for _, item := range result.Data {
+		// Playlists can also hold videos
+		if item.Type == "tracks" {
+			trackIDs = append(trackIDs, item.ID)
+		}
+	}
+
+	tracks, err := a.tracks(ctx, token, user.country, trackIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &providers.Page[valueobjects.Track]{
+		Items: tracks,
+		Next:  result.Links.NextCursor(),
+		Total: -1,
+	}, nil
+}
+
+// SearchTracks filters the catalog by ISRC when there is one, and otherwise
+// runs a search with the title, artist and album
+func (a *TidalMusicAdapter) SearchTracks(ctx context.Context, userID valueobjects.UserID, query providers.TrackQuery) ([]valueobjects.Track, error) {
+	isrc, isrcErr := valueobjects.NewISRC(query.ISRC)
+	q := tidalSearchQuery(query)
+	if isrcErr != nil && q == "" {
+		return []valueobjects.Track{}, nil
+	}
+
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.user(ctx, userID, token)
+	if err != nil {
+		return nil, toMusicError(err, "user", "TIDAL user not found")
+	}
+
+	limit := pageLimit(query.Limit, tidalDefaultSearchLimit, tidalMaxSearchLimit)
+
+	if isrcErr == nil {
+		result, err := a.client.GetTracksByISRC(ctx, token, user.country, isrc.Value())
+		if err != nil {
+			return nil, toMusicError(err, "track", "Track not found")
+		}
+
+		tracks := toTidalTracks(result)
+		return tracks[:min(len(tracks), limit)], nil
+	}
+
+	results, err := a.client.SearchTracks(ctx, token, user.country, q)
+	if err != nil {
+		return nil, toMusicError(err, "track", "Track not found")
+	}
+
+	trackIDs := make([]string, 0, limit)
+	for _, result := range results {
+		if len(trackIDs) < limit {
+			trackIDs = append(trackIDs, result.ID)
+		}
+	}
+
+	return a.tracks(ctx, token, user.country, trackIDs)
+}
+
+// CreatePlaylist creates public playlists as PUBLIC and the rest as UNLISTED,
+// TIDAL's only other visibility
+func (a *TidalMusicAdapter) CreatePlaylist(ctx context.Context, userID valueobjects.UserID, playlist providers.NewPlaylist) (*valueobjects.Playlist, error) {
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.user(ctx, userID, token)
+	if err != nil {
+		return nil, toMusicError(err, "user", "TIDAL user not found")
+	}
+
+	accessType := "UNLISTED"
+	if playlist.Public {
+		accessType = "PUBLIC"
+	}
+
+	created, err := a.client.CreatePlaylist(ctx, token, playlist.Name, playlist.Description, accessType)
+	if err != nil {
+		return nil, toMusicError(err, "playlist", "Playlist not found")
+	}
+
+	result, err := toTidalPlaylist(*created, user.id)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (a *TidalMusicAdapter) AddTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, trackIDs []string) error {
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, batch := range batches(trackIDs, services.TidalMaxBatchSize) {
+		if err := a.client.AddItems(ctx, token, playlistID, batch); err != nil {
+			return toMusicError(err, "playlist", "Playlist not found")
+		}
+	}
+	return nil
+}
+
+// RemoveTracks removes entries by their item ID, which first has to be looked
+// up by walking the playlist
+func (a *TidalMusicAdapter) RemoveTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, trackIDs []string) error {
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	user, err := a.user(ctx, userID, token)
+	if err != nil {
+		return toMusicError(err, "user", "TIDAL user not found")
+	}
+
+	var items []services.TidalIdentifier
+	cursor := ""
+	for {
+		result, err := a.client.GetPlaylistItems(ctx, token, playlistID, user.country, cursor)
+		if err != nil {
+			return toMusicError(err, "playlist", "Playlist not found")
+		}
+
+		for _, item := range result.Data {
+			if item.Type == "tracks" && slices.Contains(trackIDs, item.ID) {
+				items = append(items, item)
+			}
+		}
+
+		cursor = result.Links.NextCursor()
+		if cursor == "" {
+			break
+		}
+	}
+
+	for start := 0; start < len(items); start += services.TidalMaxBatchSize {
+		batch := items[start:min(start+services.TidalMaxBatchSize, len(items))]
+		if err := a.client.RemoveItems(ctx, token, playlistID, batch); err != nil {
+			return toMusicError(err, "playlist", "Playlist not found")
+		}
+	}
+	return nil
+}
+
+// ReorderTracks is not offered: TIDAL moves entries relative to another
+// entry's item ID, which does not map to positions without walking the
+// whole playlist for every move
+func (a *TidalMusicAdapter) ReorderTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, move providers.TrackMove) error {
+	return providers.ErrOperationNotSupported
+}
+
+func (a *TidalMusicAdapter) accessToken(ctx context.Context, userID valueobjects.UserID) (string, error) {
+	return a.credentials.GetAccessToken(ctx, userID, entities.TidalProvider)
+}
+
+// user returns the TIDAL ID and country of the user, which playlist listings
+// and catalog requests are scoped to
+func (a *TidalMusicAdapter) user(ctx context.Context, userID valueobjects.UserID, accessToken string) (tidalUser, error) {
+	key := userID.String()
+	if cached, ok := a.users.get(key); ok {
+		return cached, nil
+	}
+
+	me, err := a.client.GetMe(ctx, accessToken)
+	if err != nil {
+		return tidalUser{}, err
+	}
+
+	user := tidalUser{id: me.ID, country: me.Attributes.Country}
+	a.users.set(key, user)
+	return user, nil
+}
+
+// tracks describes the tracks with their artists and albums, keeping their order
+func (a *TidalMusicAdapter) tracks(ctx context.Context, accessToken, countryCode string, trackIDs []string) ([]valueobjects.Track, error) {
+	byID := make(map[string]valueobjects.Track, len(trackIDs))
+	for _, batch := range batches(trackIDs, services.TidalMaxBatchSize) {
+		result, err := a.client.GetTracks(ctx, accessToken, countryCode, batch)
+		if err != nil {
+			return nil, toMusicError(err, "track", "Track not found")
+		}
+
+		for _, track := range toTidalTracks(result) {
+			byID[track.ID()] = track
+		}
+	}
+
+	tracks := make([]valueobjects.Track, 0, len(trackIDs))
+	for _, id := range trackIDs {
+		if track, ok := byID[id]; ok {
+			tracks = append(tracks, track)
+		}
+	}
+	return tracks, nil
+}
+
+func tidalSearchQuery(query providers.TrackQuery) string {
+	var parts []string
+	for _, field := range []string{query.Artist, query.Title, query.Album} {
+		if field = strings.TrimSpace(field); field != "" {
+			parts = append(parts, field)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func toTidalPlaylist(playlist services.TidalPlaylist, ownerID string) (valueobjects.Playlist, error) {
+	return valueobjects.NewPlaylist(
+		playlist.ID,
+		playlist.Attributes.Name,
+		playlist.Attributes.Description,
+		ownerID,
+		playlist.Attributes.NumberOfItems,
+		playlist.Attributes.AccessType == "PUBLIC",
+	)
+}
+
+// toTidalTracks resolves the artists and albums of the tracks from the
+// included resources
+func toTidalTracks(result *services.TidalTracks) []valueobjects.Track {
+	included := make(map[string]services.TidalIncluded, len(result.Included))
+	for _, resource := range result.Included {
+		included[resource.Type+"/"+resource.ID] = resource
+	}
+
+	tracks := make([]valueobjects.Track, 0, len(result.Data))
+	for _, item := range result.Data {
+		artists := make([]valueobjects.Artist, 0, len(item.Relationships.Artists.Data))
+		for _, ref := range item.Relationships.Artists.Data {
+			artist, err := valueobjects.NewArtist(ref.ID, included["artists/"+ref.ID].Attributes.Name)
+			if err != nil {
+				continue
+			}
+			artists = append(artists, artist)
+		}
+
+		album := valueobjects.NewAlbum("", "", valueobjects.UPC{})
+		if refs := item.Relationships.Albums.Data; len(refs) > 0 {
+			resource := included["albums/"+refs[0].ID]
+			album = valueobjects.NewAlbum(refs[0].ID, resource.Attributes.Title, optionalUPC(resource.Attributes.BarcodeID))
+		}
+
+		title := item.Attributes.Title
+		if version := strings.TrimSpace(item.Attributes.Version); version != "" {
+			title += " (" + version + ")"
+		}
+
+		track, err := valueobjects.NewTrack(
+			item.ID,
+			title,
+			artists,
+			album,
+			parseISODuration(item.Attributes.Duration),
+			item.Attributes.Explicit,
+			optionalISRC(item.Attributes.ISRC),
+		)
+		if err != nil {
+			continue
+		}
+		tracks = append(tracks, track)
+	}
+	return tracks
+}
@@ -0,0 +1,71 @@
+package music
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+)
+
+// userCache keeps a value per user for a fixed time, like the storefront or
+// account ID that scope a user's requests. Expired entries are dropped when
+// read and swept once per TTL, so users who stop calling the service do not
+// stay in memory
+type userCache[V any] struct {
+	clock providers.Clock
+	ttl   time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]userCacheEntry[V]
+	nextSweep time.Time
+}
+
+type userCacheEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+func newUserCache[V any](clock providers.Clock, ttl time.Duration) *userCache[V] {
+	return &userCache[V]{
+		clock:   clock,
+		ttl:     ttl,
+		entries: make(map[string]userCacheEntry[V]),
+	}
+}
+
+func (c *userCache[V]) get(key string) (V, bool) {
+	now := c.clock.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	if !now.Before(entry.expiresAt) {
+		delete(c.entries, key)
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+func (c *userCache[V]) set(key string, value V) {
+	now := c.clock.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !now.Before(c.nextSweep) {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		c.nextSweep = now.Add(c.ttl)
+	}
+
+	c.entries[key] = userCacheEntry[V]{value: value, expiresAt: now.Add(c.ttl)}
+}
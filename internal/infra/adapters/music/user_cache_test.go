@@ -0,0 +1,44 @@
+package music
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestUserCacheExpiresAndEvicts(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	cache := newUserCache[string](clock, time.Hour)
+
+	cache.set("active", "us")
+	cache.set("idle", "gb")
+
+	clock.now = clock.now.Add(59 * time.Minute)
+	if got, ok := cache.get("active"); !ok || got != "us" {
+		t.Fatalf("get before expiry = %q, %v", got, ok)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	if _, ok := cache.get("active"); ok {
+		t.Fatal("get returned an expired entry")
+	}
+	if _, ok := cache.entries["active"]; ok {
+		t.Error("expired entry was not dropped on read")
+	}
+
+	// "idle" is never read again; the next write sweeps it
+	cache.set("active", "us")
+	if _, ok := cache.entries["idle"]; ok {
+		t.Error("expired entry of an idle user was not swept")
+	}
+	if len(cache.entries) != 1 {
+		t.Errorf("len(entries) = %d, want 1", len(cache.entries))
+	}
+}
@@ -0,0 +1,350 @@
+package music
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+	services "github.com/zandomed/sync-playlist-api/internal/infra/services/music"
+)
+
+const (
+	youtubePageLimit          = 50
+	youtubeDefaultSearchLimit = 5
+	// youtubeSearchCandidates are fetched per search so the ranking has
+	// uploads to choose from; the cost is the same for any page size
+	youtubeSearchCandidates = 15
+	// youtubeTopicSuffix ends the name of the channels YouTube generates for
+	// artists, which upload the studio recordings
+	youtubeTopicSuffix = " - Topic"
+)
+
+var isoDurationRegex = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// YouTubeMusicAdapter works on the user's YouTube playlists through the
+// YouTube Data API. Every call spends the app's daily quota; once it is
+// spent, calls fail with providers.ErrQuotaExhausted until it resets
+type YouTubeMusicAdapter struct {
+	client      *services.YouTubeAPIClient
+	credentials CredentialSource
+}
+
+func NewYouTubeMusicAdapter(client *services.YouTubeAPIClient, credentials CredentialSource) providers.MusicService {
+	return &YouTubeMusicAdapter{
+		client:      client,
+		credentials: credentials,
+	}
+}
+
+// ListPlaylists pages with YouTube's page tokens as cursors
+func (a *YouTubeMusicAdapter) ListPlaylists(ctx context.Context, userID valueobjects.UserID, page providers.PageRequest) (*providers.Page[valueobjects.Playlist], error) {
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := a.client.GetMyPlaylists(ctx, token, page.Cursor, pageLimit(page.Limit, youtubePageLimit, youtubePageLimit))
+	if err != nil {
+		return nil, toYouTubeError(err, "playlist", "Playlist not found")
+	}
+
+	playlists := make([]valueobjects.Playlist, 0, len(result.Items))
+	for _, item := range result.Items {
+		playlist, err := toYouTubePlaylist(item)
+		if err != nil {
+			continue
+		}
+		playlists = append(playlists, playlist)
+	}
+
+	return &providers.Page[valueobjects.Playlist]{
+		Items: playlists,
+		Next:  result.NextPageToken,
+		Total: result.PageInfo.TotalResults,
+	}, nil
+}
+
+func (a *YouTubeMusicAdapter) GetPlaylistTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, page providers.PageRequest) (*providers.Page[valueobjects.Track], error) {
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := a.client.GetPlaylistItems(ctx, token, playlistID, page.Cursor, pageLimit(page.Limit, youtubePageLimit, youtubePageLimit))
+	if err != nil {
+		return nil, toYouTubeError(err, "playlist", "Playlist not found")
+	}
+
+	videoIDs := make([]string, 0, len(result.Items))
+	for _, item := range result.Items {
+		// Deleted and private videos have no owner channel
+		if item.ContentDetails.VideoID != "" && item.Snippet.VideoOwnerChannelTitle != "" {
+			videoIDs = append(videoIDs, item.ContentDetails.VideoID)
+		}
+	}
+
+	tracks, err := a.videoTracks(ctx, token, videoIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &providers.Page[valueobjects.Track]{
+		Items: tracks,
+		Next:  result.NextPageToken,
+		Total: result.PageInfo.TotalResults,
+	}, nil
+}
+
+// SearchTracks searches by title and artist, as YouTube has no ISRC lookup.
+// Uploads of "Topic" channels come first, then official audio, then the rest
+// in YouTube's order
+func (a *YouTubeMusicAdapter) SearchTracks(ctx context.Context, userID valueobjects.UserID, query providers.TrackQuery) ([]valueobjects.Track, error) {
+	q := youtubeSearchQuery(query)
+	if q == "" {
+		return []valueobjects.Track{}, nil
+	}
+
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := a.client.SearchVideos(ctx, token, q, youtubeSearchCandidates)
+	if err != nil {
+		return nil, toYouTubeError(err, "track", "Track not found")
+	}
+
+	slices.SortStableFunc(results, func(x, y services.YouTubeSearchResult) int {
+		return youtubeUploadRank(x.Snippet.Title, x.Snippet.ChannelTitle) - youtubeUploadRank(y.Snippet.Title, y.Snippet.ChannelTitle)
+	})
+
+	limit := pageLimit(query.Limit, youtubeDefaultSearchLimit, youtubeSearchCandidates)
+	videoIDs := make([]string, 0, limit)
+	for _, result := range results {
+		if result.ID.VideoID != "" && len(videoIDs) < limit {
+			videoIDs = append(videoIDs, result.ID.VideoID)
+		}
+	}
+
+	return a.videoTracks(ctx, token, videoIDs)
+}
+
+func (a *YouTubeMusicAdapter) CreatePlaylist(ctx context.Context, userID valueobjects.UserID, playlist providers.NewPlaylist) (*valueobjects.Playlist, error) {
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	privacy := "private"
+	if playlist.Public {
+		privacy = "public"
+	}
+
+	created, err := a.client.CreatePlaylist(ctx, token, playlist.Name, playlist.Description, privacy)
+	if err != nil {
+		return nil, toYouTubeError(err, "playlist", "Playlist not found")
+	}
+
+	result, err := toYouTubePlaylist(*created)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AddTracks inserts the videos one by one, as the API requires. When the
+// quota runs out midway the error tells how many were added, so the caller
+// can resume with the rest
+func (a *YouTubeMusicAdapter) AddTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, trackIDs []string) error {
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for i, videoID := range trackIDs {
+		if err := a.client.InsertPlaylistItem(ctx, token, playlistID, videoID); err != nil {
+			return fmt.Errorf("added %d of %d tracks: %w", i, len(trackIDs), toYouTubeError(err, "playlist", "Playlist not found"))
+		}
+	}
+	return nil
+}
+
+// RemoveTracks deletes playlist items, which first have to be looked up by
+// walking the playlist
+func (a *YouTubeMusicAdapter) RemoveTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, trackIDs []string) error {
+	token, err := a.accessToken(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var itemIDs []string
+	pageToken := ""
+	for {
+		result, err := a.client.GetPlaylistItems(ctx, token, playlistID, pageToken, youtubePageLimit)
+		if err != nil {
+			return toYouTubeError(err, "playlist", "Playlist not found")
+		}
+
+		for _, item := range result.Items {
+			if slices.Contains(trackIDs, item.ContentDetails.VideoID) {
+				itemIDs = append(itemIDs, item.ID)
+			}
+		}
+
+		if result.NextPageToken == "" {
+			break
+		}
+		pageToken = result.NextPageToken
+	}
+
+	for _, itemID := range itemIDs {
+		if err := a.client.DeletePlaylistItem(ctx, token, itemID); err != nil {
+			return toYouTubeError(err, "playlist", "Playlist not found")
+		}
+	}
+	return nil
+}
+
+// ReorderTracks is not offered: YouTube moves one item per call, at 50 quota
+// units each
+func (a *YouTubeMusicAdapter) ReorderTracks(ctx context.Context, userID valueobjects.UserID, playlistID string, move providers.TrackMove) error {
+	return providers.ErrOperationNotSupported
+}
+
+func (a *YouTubeMusicAdapter) accessToken(ctx context.Context, userID valueobjects.UserID) (string, error) {
+	return a.credentials.GetAccessToken(ctx, userID, entities.YouTubeProvider)
+}
+
+// videoTracks describes the videos as tracks, keeping their order. The video
+// details add the duration for one quota unit per 50 videos
+func (a *YouTubeMusicAdapter) videoTracks(ctx context.Context, accessToken string, videoIDs []string) ([]valueobjects.Track, error) {
+	tracks := make([]valueobjects.Track, 0, len(videoIDs))
+	if len(videoIDs) == 0 {
+		return tracks, nil
+	}
+
+	videos, err := a.client.GetVideos(ctx, accessToken, videoIDs)
+	if err != nil {
+		return nil, toYouTubeError(err, "track", "Track not found")
+	}
+
+	byID := make(map[string]services.YouTubeVideo, len(videos))
+	for _, video := range videos {
+		byID[video.ID] = video
+	}
+
+	for _, id := range videoIDs {
+		video, ok := byID[id]
+		if !ok {
+			continue
+		}
+
+		track, err := toYouTubeTrack(video)
+		if err != nil {
+			continue
+		}
+		tracks = append(tracks, track)
+	}
+	return tracks, nil
+}
+
+func toYouTubeError(err error, resource, message string) error {
+	if stderrors.Is(err, services.ErrYouTubeQuotaExhausted) {
+		return fmt.Errorf("%w: %v", providers.ErrQuotaExhausted, err)
+	}
+	return toMusicError(err, resource, message)
+}
+
+func youtubeSearchQuery(query providers.TrackQuery) string {
+	var parts []string
+	for _, field := range []string{query.Artist, query.Title} {
+		if field = strings.TrimSpace(field); field != "" {
+			parts = append(parts, field)
+		}
+	}
+
+	if len(parts) == 0 {
+		// Some uploads list the ISRC in their description
+		return strings.TrimSpace(query.ISRC)
+	}
+	return strings.Join(parts, " ")
+}
+
+// youtubeUploadRank orders search results: auto-generated "Topic" uploads
+// carry the studio recording and its metadata, official audio comes next
+func youtubeUploadRank(title, channelTitle string) int {
+	switch {
+	case strings.HasSuffix(channelTitle, youtubeTopicSuffix):
+		return 0
+	case strings.Contains(strings.ToLower(title), "official audio"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func toYouTubePlaylist(playlist services.YouTubePlaylist) (valueobjects.Playlist, error) {
+	return valueobjects.NewPlaylist(
+		playlist.ID,
+		playlist.Snippet.Title,
+		playlist.Snippet.Description,
+		playlist.Snippet.ChannelID,
+		playlist.ContentDetails.ItemCount,
+		playlist.Status.PrivacyStatus == "public",
+	)
+}
+
+// toYouTubeTrack reads the artist from "Artist - Topic" channels, or from
+// "Artist - Title" video titles, falling back to the channel name
+func toYouTubeTrack(video services.YouTubeVideo) (valueobjects.Track, error) {
+	title := video.Snippet.Title
+	artistName := video.Snippet.ChannelTitle
+
+	if strings.HasSuffix(artistName, youtubeTopicSuffix) {
+		artistName = strings.TrimSuffix(artistName, youtubeTopicSuffix)
+	} else if artist, song, ok := strings.Cut(title, " - "); ok {
+		artistName, title = artist, song
+	}
+
+	var artists []valueobjects.Artist
+	if artist, err := valueobjects.NewArtist("", artistName); err == nil {
+		artists = append(artists, artist)
+	}
+
+	return valueobjects.NewTrack(
+		video.ID,
+		title,
+		artists,
+		valueobjects.NewAlbum("", "", valueobjects.UPC{}),
+		parseISODuration(video.ContentDetails.Duration),
+		false,
+		valueobjects.ISRC{},
+	)
+}
+
+// parseISODuration reads the ISO 8601 durations of the API, zero when invalid
+func parseISODuration(value string) time.Duration {
+	match := isoDurationRegex.FindStringSubmatch(value)
+	if match == nil {
+		return 0
+	}
+
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var duration time.Duration
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+		n, _ := strconv.Atoi(match[i+1])
+		duration += time.Duration(n) * unit
+	}
+	return duration
+}
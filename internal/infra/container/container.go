@@ -1,88 +1,390 @@
 package container
 
 import (
+	"github.com/labstack/echo/v4"
 	"github.com/zandomed/sync-playlist-api/internal/config"
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
 	authAdapters "github.com/zandomed/sync-playlist-api/internal/infra/adapters/auth"
+	"github.com/zandomed/sync-playlist-api/internal/infra/adapters/clock"
+	mailAdapters "github.com/zandomed/sync-playlist-api/internal/infra/adapters/mail"
+	musicAdapters "github.com/zandomed/sync-playlist-api/internal/infra/adapters/music"
 	httpHandlers "github.com/zandomed/sync-playlist-api/internal/infra/http/handlers"
 	httpMappers "github.com/zandomed/sync-playlist-api/internal/infra/http/mappers"
 	repoAdapters "github.com/zandomed/sync-playlist-api/internal/infra/repositories"
 	services "github.com/zandomed/sync-playlist-api/internal/infra/services/auth"
+	musicClients "github.com/zandomed/sync-playlist-api/internal/infra/services/music"
+	"github.com/zandomed/sync-playlist-api/internal/middleware"
 	"github.com/zandomed/sync-playlist-api/internal/usecases"
 	authUC "github.com/zandomed/sync-playlist-api/internal/usecases/auth"
 	healthUC "github.com/zandomed/sync-playlist-api/internal/usecases/health"
 	"github.com/zandomed/sync-playlist-api/pkg/database"
+	"github.com/zandomed/sync-playlist-api/pkg/envelope"
+	"github.com/zandomed/sync-playlist-api/pkg/jwtkeys"
 	"github.com/zandomed/sync-playlist-api/pkg/logger"
 )
 
 type Container struct {
 	// Handlers
-	AuthHandler   *httpHandlers.AuthHandler
-	HealthHandler *httpHandlers.HealthHandler
+	AuthHandler    *httpHandlers.AuthHandler
+	HealthHandler  *httpHandlers.HealthHandler
+	SessionHandler *httpHandlers.SessionHandler
+	MFAHandler     *httpHandlers.MFAHandler
+	AccountHandler *httpHandlers.AccountHandler
+	AdminHandler   *httpHandlers.AdminHandler
+	APIKeyHandler  *httpHandlers.APIKeyHandler
+	JWKSHandler    *httpHandlers.JWKSHandler
+
+	// Middlewares
+	JWTMiddleware echo.MiddlewareFunc
+	// AuthMiddleware also accepts personal API keys; pair it with RequireScope
+	AuthMiddleware echo.MiddlewareFunc
+
+	// Services
+	ProviderCredentials *authUC.ProviderCredentialService
+	// MusicServices holds a catalog adapter per configured streaming service
+	MusicServices *providers.MusicServiceRegistry
+
+	// Jobs
+	CleanupExpiredRecords *authUC.CleanupExpiredRecordsUseCase
 }
 
 func NewContainer(db *database.DB, cfg *config.Config, logger *logger.Logger) *Container {
 	userRepo := repoAdapters.NewPostgresUserRepository(db)
-	accountRepo := repoAdapters.NewPostgresAccountRepository(db)
+	tokenCipher := loadTokenCipher(cfg, logger)
+	accountRepo := repoAdapters.NewPostgresAccountRepository(db, tokenCipher, logger)
 	tokenRepo := repoAdapters.NewPostgresTokenRepository(db)
 	verificationRepo := repoAdapters.NewPostgresVerificationRepository(db)
+	revokedTokenRepo := repoAdapters.NewPostgresRevokedTokenRepository(db)
+	twoFactorRepo := repoAdapters.NewPostgresTwoFactorRepository(db, tokenCipher)
+	apiKeyRepo := repoAdapters.NewPostgresAPIKeyRepository(db)
+
+	var loginAttemptRepo repositories.LoginAttemptRepository
+	if cfg.Auth.LoginAttemptStore == "memory" {
+		loginAttemptRepo = repoAdapters.NewInMemoryLoginAttemptRepository()
+	} else {
+		loginAttemptRepo = repoAdapters.NewPostgresLoginAttemptRepository(db)
+	}
+
+	systemClock := clock.NewSystemClock()
+
+	keyRing, err := jwtkeys.Load(&cfg.JWT)
+	if err != nil {
+		logger.Sugar().Fatalf("Failed to load JWT signing keys: %v", err)
+	}
 
 	tokenGenerator := authAdapters.NewJWTTokenGenerator(
-		cfg.JWT.Secret,
+		keyRing,
 		cfg.JWT.ExpirationTime,
 		cfg.JWT.RefreshExpirationTime,
 	)
 
-	googleOAuthService := services.NewGoogleOAuthService(
-		cfg.Google.ClientID,
-		cfg.Google.ClientSecret,
-		cfg.Google.RedirectURL,
-	)
-	googleOAuthAdapter := authAdapters.NewGoogleOAuthAdapter(googleOAuthService)
+	// Only providers with credentials are exposed under /v1/oauth/:provider
+	oauthProviders := providers.NewOAuthProviderRegistry()
 
-	spotifyOAuthService := services.NewSpotifyOAuthService(
-		cfg.Spotify.ClientID,
-		cfg.Spotify.ClientSecret,
-		cfg.Spotify.RedirectURL,
-		cfg.Spotify.APIUrl,
-	)
-	spotifyOAuthAdapter := authAdapters.NewSpotifyOAuthAdapter(spotifyOAuthService)
+	if cfg.Google.ClientID != "" {
+		googleOAuthService := services.NewGoogleOAuthService(
+			cfg.Google.ClientID,
+			cfg.Google.ClientSecret,
+			cfg.Google.RedirectURL,
+		)
+		oauthProviders.Register(entities.GoogleProvider, authAdapters.NewGoogleOAuthAdapter(googleOAuthService))
+
+		// YouTube is linked with its own Google consent so signing in never asks for the youtube scope
+		youtubeOAuthService := services.NewYouTubeOAuthService(
+			cfg.Google.ClientID,
+			cfg.Google.ClientSecret,
+			cfg.YouTube.RedirectURL,
+		)
+		oauthProviders.Register(entities.YouTubeProvider, authAdapters.NewGoogleOAuthAdapter(youtubeOAuthService))
+	}
+
+	if cfg.Spotify.ClientID != "" {
+		spotifyOAuthService := services.NewSpotifyOAuthService(
+			cfg.Spotify.ClientID,
+			cfg.Spotify.ClientSecret,
+			cfg.Spotify.RedirectURL,
+			cfg.Spotify.APIUrl,
+		)
+		oauthProviders.Register(entities.SpotifyProvider, authAdapters.NewSpotifyOAuthAdapter(spotifyOAuthService))
+	}
+
+	if cfg.Apple.ClientID != "" && cfg.Apple.PrivateKey != "" {
+		appleOAuthService, err := services.NewAppleOAuthService(
+			cfg.Apple.ClientID,
+			cfg.Apple.TeamID,
+			cfg.Apple.KeyID,
+			cfg.Apple.PrivateKey,
+			cfg.Apple.RedirectURL,
+			jwtkeys.NewHTTPJWKSFetcher(nil, services.AppleJWKSURL),
+		)
+		if err != nil {
+			logger.Sugar().Fatalf("Failed to configure Sign in with Apple: %v", err)
+		}
+		oauthProviders.Register(entities.AppleProvider, authAdapters.NewAppleOAuthAdapter(appleOAuthService))
+	}
+
+	if cfg.Deezer.AppID != "" {
+		deezerOAuthService := services.NewDeezerOAuthService(
+			cfg.Deezer.AppID,
+			cfg.Deezer.Secret,
+			cfg.Deezer.RedirectURL,
+			cfg.Deezer.ConnectURL,
+			cfg.Deezer.APIUrl,
+		)
+		oauthProviders.Register(entities.DeezerProvider, authAdapters.NewDeezerOAuthAdapter(deezerOAuthService))
+	}
+
+	if cfg.Tidal.ClientID != "" {
+		tidalOAuthService := services.NewTidalOAuthService(
+			cfg.Tidal.ClientID,
+			cfg.Tidal.ClientSecret,
+			cfg.Tidal.RedirectURL,
+			cfg.Tidal.LoginURL,
+			cfg.Tidal.AuthURL,
+			cfg.Tidal.APIUrl,
+		)
+		oauthProviders.Register(entities.TidalProvider, authAdapters.NewTidalOAuthAdapter(tidalOAuthService))
+	}
+
+	var mailer providers.Mailer
+	if cfg.Mail.Driver == "smtp" {
+		mailer = mailAdapters.NewSMTPMailer(
+			cfg.Mail.SMTPHost,
+			cfg.Mail.SMTPPort,
+			cfg.Mail.SMTPUsername,
+			cfg.Mail.SMTPPassword,
+			cfg.Mail.From,
+		)
+	} else {
+		mailer = mailAdapters.NewOutboxMailer(cfg.Mail.OutboxDir, cfg.Mail.From, logger)
+	}
+	// Emails sent for unauthenticated requests about any address (password
+	// resets, verification resends) go out in the background, so the response
+	// time does not reveal registered accounts
+	asyncMailer := mailAdapters.NewAsyncMailer(mailer, logger)
+
+	authPolicy := entities.AuthenticationPolicy{
+		RequireVerifiedEmail: cfg.Auth.RequireVerifiedEmail,
+	}
+	emailVerificationConfig := authUC.EmailVerificationConfig{
+		URL:        cfg.Auth.EmailVerificationURL,
+		Expiration: cfg.Auth.EmailVerificationExpiration,
+	}
+	mfaConfig := authUC.MFAConfig{
+		Issuer:              cfg.Auth.MFAIssuer,
+		ChallengeExpiration: cfg.Auth.MFAChallengeExpiration,
+	}
+	loginThrottleConfig := authUC.LoginThrottleConfig{
+		Email: lockoutPolicy(cfg.Auth.EmailLockout),
+		IP:    lockoutPolicy(cfg.Auth.IPLockout),
+	}
+	loginThrottle := authUC.NewLoginThrottle(loginAttemptRepo, systemClock, loginThrottleConfig)
+	mailThrottleConfig := authUC.MailThrottleConfig{
+		Email: lockoutPolicy(cfg.Auth.MailEmailLimit),
+		IP:    lockoutPolicy(cfg.Auth.MailIPLimit),
+	}
+	mailThrottle := authUC.NewMailThrottle(loginAttemptRepo, systemClock, mailThrottleConfig)
+	cleanupExpiredRecordsUC := authUC.NewCleanupExpiredRecordsUseCase(tokenRepo, revokedTokenRepo, verificationRepo, loginAttemptRepo, systemClock, loginThrottleConfig, mailThrottleConfig)
+	passwordResetConfig := authUC.PasswordResetConfig{
+		URL:        cfg.Auth.PasswordResetURL,
+		Expiration: cfg.Auth.PasswordResetExpiration,
+	}
 
 	// State Expiration
 	expirationTimeForOAuthState := cfg.OAuth.TokenExpiration
 	expirationTimeForFrontendOAuth := cfg.OAuth.FrontendTokenExpiration
 
-	registerUserUC := authUC.NewRegisterUserUseCase(userRepo, accountRepo)
-	loginUserUC := authUC.NewLoginUserUseCase(userRepo, accountRepo, tokenRepo, tokenGenerator)
-	googleLoginUC := authUC.NewLoginGoogleUseCase(userRepo, accountRepo, tokenRepo, verificationRepo, tokenGenerator, googleOAuthAdapter, expirationTimeForFrontendOAuth)
-	spotifyLoginUC := authUC.NewLoginSpotifyUseCase(userRepo, accountRepo, tokenRepo, verificationRepo, tokenGenerator, spotifyOAuthAdapter, expirationTimeForFrontendOAuth)
-	linkSpotifyUC := authUC.NewLinkSpotifyAccountUseCase(userRepo, accountRepo, spotifyOAuthAdapter)
-	getUrlSpotifyUC := authUC.NewGetUrlSpotifyUseCase(spotifyOAuthAdapter, verificationRepo, expirationTimeForOAuthState)
-	getUrlGoogleUC := authUC.NewGetUrlGoogleUseCase(googleOAuthAdapter, verificationRepo, expirationTimeForOAuthState)
-	verifyTokenUC := authUC.NewVerifyTokenUseCase(verificationRepo)
+	registerUserUC := authUC.NewRegisterUserUseCase(userRepo, accountRepo, verificationRepo, mailer, emailVerificationConfig)
+	loginUserUC := authUC.NewLoginUserUseCase(userRepo, accountRepo, tokenRepo, tokenGenerator, twoFactorRepo, verificationRepo, loginThrottle, authPolicy, mfaConfig)
+	linkOAuthUC := authUC.NewLinkOAuthAccountUseCase(userRepo, accountRepo, verificationRepo, oauthProviders)
+	loginOAuthUC := authUC.NewLoginOAuthUseCase(userRepo, accountRepo, verificationRepo, oauthProviders, linkOAuthUC, expirationTimeForFrontendOAuth, authPolicy)
+	getOAuthURLUC := authUC.NewGetOAuthURLUseCase(oauthProviders, verificationRepo, expirationTimeForOAuthState)
+	verifyTokenUC := authUC.NewVerifyTokenUseCase(userRepo, verificationRepo, tokenRepo, tokenGenerator, authPolicy)
+	refreshTokenUC := authUC.NewRefreshTokenUseCase(userRepo, tokenRepo, revokedTokenRepo, tokenGenerator, authPolicy)
+	logoutUC := authUC.NewLogoutUseCase(tokenRepo, revokedTokenRepo, tokenGenerator)
+	logoutAllUC := authUC.NewLogoutAllUseCase(tokenRepo, revokedTokenRepo, tokenGenerator)
+	listSessionsUC := authUC.NewListSessionsUseCase(tokenRepo)
+	verifyEmailUC := authUC.NewVerifyEmailUseCase(userRepo, verificationRepo)
+	resendVerificationEmailUC := authUC.NewResendVerificationEmailUseCase(userRepo, verificationRepo, asyncMailer, mailThrottle, emailVerificationConfig)
+	forgotPasswordUC := authUC.NewForgotPasswordUseCase(userRepo, accountRepo, verificationRepo, asyncMailer, mailThrottle, passwordResetConfig)
+	resetPasswordUC := authUC.NewResetPasswordUseCase(userRepo, accountRepo, verificationRepo, tokenRepo, revokedTokenRepo, tokenGenerator)
+	changePasswordUC := authUC.NewChangePasswordUseCase(accountRepo, tokenRepo, revokedTokenRepo, tokenGenerator)
+	startTOTPEnrollmentUC := authUC.NewStartTOTPEnrollmentUseCase(userRepo, accountRepo, twoFactorRepo, systemClock, mfaConfig)
+	confirmTOTPEnrollmentUC := authUC.NewConfirmTOTPEnrollmentUseCase(twoFactorRepo, systemClock)
+	disableTOTPUC := authUC.NewDisableTOTPUseCase(twoFactorRepo, systemClock)
+	verifyMFAUC := authUC.NewVerifyMFAUseCase(userRepo, verificationRepo, twoFactorRepo, tokenRepo, tokenGenerator, loginThrottle, systemClock, authPolicy)
+	revokeSessionUC := authUC.NewRevokeSessionUseCase(tokenRepo, revokedTokenRepo, tokenGenerator)
+	listLinkedAccountsUC := authUC.NewListLinkedAccountsUseCase(accountRepo, systemClock)
+	unlinkAccountUC := authUC.NewUnlinkAccountUseCase(accountRepo, oauthProviders)
+	changeUserRoleUC := authUC.NewChangeUserRoleUseCase(userRepo, tokenRepo, revokedTokenRepo, tokenGenerator)
+	createAPIKeyUC := authUC.NewCreateAPIKeyUseCase(apiKeyRepo, systemClock)
+	listAPIKeysUC := authUC.NewListAPIKeysUseCase(apiKeyRepo)
+	updateAPIKeyUC := authUC.NewUpdateAPIKeyUseCase(apiKeyRepo, systemClock)
+	deleteAPIKeyUC := authUC.NewDeleteAPIKeyUseCase(apiKeyRepo)
+	authenticateAPIKeyUC := authUC.NewAuthenticateAPIKeyUseCase(apiKeyRepo, userRepo, systemClock, authPolicy)
 	getStatusUC := healthUC.NewGetStatusUseCase(cfg)
 
+	providerCredentials := authUC.NewProviderCredentialService(accountRepo, oauthProviders, systemClock)
+	musicServices := providers.NewMusicServiceRegistry()
+
+	if cfg.Google.ClientID != "" {
+		youtubeClient := musicClients.NewYouTubeAPIClient(nil, cfg.YouTube.APIUrl, musicClients.NewYouTubeQuota(cfg.YouTube.DailyQuota))
+		musicServices.Register(entities.YouTubeProvider, musicAdapters.NewYouTubeMusicAdapter(youtubeClient, providerCredentials))
+	}
+
+	// MusicKit developer tokens are signed with the same team key as Sign in with Apple
+	var appleDeveloperTokens providers.MusicDeveloperTokenProvider
+	if cfg.Apple.TeamID != "" && cfg.Apple.KeyID != "" && cfg.Apple.PrivateKey != "" {
+		developerTokens, err := musicClients.NewAppleDeveloperTokenGenerator(cfg.Apple.TeamID, cfg.Apple.KeyID, cfg.Apple.PrivateKey)
+		if err != nil {
+			logger.Sugar().Fatalf("Failed to configure Apple Music: %v", err)
+		}
+		appleDeveloperTokens = developerTokens
+
+		appleMusicClient := musicClients.NewAppleMusicAPIClient(nil, cfg.Apple.MusicAPIUrl, developerTokens)
+		musicServices.Register(entities.AppleProvider, musicAdapters.NewAppleMusicAdapter(appleMusicClient, providerCredentials, systemClock))
+	}
+
+	getAppleMusicDeveloperTokenUC := authUC.NewGetAppleMusicDeveloperTokenUseCase(appleDeveloperTokens)
+	setAppleMusicTokenUC := authUC.NewSetAppleMusicTokenUseCase(accountRepo)
+
+	if cfg.Spotify.ClientID != "" {
+		spotifyClient := musicClients.NewSpotifyAPIClient(nil, cfg.Spotify.APIUrl)
+		musicServices.Register(entities.SpotifyProvider, musicAdapters.NewSpotifyMusicAdapter(spotifyClient, providerCredentials))
+	}
+
+	if cfg.Deezer.AppID != "" {
+		deezerClient := musicClients.NewDeezerAPIClient(nil, cfg.Deezer.APIUrl)
+		musicServices.Register(entities.DeezerProvider, musicAdapters.NewDeezerMusicAdapter(deezerClient, providerCredentials))
+	}
+
+	if cfg.Tidal.ClientID != "" {
+		tidalClient := musicClients.NewTidalAPIClient(nil, cfg.Tidal.APIUrl)
+		musicServices.Register(entities.TidalProvider, musicAdapters.NewTidalMusicAdapter(tidalClient, providerCredentials, systemClock))
+	}
+
 	authMapper := httpMappers.NewAuthMapper()
+	sessionMapper := httpMappers.NewSessionMapper()
+	mfaMapper := httpMappers.NewMFAMapper()
+	accountMapper := httpMappers.NewAccountMapper()
+	adminMapper := httpMappers.NewAdminMapper()
+	apiKeyMapper := httpMappers.NewAPIKeyMapper()
 
 	authHandler := httpHandlers.NewAuthHandler(
 		usecases.NewAuthUseCases(
 			registerUserUC,
 			loginUserUC,
-			googleLoginUC,
-			spotifyLoginUC,
-			linkSpotifyUC,
-			getUrlSpotifyUC,
-			getUrlGoogleUC,
+			loginOAuthUC,
+			linkOAuthUC,
+			getOAuthURLUC,
 			verifyTokenUC,
+			refreshTokenUC,
+			logoutUC,
+			logoutAllUC,
+			verifyEmailUC,
+			resendVerificationEmailUC,
+			forgotPasswordUC,
+			resetPasswordUC,
+			changePasswordUC,
 		),
 		authMapper,
 		cfg,
 		logger,
 	)
 
+	sessionHandler := httpHandlers.NewSessionHandler(
+		usecases.NewSessionUseCases(
+			listSessionsUC,
+			revokeSessionUC,
+		),
+		sessionMapper,
+		logger,
+	)
+
+	mfaHandler := httpHandlers.NewMFAHandler(
+		usecases.NewMFAUseCases(
+			startTOTPEnrollmentUC,
+			confirmTOTPEnrollmentUC,
+			disableTOTPUC,
+			verifyMFAUC,
+		),
+		mfaMapper,
+		cfg,
+		logger,
+	)
+
+	accountHandler := httpHandlers.NewAccountHandler(
+		usecases.NewAccountUseCases(
+			listLinkedAccountsUC,
+			unlinkAccountUC,
+			getAppleMusicDeveloperTokenUC,
+			setAppleMusicTokenUC,
+		),
+		accountMapper,
+		logger,
+	)
+
+	adminHandler := httpHandlers.NewAdminHandler(
+		usecases.NewAdminUseCases(
+			changeUserRoleUC,
+		),
+		adminMapper,
+		logger,
+	)
+
+	apiKeyHandler := httpHandlers.NewAPIKeyHandler(
+		usecases.NewAPIKeyUseCases(
+			createAPIKeyUC,
+			listAPIKeysUC,
+			updateAPIKeyUC,
+			deleteAPIKeyUC,
+		),
+		apiKeyMapper,
+		logger,
+	)
+
 	healthHandler := httpHandlers.NewHealthHandler(getStatusUC)
+	jwksHandler := httpHandlers.NewJWKSHandler(keyRing)
 
 	return &Container{
-		AuthHandler:   authHandler,
-		HealthHandler: healthHandler,
+		AuthHandler:    authHandler,
+		HealthHandler:  healthHandler,
+		SessionHandler: sessionHandler,
+		MFAHandler:     mfaHandler,
+		AccountHandler: accountHandler,
+		AdminHandler:   adminHandler,
+		APIKeyHandler:  apiKeyHandler,
+		JWKSHandler:    jwksHandler,
+		JWTMiddleware:  middleware.JWT(keyRing, revokedTokenRepo),
+		AuthMiddleware: middleware.JWTOrAPIKey(keyRing, revokedTokenRepo, authAdapters.NewAPIKeyAuthenticator(authenticateAPIKeyUC)),
+
+		ProviderCredentials: providerCredentials,
+		MusicServices:       musicServices,
+
+		CleanupExpiredRecords: cleanupExpiredRecordsUC,
+	}
+}
+
+func lockoutPolicy(cfg config.LockoutConfig) entities.LockoutPolicy {
+	return entities.LockoutPolicy{
+		FreeAttempts:     cfg.FreeAttempts,
+		BaseDelay:        cfg.BaseDelay,
+		MaxDelay:         cfg.MaxDelay,
+		LockoutThreshold: cfg.LockoutThreshold,
+		LockoutDuration:  cfg.LockoutDuration,
+		ResetAfter:       cfg.ResetAfter,
+	}
+}
+
+// loadTokenCipher builds the cipher for provider tokens and TOTP secrets. There
+// is no temporary fallback: a key lost on restart would lock every user with
+// two-factor authentication out of their account
+func loadTokenCipher(cfg *config.Config, logger *logger.Logger) *envelope.Cipher {
+	tokenCipher, err := envelope.Load(&cfg.TokenEncryption)
+	if err != nil {
+		logger.Sugar().Fatalf("Failed to load TOKEN_ENCRYPTION_KEYS, required to store provider tokens and TOTP secrets: %v", err)
 	}
+	return tokenCipher
 }
@@ -0,0 +1,34 @@
+package dtos
+
+import "time"
+
+type LinkedAccountResponse struct {
+	Provider    string    `json:"provider"`
+	LinkedAt    time.Time `json:"linkedAt"`
+	Scopes      []string  `json:"scopes"`
+	TokenHealth string    `json:"tokenHealth,omitempty"`
+}
+
+type ListLinkedAccountsResponse struct {
+	Accounts []LinkedAccountResponse `json:"accounts"`
+}
+
+type UnlinkAccountResponse struct {
+	Success         bool   `json:"success"`
+	Message         string `json:"message"`
+	RevokedUpstream bool   `json:"revokedUpstream"`
+}
+
+type AppleMusicDeveloperTokenResponse struct {
+	DeveloperToken string    `json:"developerToken"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+}
+
+type SetAppleMusicTokenRequest struct {
+	MusicUserToken string `json:"musicUserToken" validate:"required"`
+}
+
+type SetAppleMusicTokenResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,12 @@
+package dtos
+
+type ChangeUserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user support admin"`
+}
+
+type ChangeUserRoleResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	UserID  string `json:"userID"`
+	Role    string `json:"role"`
+}
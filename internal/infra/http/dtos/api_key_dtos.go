@@ -0,0 +1,38 @@
+package dtos
+
+import "time"
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type UpdateAPIKeyRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	// Key is only returned here; it is stored hashed
+	Key string `json:"key"`
+}
+
+type ListAPIKeysResponse struct {
+	Keys []APIKeyResponse `json:"keys"`
+}
+
+type DeleteAPIKeyResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
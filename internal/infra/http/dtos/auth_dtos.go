@@ -8,7 +8,26 @@ type RegisterRequest struct {
 }
 
 type RegisterResponse struct {
-	UserID  string `json:"userID"`
+	UserID                string `json:"userID"`
+	Message               string `json:"message"`
+	VerificationEmailSent bool   `json:"verificationEmailSent"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type VerifyEmailResponse struct {
+	UserID string `json:"userID"`
+	Email  string `json:"email"`
+}
+
+type ResendVerificationEmailRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResendVerificationEmailResponse struct {
+	Success bool   `json:"success"`
 	Message string `json:"message"`
 }
 
@@ -18,62 +37,106 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	AccessToken  string `json:"accessToken"`
+	AccessToken  string `json:"accessToken,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	UserID       string `json:"userID"`
+	MFARequired  bool   `json:"mfaRequired"`
+	MFAToken     string `json:"mfaToken,omitempty"` // redeem at /v1/auth/mfa/verify
+	// CSRFToken is set when the refresh token went to the session cookie
+	CSRFToken string `json:"csrfToken,omitempty"`
+}
+
+// RefreshTokenRequest may omit the refresh token when it was delivered in
+// the session cookie
+type RefreshTokenRequest struct {
 	RefreshToken string `json:"refreshToken"`
+}
+
+type RefreshTokenResponse struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken,omitempty"`
 	UserID       string `json:"userID"`
+	CSRFToken    string `json:"csrfToken,omitempty"`
 }
 
-type GoogleAuthURLResponse struct {
-	URL   string `json:"url"`
-	State string `json:"state"` // Server-generated state for OAuth flow
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
 }
 
-type GoogleCallbackRequest struct {
-	Code  string `json:"code" validate:"required"`
-	State string `json:"state" validate:"required"` // OAuth state parameter
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"newPassword" validate:"required,min=8,max=128"`
 }
 
-type GoogleCallbackResponse struct {
-	AccessToken               string `json:"accessToken"`
-	RefreshToken              string `json:"refreshToken"`
-	UserID                    string `json:"userID"`
-	IsNewUser                 bool   `json:"isNewUser"`
-	FrontendVerificationToken string `json:"frontendVerificationToken"`
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" validate:"required"`
+	NewPassword     string `json:"newPassword" validate:"required,min=8,max=128"`
 }
 
-type VerifyTokenRequest struct {
-	Token string `json:"token" validate:"required"`
+type PasswordResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
 }
 
-type VerifyTokenResponse struct {
-	Valid  bool   `json:"valid"`
-	UserID string `json:"userId,omitempty"`
+// LogoutRequest may omit the refresh token when it is in the session cookie
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type LogoutResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
 }
 
-type SpotifyAuthURLResponse struct {
+type OAuthAuthURLResponse struct {
 	URL   string `json:"url"`
 	State string `json:"state"` // Server-generated state for OAuth flow
 }
 
-type SpotifyCallbackRequest struct {
-	Code  string `json:"code" validate:"required"`
-	State string `json:"state" validate:"required"`
+// OAuthCallbackRequest is read from the query string, or from the form body
+// for providers that post the result back (Apple's response_mode=form_post)
+type OAuthCallbackRequest struct {
+	Provider string `param:"provider"`
+	Code     string `query:"code" form:"code"`
+	State    string `query:"state" form:"state"` // OAuth state parameter
+	// Error is set by the provider when the user cancels the authorization
+	Error string `query:"error" form:"error"`
+	// User is a JSON document with the user's name that Apple sends only on
+	// the first authorization
+	User string `form:"user"`
 }
 
-type SpotifyCallbackResponse struct {
-	AccessToken               string `json:"accessToken"`
-	RefreshToken              string `json:"refreshToken"`
+// OAuthCallbackResponse carries the one-time code to exchange at
+// /v1/oauth/verify; the session tokens are never part of the callback
+type OAuthCallbackResponse struct {
 	UserID                    string `json:"userID"`
 	IsNewUser                 bool   `json:"isNewUser"`
 	FrontendVerificationToken string `json:"frontendVerificationToken"`
 }
 
-type LinkSpotifyRequest struct {
+type VerifyTokenRequest struct {
+	Token string `json:"token" validate:"required"`
+	// CodeVerifier is required for flows started with a code_challenge
+	CodeVerifier string `json:"codeVerifier"`
+	// RefreshTokenCookie delivers the refresh token as an HttpOnly cookie
+	// instead of in the response body
+	RefreshTokenCookie bool `json:"refreshTokenCookie"`
+}
+
+type VerifyTokenResponse struct {
+	Valid        bool   `json:"valid"`
+	UserID       string `json:"userId,omitempty"`
+	AccessToken  string `json:"accessToken,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	CSRFToken    string `json:"csrfToken,omitempty"`
+}
+
+type LinkOAuthAccountRequest struct {
 	Code  string `json:"code" validate:"required"`
-	State string `json:"state"`
+	State string `json:"state" validate:"required"`
 }
 
-type LinkSpotifyResponse struct {
+type LinkOAuthAccountResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 }
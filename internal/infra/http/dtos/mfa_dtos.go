@@ -0,0 +1,36 @@
+package dtos
+
+type StartTOTPEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"otpauthUri"`
+}
+
+type ConfirmTOTPEnrollmentRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type ConfirmTOTPEnrollmentResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type DisableTOTPRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableTOTPResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type VerifyMFARequest struct {
+	MFAToken string `json:"mfaToken" validate:"required"`
+	Code     string `json:"code" validate:"required"` // TOTP or recovery code
+}
+
+type VerifyMFAResponse struct {
+	AccessToken            string `json:"accessToken"`
+	RefreshToken           string `json:"refreshToken,omitempty"`
+	UserID                 string `json:"userID"`
+	RemainingRecoveryCodes int    `json:"remainingRecoveryCodes"`
+	CSRFToken              string `json:"csrfToken,omitempty"`
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zandomed/sync-playlist-api/internal/infra/http/dtos"
+	"github.com/zandomed/sync-playlist-api/internal/infra/http/mappers"
+	"github.com/zandomed/sync-playlist-api/internal/usecases"
+	"github.com/zandomed/sync-playlist-api/pkg/logger"
+)
+
+type AccountHandler struct {
+	uc     *usecases.AccountUseCases
+	mapper *mappers.AccountMapper
+	logger *logger.Logger
+}
+
+func NewAccountHandler(
+	uc *usecases.AccountUseCases,
+	mapper *mappers.AccountMapper,
+	logger *logger.Logger,
+) *AccountHandler {
+	return &AccountHandler{
+		uc:     uc,
+		mapper: mapper,
+		logger: logger,
+	}
+}
+
+func (h *AccountHandler) ListLinkedAccounts(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
+	}
+
+	request := h.mapper.ToListLinkedAccountsRequest(claims)
+
+	response, err := h.uc.ListLinkedAccountsUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Errorf("Failed to list accounts for user %s: %v", claims.UserID, err)
+		return HandleUseCaseError(c, err)
+	}
+
+	return SendSuccess(c, http.StatusOK, h.mapper.ToListLinkedAccountsResponse(response))
+}
+
+func (h *AccountHandler) UnlinkAccount(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
+	}
+
+	request := h.mapper.ToUnlinkAccountRequest(claims, c.Param("provider"))
+
+	response, err := h.uc.UnlinkAccountUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Warnf("Failed to unlink %s account for user %s: %v", request.Provider, claims.UserID, err)
+		return HandleUseCaseError(c, err)
+	}
+
+	if !response.RevokedUpstream {
+		h.logger.Sugar().Warnf("%s tokens of user %s were not revoked upstream", request.Provider, claims.UserID)
+	}
+
+	h.logger.Sugar().Infof("%s account unlinked for user %s", request.Provider, claims.UserID)
+	return SendSuccess(c, http.StatusOK, h.mapper.ToUnlinkAccountResponse(response))
+}
+
+func (h *AccountHandler) GetAppleMusicDeveloperToken(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
+	}
+
+	response, err := h.uc.GetAppleMusicDeveloperTokenUseCase.Execute(c.Request().Context())
+	if err != nil {
+		h.logger.Sugar().Errorf("Failed to issue Apple Music developer token for user %s: %v", claims.UserID, err)
+		return HandleUseCaseError(c, err)
+	}
+
+	return SendSuccess(c, http.StatusOK, h.mapper.ToAppleMusicDeveloperTokenResponse(response))
+}
+
+func (h *AccountHandler) SetAppleMusicToken(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
+	}
+
+	var dto dtos.SetAppleMusicTokenRequest
+	if err := c.Bind(&dto); err != nil {
+		h.logger.Sugar().Warnf("Invalid request body: %v", err)
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Invalid request body")
+	}
+
+	if err := c.Validate(&dto); err != nil {
+		h.logger.Sugar().Warnf("Validation failed: %v", err)
+		return SendValidationError(c, err)
+	}
+
+	request := h.mapper.ToSetAppleMusicTokenRequest(&dto, claims)
+
+	response, err := h.uc.SetAppleMusicTokenUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Warnf("Failed to store Apple Music token for user %s: %v", claims.UserID, err)
+		return HandleUseCaseError(c, err)
+	}
+
+	h.logger.Sugar().Infof("Apple Music authorized for user %s", claims.UserID)
+	return SendSuccess(c, http.StatusOK, h.mapper.ToSetAppleMusicTokenResponse(response))
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zandomed/sync-playlist-api/internal/infra/http/dtos"
+	"github.com/zandomed/sync-playlist-api/internal/infra/http/mappers"
+	"github.com/zandomed/sync-playlist-api/internal/usecases"
+	"github.com/zandomed/sync-playlist-api/pkg/logger"
+)
+
+// AdminHandler serves the /v1/admin endpoints. Authorization is done by the
+// RequireRole and RequireScope middlewares on the routes
+type AdminHandler struct {
+	uc     *usecases.AdminUseCases
+	mapper *mappers.AdminMapper
+	logger *logger.Logger
+}
+
+func NewAdminHandler(
+	uc *usecases.AdminUseCases,
+	mapper *mappers.AdminMapper,
+	logger *logger.Logger,
+) *AdminHandler {
+	return &AdminHandler{
+		uc:     uc,
+		mapper: mapper,
+		logger: logger,
+	}
+}
+
+func (h *AdminHandler) ChangeUserRole(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
+	}
+
+	var dto dtos.ChangeUserRoleRequest
+	if err := c.Bind(&dto); err != nil {
+		h.logger.Sugar().Warnf("Invalid request body: %v", err)
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Invalid request body")
+	}
+
+	if err := c.Validate(&dto); err != nil {
+		h.logger.Sugar().Warnf("Validation failed: %v", err)
+		return SendValidationError(c, err)
+	}
+
+	request := h.mapper.ToChangeUserRoleRequest(&dto, claims, c.Param("id"))
+
+	response, err := h.uc.ChangeUserRoleUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Warnf("Role change of user %s by %s failed: %v", request.UserID, claims.UserID, err)
+		return HandleUseCaseError(c, err)
+	}
+
+	h.logger.Sugar().Infof("User %s set role of user %s to %s", claims.UserID, response.UserID, response.Role)
+	return SendSuccess(c, http.StatusOK, h.mapper.ToChangeUserRoleResponse(response))
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zandomed/sync-playlist-api/internal/infra/http/dtos"
+	"github.com/zandomed/sync-playlist-api/internal/infra/http/mappers"
+	"github.com/zandomed/sync-playlist-api/internal/usecases"
+	"github.com/zandomed/sync-playlist-api/pkg/logger"
+)
+
+type APIKeyHandler struct {
+	uc     *usecases.APIKeyUseCases
+	mapper *mappers.APIKeyMapper
+	logger *logger.Logger
+}
+
+func NewAPIKeyHandler(
+	uc *usecases.APIKeyUseCases,
+	mapper *mappers.APIKeyMapper,
+	logger *logger.Logger,
+) *APIKeyHandler {
+	return &APIKeyHandler{
+		uc:     uc,
+		mapper: mapper,
+		logger: logger,
+	}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
+	}
+
+	var dto dtos.CreateAPIKeyRequest
+	if err := c.Bind(&dto); err != nil {
+		h.logger.Sugar().Warnf("Invalid request body: %v", err)
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Invalid request body")
+	}
+
+	if err := c.Validate(&dto); err != nil {
+		h.logger.Sugar().Warnf("Validation failed: %v", err)
+		return SendValidationError(c, err)
+	}
+
+	request := h.mapper.ToCreateAPIKeyRequest(&dto, claims)
+
+	response, err := h.uc.CreateAPIKeyUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Warnf("Failed to create API key for user %s: %v", claims.UserID, err)
+		return HandleUseCaseError(c, err)
+	}
+
+	h.logger.Sugar().Infof("API key %s created for user %s", response.ID, claims.UserID)
+	return SendSuccess(c, http.StatusCreated, h.mapper.ToCreateAPIKeyResponse(response))
+}
+
+func (h *APIKeyHandler) ListAPIKeys(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
+	}
+
+	request := h.mapper.ToListAPIKeysRequest(claims)
+
+	response, err := h.uc.ListAPIKeysUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Errorf("Failed to list API keys for user %s: %v", claims.UserID, err)
+		return HandleUseCaseError(c, err)
+	}
+
+	return SendSuccess(c, http.StatusOK, h.mapper.ToListAPIKeysResponse(response))
+}
+
+func (h *APIKeyHandler) UpdateAPIKey(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
+	}
+
+	var dto dtos.UpdateAPIKeyRequest
+	if err := c.Bind(&dto); err != nil {
+		h.logger.Sugar().Warnf("Invalid request body: %v", err)
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Invalid request body")
+	}
+
+	if err := c.Validate(&dto); err != nil {
+		h.logger.Sugar().Warnf("Validation failed: %v", err)
+		return SendValidationError(c, err)
+	}
+
+	request := h.mapper.ToUpdateAPIKeyRequest(&dto, claims, c.Param("id"))
+
+	response, err := h.uc.UpdateAPIKeyUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Warnf("Failed to update API key %s for user %s: %v", request.KeyID, claims.UserID, err)
+		return HandleUseCaseError(c, err)
+	}
+
+	return SendSuccess(c, http.StatusOK, h.mapper.ToAPIKeyResponse(response))
+}
+
+func (h *APIKeyHandler) DeleteAPIKey(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
+	}
+
+	request := h.mapper.ToDeleteAPIKeyRequest(claims, c.Param("id"))
+
+	response, err := h.uc.DeleteAPIKeyUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Warnf("Failed to delete API key %s for user %s: %v", request.KeyID, claims.UserID, err)
+		return HandleUseCaseError(c, err)
+	}
+
+	h.logger.Sugar().Infof("API key %s deleted for user %s", request.KeyID, claims.UserID)
+	return SendSuccess(c, http.StatusOK, h.mapper.ToDeleteAPIKeyResponse(response))
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/labstack/echo/v4"
 	"github.com/zandomed/sync-playlist-api/internal/config"
@@ -10,6 +11,7 @@ import (
 	"github.com/zandomed/sync-playlist-api/internal/infra/http/mappers"
 	"github.com/zandomed/sync-playlist-api/internal/middleware"
 	"github.com/zandomed/sync-playlist-api/internal/usecases"
+	authUC "github.com/zandomed/sync-playlist-api/internal/usecases/auth"
 	"github.com/zandomed/sync-playlist-api/pkg/logger"
 )
 
@@ -54,6 +56,10 @@ func (h *AuthHandler) Register(c echo.Context) error {
 		return HandleUseCaseError(c, err)
 	}
 
+	if !response.VerificationEmailSent {
+		h.logger.Sugar().Warnf("Verification email could not be sent to user: %s", response.UserID)
+	}
+
 	h.logger.Sugar().Infof("User registered successfully: %s", response.UserID)
 	return SendSuccess(c, http.StatusCreated, h.mapper.ToRegisterResponse(response))
 }
@@ -70,7 +76,7 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return SendValidationError(c, err)
 	}
 
-	request := h.mapper.ToLoginUserRequest(&dto)
+	request := h.mapper.ToLoginUserRequest(&dto, c.Request().UserAgent(), c.RealIP())
 
 	response, err := h.uc.LoginUserPassUseCase.Execute(c.Request().Context(), *request)
 	if err != nil {
@@ -78,75 +84,169 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return HandleUseCaseError(c, err)
 	}
 
+	if response.MFARequired {
+		h.logger.Sugar().Infof("Second factor required for user: %s", response.UserID)
+		return SendSuccess(c, http.StatusOK, h.mapper.ToLoginResponse(response))
+	}
+
+	respObj := h.mapper.ToLoginResponse(response)
+	if h.cfg.UsesCookieSessions() {
+		csrfToken, err := setSessionCookies(c, h.cfg, respObj.RefreshToken)
+		if err != nil {
+			h.logger.Sugar().Errorf("Failed to set session cookies: %v", err)
+			return SendError(c, http.StatusInternalServerError, "internal_error", "Failed to start the session")
+		}
+		respObj.RefreshToken = ""
+		respObj.CSRFToken = csrfToken
+	}
+
 	h.logger.Sugar().Infof("User logged in successfully: %s", response.UserID)
-	return SendSuccess(c, http.StatusOK, h.mapper.ToLoginResponse(response))
+	return SendSuccess(c, http.StatusOK, respObj)
 }
 
-func (h *AuthHandler) GoogleAuth(c echo.Context) error {
-	response, err := h.uc.GetUrlGoogleUseCase.Execute(c.Request().Context())
+// RefreshToken reads the refresh token from the body, or from the session
+// cookie, in which case the rotated token is set back as a cookie
+func (h *AuthHandler) RefreshToken(c echo.Context) error {
+	var dto dtos.RefreshTokenRequest
+	if err := c.Bind(&dto); err != nil {
+		h.logger.Sugar().Warnf("Invalid request body: %v", err)
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Invalid request body")
+	}
+
+	fromCookie := false
+	if dto.RefreshToken == "" {
+		dto.RefreshToken = refreshTokenFromCookie(c)
+		fromCookie = dto.RefreshToken != ""
+	}
+
+	if dto.RefreshToken == "" {
+		h.logger.Sugar().Warn("Refresh token missing from body and cookie")
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Missing refresh token")
+	}
+
+	request := h.mapper.ToRefreshTokenRequest(&dto, c.Request().UserAgent(), c.RealIP())
+
+	response, err := h.uc.RefreshTokenUseCase.Execute(c.Request().Context(), *request)
 	if err != nil {
-		h.logger.Sugar().Errorf("Failed to generate Google auth URL: %v", err)
+		h.logger.Sugar().Warnf("Token refresh failed: %v", err)
 		return HandleUseCaseError(c, err)
 	}
 
-	h.logger.Sugar().Infof("Generated Google auth URL with state: %s", response.State)
-
-	// Check if client accepts JSON
-	acceptHeader := c.Request().Header.Get("Accept")
-	if acceptHeader == string(ContentTypeApplicationJson) || c.Request().Header.Get("Content-Type") == string(ContentTypeApplicationJson) {
-		return SendSuccess(c, http.StatusOK, h.mapper.ToGoogleAuthURLResponse(response))
+	respObj := h.mapper.ToRefreshTokenResponse(response)
+	if fromCookie || h.cfg.UsesCookieSessions() {
+		csrfToken, err := setSessionCookies(c, h.cfg, respObj.RefreshToken)
+		if err != nil {
+			h.logger.Sugar().Errorf("Failed to set session cookies: %v", err)
+			return SendError(c, http.StatusInternalServerError, "internal_error", "Failed to refresh the session")
+		}
+		respObj.RefreshToken = ""
+		respObj.CSRFToken = csrfToken
 	}
-	return c.Redirect(http.StatusFound, response.URL)
+
+	h.logger.Sugar().Infof("Tokens refreshed for user: %s", response.UserID)
+	return SendSuccess(c, http.StatusOK, respObj)
 }
 
-func (h *AuthHandler) GoogleCallback(c echo.Context) error {
-	code := c.QueryParam("code")
-	state := c.QueryParam("state")
+func (h *AuthHandler) VerifyEmail(c echo.Context) error {
+	var dto dtos.VerifyEmailRequest
+	if err := c.Bind(&dto); err != nil {
+		h.logger.Sugar().Warnf("Invalid request body: %v", err)
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Invalid request body")
+	}
+
+	if err := c.Validate(&dto); err != nil {
+		h.logger.Sugar().Warnf("Validation failed: %v", err)
+		return SendValidationError(c, err)
+	}
+
+	request := h.mapper.ToVerifyEmailRequest(&dto)
 
-	if code == "" {
-		h.logger.Sugar().Warn("Missing authorization code in Google callback")
-		return SendError(c, http.StatusBadRequest, "invalid_request", "Missing authorization code")
+	response, err := h.uc.VerifyEmailUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Warnf("Email verification failed: %v", err)
+		return HandleUseCaseError(c, err)
 	}
 
-	if state == "" {
-		h.logger.Sugar().Warn("Missing state parameter in Google callback")
-		return SendError(c, http.StatusBadRequest, "invalid_request", "Missing state parameter")
+	h.logger.Sugar().Infof("Email verified for user: %s", response.UserID)
+	return SendSuccess(c, http.StatusOK, h.mapper.ToVerifyEmailResponse(response))
+}
+
+func (h *AuthHandler) ResendVerificationEmail(c echo.Context) error {
+	var dto dtos.ResendVerificationEmailRequest
+	if err := c.Bind(&dto); err != nil {
+		h.logger.Sugar().Warnf("Invalid request body: %v", err)
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Invalid request body")
 	}
 
-	dto := dtos.GoogleCallbackRequest{
-		Code:  code,
-		State: state,
+	if err := c.Validate(&dto); err != nil {
+		h.logger.Sugar().Warnf("Validation failed: %v", err)
+		return SendValidationError(c, err)
 	}
 
-	request := h.mapper.ToGoogleCallbackRequest(&dto)
+	request := h.mapper.ToResendVerificationEmailRequest(&dto, c.RealIP())
 
-	response, err := h.uc.LoginGoogleUseCase.Execute(c.Request().Context(), *request)
+	response, err := h.uc.ResendVerificationEmailUseCase.Execute(c.Request().Context(), *request)
 	if err != nil {
-		h.logger.Sugar().Errorf("Google callback failed: %v", err)
+		h.logger.Sugar().Errorf("Resending verification email failed: %v", err)
 		return HandleUseCaseError(c, err)
 	}
 
-	if response.IsNewUser {
-		h.logger.Sugar().Infof("New user created via Google OAuth: %s", response.UserID)
-	} else {
-		h.logger.Sugar().Infof("User logged in via Google OAuth: %s", response.UserID)
+	return SendSuccess(c, http.StatusOK, h.mapper.ToResendVerificationEmailResponse(response))
+}
+
+func (h *AuthHandler) ForgotPassword(c echo.Context) error {
+	var dto dtos.ForgotPasswordRequest
+	if err := c.Bind(&dto); err != nil {
+		h.logger.Sugar().Warnf("Invalid request body: %v", err)
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Invalid request body")
 	}
 
-	respObj := h.mapper.ToGoogleCallbackResponse(response)
+	if err := c.Validate(&dto); err != nil {
+		h.logger.Sugar().Warnf("Validation failed: %v", err)
+		return SendValidationError(c, err)
+	}
 
-	acceptHeader := c.Request().Header.Get("Accept")
-	if acceptHeader == string(ContentTypeApplicationJson) || c.Request().Header.Get("Content-Type") == string(ContentTypeApplicationJson) {
-		return SendSuccess(c, http.StatusOK, respObj)
+	request := h.mapper.ToForgotPasswordRequest(&dto, c.RealIP())
+
+	response, err := h.uc.ForgotPasswordUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Errorf("Forgot password failed: %v", err)
+		return HandleUseCaseError(c, err)
 	}
 
-	redirectURL := fmt.Sprintf("%s/api/auth/oauth/callback?access_token=%s&refresh_token=%s&state=%s",
-		h.cfg.Server.FrontendURL, respObj.AccessToken, respObj.RefreshToken, respObj.FrontendVerificationToken)
+	return SendSuccess(c, http.StatusOK, h.mapper.ToForgotPasswordResponse(response))
+}
 
-	return c.Redirect(http.StatusFound, redirectURL)
+func (h *AuthHandler) ResetPassword(c echo.Context) error {
+	var dto dtos.ResetPasswordRequest
+	if err := c.Bind(&dto); err != nil {
+		h.logger.Sugar().Warnf("Invalid request body: %v", err)
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Invalid request body")
+	}
+
+	if err := c.Validate(&dto); err != nil {
+		h.logger.Sugar().Warnf("Validation failed: %v", err)
+		return SendValidationError(c, err)
+	}
+
+	request := h.mapper.ToResetPasswordRequest(&dto)
+
+	response, err := h.uc.ResetPasswordUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Warnf("Password reset failed: %v", err)
+		return HandleUseCaseError(c, err)
+	}
+
+	return SendSuccess(c, http.StatusOK, h.mapper.ToResetPasswordResponse(response))
 }
 
-func (h *AuthHandler) VerifyToken(c echo.Context) error {
-	var dto dtos.VerifyTokenRequest
+func (h *AuthHandler) ChangePassword(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
+	}
+
+	var dto dtos.ChangePasswordRequest
 	if err := c.Bind(&dto); err != nil {
 		h.logger.Sugar().Warnf("Invalid request body: %v", err)
 		return SendError(c, http.StatusBadRequest, "invalid_request", "Invalid request body")
@@ -157,108 +257,300 @@ func (h *AuthHandler) VerifyToken(c echo.Context) error {
 		return SendValidationError(c, err)
 	}
 
-	request := h.mapper.ToVerifyTokenRequest(&dto)
+	request := h.mapper.ToChangePasswordRequest(&dto, claims)
 
-	response, err := h.uc.VerifyTokenUseCase.Execute(c.Request().Context(), *request)
+	response, err := h.uc.ChangePasswordUseCase.Execute(c.Request().Context(), *request)
 	if err != nil {
-		h.logger.Sugar().Errorf("Token verification failed: %v", err)
+		h.logger.Sugar().Warnf("Password change failed for user %s: %v", claims.UserID, err)
 		return HandleUseCaseError(c, err)
 	}
 
-	if !response.Valid {
-		h.logger.Sugar().Warn("Invalid verification token provided")
-		return SendError(c, http.StatusUnauthorized, "invalid_token", "Verification token is invalid or expired")
+	h.logger.Sugar().Infof("Password changed for user: %s", claims.UserID)
+	return SendSuccess(c, http.StatusOK, h.mapper.ToChangePasswordResponse(response))
+}
+
+func (h *AuthHandler) Logout(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
 	}
 
-	h.logger.Sugar().Infof("Token verified successfully for user: %s", response.UserID)
-	return SendSuccess(c, http.StatusOK, h.mapper.ToVerifyTokenResponse(response))
+	var dto dtos.LogoutRequest
+	if err := c.Bind(&dto); err != nil {
+		h.logger.Sugar().Warnf("Invalid request body: %v", err)
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Invalid request body")
+	}
+
+	if dto.RefreshToken == "" {
+		dto.RefreshToken = refreshTokenFromCookie(c)
+	}
+
+	if dto.RefreshToken == "" {
+		h.logger.Sugar().Warn("Refresh token missing from body and cookie")
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Missing refresh token")
+	}
+
+	request := h.mapper.ToLogoutRequest(&dto, claims)
+
+	response, err := h.uc.LogoutUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Errorf("Logout failed for user %s: %v", claims.UserID, err)
+		return HandleUseCaseError(c, err)
+	}
+
+	clearSessionCookies(c)
+
+	h.logger.Sugar().Infof("User logged out: %s", claims.UserID)
+	return SendSuccess(c, http.StatusOK, h.mapper.ToLogoutResponse(response))
 }
 
-func (h *AuthHandler) SpotifyAuth(c echo.Context) error {
+func (h *AuthHandler) LogoutAll(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
+	}
 
-	// Check if this is a link request (user is authenticated)
-	authHeader := c.Request().Header.Get("Authorization")
-	if authHeader != "" {
-		// This is a link request, use link use case
-		response, err := h.uc.GetUrlSpotifyUseCase.Execute(c.Request().Context())
-		if err != nil {
-			h.logger.Sugar().Errorf("Failed to generate Spotify auth URL for linking: %v", err)
-			return HandleUseCaseError(c, err)
-		}
-		h.logger.Sugar().Infof("Generated Spotify auth URL for linking")
+	request := h.mapper.ToLogoutAllRequest(claims)
 
-		// Check if client accepts JSON
-		acceptHeader := c.Request().Header.Get("Accept")
-		if acceptHeader == string(ContentTypeApplicationJson) || c.Request().Header.Get("Content-Type") == string(ContentTypeApplicationJson) {
-			return SendSuccess(c, http.StatusOK, h.mapper.ToSpotifyAuthURLResponse(response))
-		}
-		return c.Redirect(http.StatusFound, response.URL)
+	response, err := h.uc.LogoutAllUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Errorf("Logout from all sessions failed for user %s: %v", claims.UserID, err)
+		return HandleUseCaseError(c, err)
 	}
 
-	// Regular login flow
-	response, err := h.uc.GetUrlSpotifyUseCase.Execute(c.Request().Context())
+	clearSessionCookies(c)
+
+	h.logger.Sugar().Infof("User logged out from all sessions: %s", claims.UserID)
+	return SendSuccess(c, http.StatusOK, h.mapper.ToLogoutResponse(response))
+}
+
+// OAuthAuthorize starts the authorization code flow of the :provider path param.
+// Native and mobile apps, which cannot count on the system browser keeping the
+// nonce cookie, pass a S256 code_challenge and redeem the one-time code of the
+// callback redirect with its verifier
+func (h *AuthHandler) OAuthAuthorize(c echo.Context) error {
+	provider := c.Param("provider")
+	request := h.mapper.ToOAuthURLRequest(provider, c.QueryParam("code_challenge"))
+
+	response, err := h.uc.GetOAuthURLUseCase.Execute(c.Request().Context(), *request)
 	if err != nil {
-		h.logger.Sugar().Errorf("Failed to generate Spotify auth URL: %v", err)
+		h.logger.Sugar().Errorf("Failed to generate %s auth URL: %v", provider, err)
 		return HandleUseCaseError(c, err)
 	}
 
-	h.logger.Sugar().Infof("Generated Spotify auth URL")
+	h.logger.Sugar().Infof("Generated %s auth URL with state: %s", provider, response.State)
+
+	// JSON clients must make this request with credentials so the browser
+	// keeps the cookie for the callback
+	setOAuthNonceCookie(c, response.BrowserNonce, response.ExpiresAt)
 
 	// Check if client accepts JSON
 	acceptHeader := c.Request().Header.Get("Accept")
 	if acceptHeader == string(ContentTypeApplicationJson) || c.Request().Header.Get("Content-Type") == string(ContentTypeApplicationJson) {
-		return SendSuccess(c, http.StatusOK, h.mapper.ToSpotifyAuthURLResponse(response))
+		return SendSuccess(c, http.StatusOK, h.mapper.ToOAuthAuthURLResponse(response))
 	}
 	return c.Redirect(http.StatusFound, response.URL)
 }
 
-func (h *AuthHandler) SpotifyCallback(c echo.Context) error {
-	code := c.QueryParam("code")
-	state := c.QueryParam("state")
+// OAuthCallback completes the flow. Most providers redirect back with a GET,
+// Apple posts a form instead
+func (h *AuthHandler) OAuthCallback(c echo.Context) error {
+	var dto dtos.OAuthCallbackRequest
+	if err := c.Bind(&dto); err != nil {
+		h.logger.Sugar().Warnf("Invalid OAuth callback: %v", err)
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Invalid request body")
+	}
 
-	if code == "" {
-		h.logger.Sugar().Warn("Missing authorization code in Spotify callback")
-		return SendError(c, http.StatusBadRequest, "invalid_request", "Missing authorization code")
+	if dto.Error != "" {
+		h.logger.Sugar().Warnf("%s authorization failed: %s", dto.Provider, dto.Error)
+		return SendError(c, http.StatusBadRequest, "oauth_authorization_failed", "Authorization was not completed")
 	}
 
-	if state == "" {
-		h.logger.Sugar().Warn("Missing state parameter in Google callback")
-		return SendError(c, http.StatusBadRequest, "invalid_request", "Missing state parameter")
+	if dto.Code == "" {
+		h.logger.Sugar().Warnf("Missing authorization code in %s callback", dto.Provider)
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Missing authorization code")
 	}
 
-	// Regular login flow
-	dto := dtos.SpotifyCallbackRequest{
-		Code:  code,
-		State: state,
+	if dto.State == "" {
+		h.logger.Sugar().Warnf("Missing state parameter in %s callback", dto.Provider)
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Missing state parameter")
 	}
 
-	request := h.mapper.ToSpotifyCallbackRequest(&dto)
+	request := h.mapper.ToOAuthCallbackRequest(&dto, takeOAuthNonceCookie(c))
 
-	response, err := h.uc.LoginSpotifyUseCase.Execute(c.Request().Context(), *request)
+	response, err := h.uc.LoginOAuthUseCase.Execute(c.Request().Context(), *request)
 	if err != nil {
-		h.logger.Sugar().Errorf("Spotify callback failed: %v", err)
+		h.logger.Sugar().Errorf("%s callback failed: %v", dto.Provider, err)
 		return HandleUseCaseError(c, err)
 	}
 
+	if response.Link != nil {
+		return h.sendOAuthLinkResult(c, dto.Provider, response)
+	}
+
+	if response.LinkRequired {
+		return h.sendOAuthLinkRequired(c, dto.Provider)
+	}
+
 	if response.IsNewUser {
-		h.logger.Sugar().Infof("New user created via Spotify OAuth: %s", response.UserID)
+		h.logger.Sugar().Infof("New user created via %s OAuth: %s", dto.Provider, response.UserID)
 	} else {
-		h.logger.Sugar().Infof("User logged in via Spotify OAuth: %s", response.UserID)
+		h.logger.Sugar().Infof("User logged in via %s OAuth: %s", dto.Provider, response.UserID)
 	}
 
-	respObj := h.mapper.ToSpotifyCallbackResponse(response)
+	respObj := h.mapper.ToOAuthCallbackResponse(response)
 
 	acceptHeader := c.Request().Header.Get("Accept")
 	if acceptHeader == string(ContentTypeApplicationJson) || c.Request().Header.Get("Content-Type") == string(ContentTypeApplicationJson) {
 		return SendSuccess(c, http.StatusOK, respObj)
 	}
 
-	redirectURL := fmt.Sprintf("%s/api/auth/oauth/callback?access_token=%s&refresh_token=%s&state=%s",
-		h.cfg.Server.FrontendURL, respObj.AccessToken, respObj.RefreshToken, respObj.FrontendVerificationToken)
+	// Only the one-time code travels in the URL, where it can end up in the
+	// browser history or Referer headers; the frontend exchanges it at
+	// /v1/oauth/verify for the tokens
+	redirectURL := fmt.Sprintf("%s/api/auth/oauth/callback?code=%s",
+		h.cfg.Server.FrontendURL, url.QueryEscape(respObj.FrontendVerificationToken))
+
+	// 303 makes the browser follow the redirect with a GET after a form post
+	if c.Request().Method == http.MethodPost {
+		return c.Redirect(http.StatusSeeOther, redirectURL)
+	}
+	return c.Redirect(http.StatusFound, redirectURL)
+}
+
+// sendOAuthLinkResult answers a callback of a flow started from OAuthLink;
+// no tokens are issued because the user is already logged in
+func (h *AuthHandler) sendOAuthLinkResult(c echo.Context, provider string, response *authUC.LoginOAuthCallbackResponse) error {
+	h.logger.Sugar().Infof("%s account linked to user: %s", provider, response.UserID)
+
+	acceptHeader := c.Request().Header.Get("Accept")
+	if acceptHeader == string(ContentTypeApplicationJson) || c.Request().Header.Get("Content-Type") == string(ContentTypeApplicationJson) {
+		return SendSuccess(c, http.StatusOK, h.mapper.ToLinkOAuthAccountResponse(response.Link))
+	}
+
+	redirectURL := fmt.Sprintf("%s/api/auth/oauth/callback?linked=%s", h.cfg.Server.FrontendURL, url.QueryEscape(provider))
 
+	if c.Request().Method == http.MethodPost {
+		return c.Redirect(http.StatusSeeOther, redirectURL)
+	}
 	return c.Redirect(http.StatusFound, redirectURL)
 }
 
+// sendOAuthLinkRequired tells the user that an account already uses the
+// provider's email; they have to sign in with it and link the provider
+func (h *AuthHandler) sendOAuthLinkRequired(c echo.Context, provider string) error {
+	h.logger.Sugar().Infof("%s login requires linking to an existing account", provider)
+
+	acceptHeader := c.Request().Header.Get("Accept")
+	if acceptHeader == string(ContentTypeApplicationJson) || c.Request().Header.Get("Content-Type") == string(ContentTypeApplicationJson) {
+		return SendError(c, http.StatusConflict, "link_required", "An account with this email already exists. Sign in and link the provider from your account")
+	}
+
+	redirectURL := fmt.Sprintf("%s/api/auth/oauth/callback?error=link_required&provider=%s", h.cfg.Server.FrontendURL, url.QueryEscape(provider))
+
+	if c.Request().Method == http.MethodPost {
+		return c.Redirect(http.StatusSeeOther, redirectURL)
+	}
+	return c.Redirect(http.StatusFound, redirectURL)
+}
+
+// OAuthLink starts a flow that links the :provider account to the logged-in
+// user. The URL is always returned as JSON: the request carries the access
+// token, so it is made by the frontend rather than by a browser navigation
+func (h *AuthHandler) OAuthLink(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
+	}
+
+	provider := c.Param("provider")
+	request := h.mapper.ToOAuthLinkURLRequest(provider, claims)
+
+	response, err := h.uc.GetOAuthURLUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Errorf("Failed to generate %s link URL for user %s: %v", provider, claims.UserID, err)
+		return HandleUseCaseError(c, err)
+	}
+
+	// Used when the provider redirects to OAuthCallback; clients that post
+	// the code to LinkOAuthAccount are bound by their access token instead
+	setOAuthNonceCookie(c, response.BrowserNonce, response.ExpiresAt)
+	return SendSuccess(c, http.StatusOK, h.mapper.ToOAuthAuthURLResponse(response))
+}
+
+// LinkOAuthAccount completes a link flow for clients that handle the provider
+// callback themselves and post the code and state back
+func (h *AuthHandler) LinkOAuthAccount(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
+	}
+
+	var dto dtos.LinkOAuthAccountRequest
+	if err := c.Bind(&dto); err != nil {
+		h.logger.Sugar().Warnf("Invalid request body: %v", err)
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Invalid request body")
+	}
+
+	if err := c.Validate(&dto); err != nil {
+		h.logger.Sugar().Warnf("Validation failed: %v", err)
+		return SendValidationError(c, err)
+	}
+
+	provider := c.Param("provider")
+	request := h.mapper.ToLinkOAuthAccountRequest(&dto, claims.UserID.String(), provider)
+
+	response, err := h.uc.LinkOAuthUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Warnf("Failed to link %s account for user %s: %v", provider, claims.UserID, err)
+		return HandleUseCaseError(c, err)
+	}
+
+	h.logger.Sugar().Infof("%s account linked to user: %s", provider, claims.UserID)
+	return SendSuccess(c, http.StatusOK, h.mapper.ToLinkOAuthAccountResponse(response))
+}
+
+// VerifyToken exchanges the one-time code from the OAuth redirect for a session
+func (h *AuthHandler) VerifyToken(c echo.Context) error {
+	var dto dtos.VerifyTokenRequest
+	if err := c.Bind(&dto); err != nil {
+		h.logger.Sugar().Warnf("Invalid request body: %v", err)
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Invalid request body")
+	}
+
+	if err := c.Validate(&dto); err != nil {
+		h.logger.Sugar().Warnf("Validation failed: %v", err)
+		return SendValidationError(c, err)
+	}
+
+	request := h.mapper.ToVerifyTokenRequest(&dto, c.Request().UserAgent(), c.RealIP())
+
+	response, err := h.uc.VerifyTokenUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Errorf("Token verification failed: %v", err)
+		return HandleUseCaseError(c, err)
+	}
+
+	if !response.Valid {
+		h.logger.Sugar().Warn("Invalid verification token provided")
+		return SendError(c, http.StatusUnauthorized, "invalid_token", "Verification token is invalid or expired")
+	}
+
+	useCookie := dto.RefreshTokenCookie || h.cfg.UsesCookieSessions()
+	respObj := h.mapper.ToVerifyTokenResponse(response, useCookie)
+	if useCookie {
+		csrfToken, err := setSessionCookies(c, h.cfg, response.RefreshToken)
+		if err != nil {
+			h.logger.Sugar().Errorf("Failed to set session cookies: %v", err)
+			return SendError(c, http.StatusInternalServerError, "internal_error", "Failed to start the session")
+		}
+		respObj.CSRFToken = csrfToken
+	}
+
+	h.logger.Sugar().Infof("Token verified successfully for user: %s", response.UserID)
+	return SendSuccess(c, http.StatusOK, respObj)
+}
+
 func GetUserFromJWT(c echo.Context) (*middleware.Claims, error) {
 	return middleware.GetUserFromContext(c)
 }
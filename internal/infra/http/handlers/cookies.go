@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zandomed/sync-playlist-api/internal/config"
+	"github.com/zandomed/sync-playlist-api/internal/middleware"
+)
+
+// oauthNonceCookieName holds the nonce that binds an OAuth state to the
+// browser that started the flow
+const oauthNonceCookieName = "__Host-oauth_nonce"
+
+// setSessionCookies stores the refresh token where scripts cannot read it,
+// next to the CSRF token the client echoes in the X-CSRF-Token header. The
+// CSRF token is returned so it can also go in the body, for frontends served
+// from another host that cannot read the API cookies. __Host- cookies are
+// always Secure; browsers accept them over plain http on localhost
+func setSessionCookies(c echo.Context, cfg *config.Config, refreshToken string) (string, error) {
+	csrfToken, err := middleware.NewCSRFToken()
+	if err != nil {
+		return "", err
+	}
+
+	maxAge := int(cfg.JWT.RefreshExpirationTime.Seconds())
+	c.SetCookie(&http.Cookie{
+		Name:     middleware.SessionCookieName,
+		Value:    refreshToken,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	c.SetCookie(&http.Cookie{
+		Name:     middleware.CSRFCookieName,
+		Value:    csrfToken,
+		Path:     "/",
+		MaxAge:   maxAge,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return csrfToken, nil
+}
+
+// clearSessionCookies removes the cookies set by setSessionCookies
+func clearSessionCookies(c echo.Context) {
+	for _, name := range []string{middleware.SessionCookieName, middleware.CSRFCookieName} {
+		c.SetCookie(&http.Cookie{
+			Name:     name,
+			Path:     "/",
+			MaxAge:   -1,
+			Secure:   true,
+			SameSite: http.SameSiteStrictMode,
+		})
+	}
+}
+
+// refreshTokenFromCookie returns the refresh token cookie, or "" without one
+func refreshTokenFromCookie(c echo.Context) string {
+	cookie, err := c.Cookie(middleware.SessionCookieName)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// setOAuthNonceCookie hands the browser starting an OAuth flow the nonce the
+// callback must present. SameSite=None because Apple posts its callback from
+// appleid.apple.com; the nonce alone grants nothing without the state
+func setOAuthNonceCookie(c echo.Context, nonce string, expiresAt time.Time) {
+	c.SetCookie(&http.Cookie{
+		Name:     oauthNonceCookieName,
+		Value:    nonce,
+		Path:     "/",
+		MaxAge:   int(time.Until(expiresAt).Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+}
+
+// takeOAuthNonceCookie returns the nonce cookie, or "" without one, and
+// removes it: each nonce serves a single callback
+func takeOAuthNonceCookie(c echo.Context) string {
+	cookie, err := c.Cookie(oauthNonceCookieName)
+	if err != nil {
+		return ""
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     oauthNonceCookieName,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+	return cookie.Value
+}
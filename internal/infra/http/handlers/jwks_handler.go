@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zandomed/sync-playlist-api/pkg/jwtkeys"
+)
+
+type JWKSHandler struct {
+	keyRing *jwtkeys.KeyRing
+}
+
+func NewJWKSHandler(keyRing *jwtkeys.KeyRing) *JWKSHandler {
+	return &JWKSHandler{
+		keyRing: keyRing,
+	}
+}
+
+// GetKeys publishes the public keys that verify our access tokens
+func (h *JWKSHandler) GetKeys(c echo.Context) error {
+	// Short cache so key rotations propagate quickly to verifiers
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, h.keyRing.JWKS())
+}
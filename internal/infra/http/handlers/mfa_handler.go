@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zandomed/sync-playlist-api/internal/config"
+	"github.com/zandomed/sync-playlist-api/internal/infra/http/dtos"
+	"github.com/zandomed/sync-playlist-api/internal/infra/http/mappers"
+	"github.com/zandomed/sync-playlist-api/internal/usecases"
+	"github.com/zandomed/sync-playlist-api/pkg/logger"
+)
+
+type MFAHandler struct {
+	uc     *usecases.MFAUseCases
+	mapper *mappers.MFAMapper
+	cfg    *config.Config
+	logger *logger.Logger
+}
+
+func NewMFAHandler(
+	uc *usecases.MFAUseCases,
+	mapper *mappers.MFAMapper,
+	cfg *config.Config,
+	logger *logger.Logger,
+) *MFAHandler {
+	return &MFAHandler{
+		uc:     uc,
+		mapper: mapper,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (h *MFAHandler) StartTOTPEnrollment(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
+	}
+
+	request := h.mapper.ToStartTOTPEnrollmentRequest(claims)
+
+	response, err := h.uc.StartTOTPEnrollmentUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Warnf("Failed to start TOTP enrollment for user %s: %v", claims.UserID, err)
+		return HandleUseCaseError(c, err)
+	}
+
+	return SendSuccess(c, http.StatusOK, h.mapper.ToStartTOTPEnrollmentResponse(response))
+}
+
+func (h *MFAHandler) ConfirmTOTPEnrollment(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
+	}
+
+	var dto dtos.ConfirmTOTPEnrollmentRequest
+	if err := c.Bind(&dto); err != nil {
+		h.logger.Sugar().Warnf("Invalid request body: %v", err)
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Invalid request body")
+	}
+
+	if err := c.Validate(&dto); err != nil {
+		h.logger.Sugar().Warnf("Validation failed: %v", err)
+		return SendValidationError(c, err)
+	}
+
+	request := h.mapper.ToConfirmTOTPEnrollmentRequest(&dto, claims)
+
+	response, err := h.uc.ConfirmTOTPEnrollmentUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Warnf("Failed to confirm TOTP enrollment for user %s: %v", claims.UserID, err)
+		return HandleUseCaseError(c, err)
+	}
+
+	h.logger.Sugar().Infof("Two-factor authentication enabled for user %s", claims.UserID)
+	return SendSuccess(c, http.StatusOK, h.mapper.ToConfirmTOTPEnrollmentResponse(response))
+}
+
+func (h *MFAHandler) DisableTOTP(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
+	}
+
+	var dto dtos.DisableTOTPRequest
+	if err := c.Bind(&dto); err != nil {
+		h.logger.Sugar().Warnf("Invalid request body: %v", err)
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Invalid request body")
+	}
+
+	if err := c.Validate(&dto); err != nil {
+		h.logger.Sugar().Warnf("Validation failed: %v", err)
+		return SendValidationError(c, err)
+	}
+
+	request := h.mapper.ToDisableTOTPRequest(&dto, claims)
+
+	response, err := h.uc.DisableTOTPUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Warnf("Failed to disable TOTP for user %s: %v", claims.UserID, err)
+		return HandleUseCaseError(c, err)
+	}
+
+	h.logger.Sugar().Infof("Two-factor authentication disabled for user %s", claims.UserID)
+	return SendSuccess(c, http.StatusOK, h.mapper.ToDisableTOTPResponse(response))
+}
+
+func (h *MFAHandler) VerifyMFA(c echo.Context) error {
+	var dto dtos.VerifyMFARequest
+	if err := c.Bind(&dto); err != nil {
+		h.logger.Sugar().Warnf("Invalid request body: %v", err)
+		return SendError(c, http.StatusBadRequest, "invalid_request", "Invalid request body")
+	}
+
+	if err := c.Validate(&dto); err != nil {
+		h.logger.Sugar().Warnf("Validation failed: %v", err)
+		return SendValidationError(c, err)
+	}
+
+	request := h.mapper.ToVerifyMFARequest(&dto, c.Request().UserAgent(), c.RealIP())
+
+	response, err := h.uc.VerifyMFAUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Warnf("MFA verification failed: %v", err)
+		return HandleUseCaseError(c, err)
+	}
+
+	respObj := h.mapper.ToVerifyMFAResponse(response)
+	if h.cfg.UsesCookieSessions() {
+		csrfToken, err := setSessionCookies(c, h.cfg, respObj.RefreshToken)
+		if err != nil {
+			h.logger.Sugar().Errorf("Failed to set session cookies: %v", err)
+			return SendError(c, http.StatusInternalServerError, "internal_error", "Failed to start the session")
+		}
+		respObj.RefreshToken = ""
+		respObj.CSRFToken = csrfToken
+	}
+
+	h.logger.Sugar().Infof("User logged in with second factor: %s", response.UserID)
+	return SendSuccess(c, http.StatusOK, respObj)
+}
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
@@ -44,6 +46,11 @@ func HandleUseCaseError(c echo.Context, err error) error {
 	case *errors.DomainError:
 		return SendError(c, http.StatusBadRequest, e.Code(), e.Message())
 	case *errors.AuthenticationError:
+		if e.RetryAfter() > 0 {
+			retryAfter := int(math.Ceil(e.RetryAfter().Seconds()))
+			c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			return SendError(c, http.StatusTooManyRequests, e.Code(), e.Message())
+		}
 		return SendError(c, http.StatusUnauthorized, e.Code(), e.Message())
 	case *errors.ValidationError:
 		return SendError(c, http.StatusBadRequest, e.Code(), e.Message())
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zandomed/sync-playlist-api/internal/infra/http/mappers"
+	"github.com/zandomed/sync-playlist-api/internal/usecases"
+	"github.com/zandomed/sync-playlist-api/pkg/logger"
+)
+
+type SessionHandler struct {
+	uc     *usecases.SessionUseCases
+	mapper *mappers.SessionMapper
+	logger *logger.Logger
+}
+
+func NewSessionHandler(
+	uc *usecases.SessionUseCases,
+	mapper *mappers.SessionMapper,
+	logger *logger.Logger,
+) *SessionHandler {
+	return &SessionHandler{
+		uc:     uc,
+		mapper: mapper,
+		logger: logger,
+	}
+}
+
+func (h *SessionHandler) ListSessions(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
+	}
+
+	request := h.mapper.ToListSessionsRequest(claims)
+
+	response, err := h.uc.ListSessionsUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Errorf("Failed to list sessions for user %s: %v", claims.UserID, err)
+		return HandleUseCaseError(c, err)
+	}
+
+	return SendSuccess(c, http.StatusOK, h.mapper.ToListSessionsResponse(response))
+}
+
+func (h *SessionHandler) RevokeSession(c echo.Context) error {
+	claims, err := GetUserFromJWT(c)
+	if err != nil {
+		return err
+	}
+
+	request := h.mapper.ToRevokeSessionRequest(claims, c.Param("id"))
+
+	response, err := h.uc.RevokeSessionUseCase.Execute(c.Request().Context(), *request)
+	if err != nil {
+		h.logger.Sugar().Warnf("Failed to revoke session %s for user %s: %v", request.SessionID, claims.UserID, err)
+		return HandleUseCaseError(c, err)
+	}
+
+	h.logger.Sugar().Infof("Session %s revoked for user %s", request.SessionID, claims.UserID)
+	return SendSuccess(c, http.StatusOK, h.mapper.ToRevokeSessionResponse(response))
+}
@@ -0,0 +1,76 @@
+package mappers
+
+import (
+	"github.com/zandomed/sync-playlist-api/internal/infra/http/dtos"
+	"github.com/zandomed/sync-playlist-api/internal/middleware"
+	authUC "github.com/zandomed/sync-playlist-api/internal/usecases/auth"
+)
+
+type AccountMapper struct{}
+
+func NewAccountMapper() *AccountMapper {
+	return &AccountMapper{}
+}
+
+func (m *AccountMapper) ToListLinkedAccountsRequest(claims *middleware.Claims) *authUC.ListLinkedAccountsRequest {
+	return &authUC.ListLinkedAccountsRequest{
+		UserID: claims.UserID.String(),
+	}
+}
+
+func (m *AccountMapper) ToListLinkedAccountsResponse(ucResponse *authUC.ListLinkedAccountsResponse) *dtos.ListLinkedAccountsResponse {
+	accounts := make([]dtos.LinkedAccountResponse, 0, len(ucResponse.Accounts))
+	for _, account := range ucResponse.Accounts {
+		scopes := account.Scopes
+		if scopes == nil {
+			scopes = []string{}
+		}
+
+		accounts = append(accounts, dtos.LinkedAccountResponse{
+			Provider:    account.Provider,
+			LinkedAt:    account.LinkedAt,
+			Scopes:      scopes,
+			TokenHealth: account.TokenHealth,
+		})
+	}
+
+	return &dtos.ListLinkedAccountsResponse{
+		Accounts: accounts,
+	}
+}
+
+func (m *AccountMapper) ToUnlinkAccountRequest(claims *middleware.Claims, provider string) *authUC.UnlinkAccountRequest {
+	return &authUC.UnlinkAccountRequest{
+		UserID:   claims.UserID.String(),
+		Provider: provider,
+	}
+}
+
+func (m *AccountMapper) ToUnlinkAccountResponse(ucResponse *authUC.UnlinkAccountResponse) *dtos.UnlinkAccountResponse {
+	return &dtos.UnlinkAccountResponse{
+		Success:         ucResponse.Success,
+		Message:         ucResponse.Message,
+		RevokedUpstream: ucResponse.RevokedUpstream,
+	}
+}
+
+func (m *AccountMapper) ToAppleMusicDeveloperTokenResponse(ucResponse *authUC.GetAppleMusicDeveloperTokenResponse) *dtos.AppleMusicDeveloperTokenResponse {
+	return &dtos.AppleMusicDeveloperTokenResponse{
+		DeveloperToken: ucResponse.DeveloperToken,
+		ExpiresAt:      ucResponse.ExpiresAt,
+	}
+}
+
+func (m *AccountMapper) ToSetAppleMusicTokenRequest(dto *dtos.SetAppleMusicTokenRequest, claims *middleware.Claims) *authUC.SetAppleMusicTokenRequest {
+	return &authUC.SetAppleMusicTokenRequest{
+		UserID:         claims.UserID.String(),
+		MusicUserToken: dto.MusicUserToken,
+	}
+}
+
+func (m *AccountMapper) ToSetAppleMusicTokenResponse(ucResponse *authUC.SetAppleMusicTokenResponse) *dtos.SetAppleMusicTokenResponse {
+	return &dtos.SetAppleMusicTokenResponse{
+		Success: ucResponse.Success,
+		Message: ucResponse.Message,
+	}
+}
@@ -0,0 +1,30 @@
+package mappers
+
+import (
+	"github.com/zandomed/sync-playlist-api/internal/infra/http/dtos"
+	"github.com/zandomed/sync-playlist-api/internal/middleware"
+	authUC "github.com/zandomed/sync-playlist-api/internal/usecases/auth"
+)
+
+type AdminMapper struct{}
+
+func NewAdminMapper() *AdminMapper {
+	return &AdminMapper{}
+}
+
+func (m *AdminMapper) ToChangeUserRoleRequest(dto *dtos.ChangeUserRoleRequest, claims *middleware.Claims, userID string) *authUC.ChangeUserRoleRequest {
+	return &authUC.ChangeUserRoleRequest{
+		ActorID: claims.UserID.String(),
+		UserID:  userID,
+		Role:    dto.Role,
+	}
+}
+
+func (m *AdminMapper) ToChangeUserRoleResponse(ucResponse *authUC.ChangeUserRoleResponse) *dtos.ChangeUserRoleResponse {
+	return &dtos.ChangeUserRoleResponse{
+		Success: ucResponse.Success,
+		Message: ucResponse.Message,
+		UserID:  ucResponse.UserID,
+		Role:    ucResponse.Role,
+	}
+}
@@ -0,0 +1,80 @@
+package mappers
+
+import (
+	"github.com/zandomed/sync-playlist-api/internal/infra/http/dtos"
+	"github.com/zandomed/sync-playlist-api/internal/middleware"
+	authUC "github.com/zandomed/sync-playlist-api/internal/usecases/auth"
+)
+
+type APIKeyMapper struct{}
+
+func NewAPIKeyMapper() *APIKeyMapper {
+	return &APIKeyMapper{}
+}
+
+func (m *APIKeyMapper) ToCreateAPIKeyRequest(dto *dtos.CreateAPIKeyRequest, claims *middleware.Claims) *authUC.CreateAPIKeyRequest {
+	return &authUC.CreateAPIKeyRequest{
+		UserID:    claims.UserID.String(),
+		Name:      dto.Name,
+		Scopes:    dto.Scopes,
+		ExpiresAt: dto.ExpiresAt,
+	}
+}
+
+func (m *APIKeyMapper) ToCreateAPIKeyResponse(ucResponse *authUC.CreateAPIKeyResponse) *dtos.CreateAPIKeyResponse {
+	return &dtos.CreateAPIKeyResponse{
+		APIKeyResponse: m.ToAPIKeyResponse(&ucResponse.APIKeyResponse),
+		Key:            ucResponse.Key,
+	}
+}
+
+func (m *APIKeyMapper) ToListAPIKeysRequest(claims *middleware.Claims) *authUC.ListAPIKeysRequest {
+	return &authUC.ListAPIKeysRequest{
+		UserID: claims.UserID.String(),
+	}
+}
+
+func (m *APIKeyMapper) ToListAPIKeysResponse(ucResponse *authUC.ListAPIKeysResponse) *dtos.ListAPIKeysResponse {
+	keys := make([]dtos.APIKeyResponse, 0, len(ucResponse.Keys))
+	for i := range ucResponse.Keys {
+		keys = append(keys, m.ToAPIKeyResponse(&ucResponse.Keys[i]))
+	}
+
+	return &dtos.ListAPIKeysResponse{
+		Keys: keys,
+	}
+}
+
+func (m *APIKeyMapper) ToUpdateAPIKeyRequest(dto *dtos.UpdateAPIKeyRequest, claims *middleware.Claims, keyID string) *authUC.UpdateAPIKeyRequest {
+	return &authUC.UpdateAPIKeyRequest{
+		UserID: claims.UserID.String(),
+		KeyID:  keyID,
+		Name:   dto.Name,
+	}
+}
+
+func (m *APIKeyMapper) ToDeleteAPIKeyRequest(claims *middleware.Claims, keyID string) *authUC.DeleteAPIKeyRequest {
+	return &authUC.DeleteAPIKeyRequest{
+		UserID: claims.UserID.String(),
+		KeyID:  keyID,
+	}
+}
+
+func (m *APIKeyMapper) ToDeleteAPIKeyResponse(ucResponse *authUC.DeleteAPIKeyResponse) *dtos.DeleteAPIKeyResponse {
+	return &dtos.DeleteAPIKeyResponse{
+		Success: ucResponse.Success,
+		Message: ucResponse.Message,
+	}
+}
+
+func (m *APIKeyMapper) ToAPIKeyResponse(ucResponse *authUC.APIKeyResponse) dtos.APIKeyResponse {
+	return dtos.APIKeyResponse{
+		ID:         ucResponse.ID,
+		Name:       ucResponse.Name,
+		Prefix:     ucResponse.Prefix,
+		Scopes:     ucResponse.Scopes,
+		ExpiresAt:  ucResponse.ExpiresAt,
+		LastUsedAt: ucResponse.LastUsedAt,
+		CreatedAt:  ucResponse.CreatedAt,
+	}
+}
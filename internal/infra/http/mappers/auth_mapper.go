@@ -1,7 +1,10 @@
 package mappers
 
 import (
+	"encoding/json"
+
 	"github.com/zandomed/sync-playlist-api/internal/infra/http/dtos"
+	"github.com/zandomed/sync-playlist-api/internal/middleware"
 	authUC "github.com/zandomed/sync-playlist-api/internal/usecases/auth"
 )
 
@@ -22,15 +25,45 @@ func (m *AuthMapper) ToRegisterUserRequest(dto *dtos.RegisterRequest) *authUC.Re
 
 func (m *AuthMapper) ToRegisterResponse(ucResponse *authUC.RegisterUserResponse) *dtos.RegisterResponse {
 	return &dtos.RegisterResponse{
-		UserID:  ucResponse.UserID,
-		Message: "User registered successfully",
+		UserID:                ucResponse.UserID,
+		Message:               "User registered successfully",
+		VerificationEmailSent: ucResponse.VerificationEmailSent,
+	}
+}
+
+func (m *AuthMapper) ToVerifyEmailRequest(dto *dtos.VerifyEmailRequest) *authUC.VerifyEmailRequest {
+	return &authUC.VerifyEmailRequest{
+		Token: dto.Token,
 	}
 }
 
-func (m *AuthMapper) ToLoginUserRequest(dto *dtos.LoginRequest) *authUC.LoginUserRequest {
+func (m *AuthMapper) ToVerifyEmailResponse(ucResponse *authUC.VerifyEmailResponse) *dtos.VerifyEmailResponse {
+	return &dtos.VerifyEmailResponse{
+		UserID: ucResponse.UserID,
+		Email:  ucResponse.Email,
+	}
+}
+
+func (m *AuthMapper) ToResendVerificationEmailRequest(dto *dtos.ResendVerificationEmailRequest, ipAddress string) *authUC.ResendVerificationEmailRequest {
+	return &authUC.ResendVerificationEmailRequest{
+		Email:     dto.Email,
+		IPAddress: ipAddress,
+	}
+}
+
+func (m *AuthMapper) ToResendVerificationEmailResponse(ucResponse *authUC.ResendVerificationEmailResponse) *dtos.ResendVerificationEmailResponse {
+	return &dtos.ResendVerificationEmailResponse{
+		Success: ucResponse.Success,
+		Message: ucResponse.Message,
+	}
+}
+
+func (m *AuthMapper) ToLoginUserRequest(dto *dtos.LoginRequest, userAgent, ipAddress string) *authUC.LoginUserRequest {
 	return &authUC.LoginUserRequest{
-		Email:    dto.Email,
-		Password: dto.Password,
+		Email:     dto.Email,
+		Password:  dto.Password,
+		UserAgent: userAgent,
+		IPAddress: ipAddress,
 	}
 }
 
@@ -39,80 +72,181 @@ func (m *AuthMapper) ToLoginResponse(ucResponse *authUC.LoginUserResponse) *dtos
 		AccessToken:  ucResponse.AccessToken,
 		RefreshToken: ucResponse.RefreshToken,
 		UserID:       ucResponse.UserID,
+		MFARequired:  ucResponse.MFARequired,
+		MFAToken:     ucResponse.MFAToken,
 	}
 }
 
-func (m *AuthMapper) ToGoogleAuthURLResponse(ucResponse *authUC.GetUrlGoogleResponse) *dtos.GoogleAuthURLResponse {
-	return &dtos.GoogleAuthURLResponse{
-		URL:   ucResponse.URL,
-		State: ucResponse.State,
+func (m *AuthMapper) ToRefreshTokenRequest(dto *dtos.RefreshTokenRequest, userAgent, ipAddress string) *authUC.RefreshTokenRequest {
+	return &authUC.RefreshTokenRequest{
+		RefreshToken: dto.RefreshToken,
+		UserAgent:    userAgent,
+		IPAddress:    ipAddress,
 	}
 }
 
-func (m *AuthMapper) ToGoogleCallbackRequest(dto *dtos.GoogleCallbackRequest) *authUC.LoginGoogleCallbackRequest {
-	return &authUC.LoginGoogleCallbackRequest{
-		Code:  dto.Code,
-		State: dto.State,
+func (m *AuthMapper) ToRefreshTokenResponse(ucResponse *authUC.RefreshTokenResponse) *dtos.RefreshTokenResponse {
+	return &dtos.RefreshTokenResponse{
+		AccessToken:  ucResponse.AccessToken,
+		RefreshToken: ucResponse.RefreshToken,
+		UserID:       ucResponse.UserID,
 	}
 }
 
-func (m *AuthMapper) ToGoogleCallbackResponse(ucResponse *authUC.LoginGoogleCallbackResponse) *dtos.GoogleCallbackResponse {
-	return &dtos.GoogleCallbackResponse{
-		AccessToken:               ucResponse.AccessToken,
-		RefreshToken:              ucResponse.RefreshToken,
-		UserID:                    ucResponse.UserID,
-		IsNewUser:                 ucResponse.IsNewUser,
-		FrontendVerificationToken: ucResponse.FrontendVerificationToken,
+func (m *AuthMapper) ToForgotPasswordRequest(dto *dtos.ForgotPasswordRequest, ipAddress string) *authUC.ForgotPasswordRequest {
+	return &authUC.ForgotPasswordRequest{
+		Email:     dto.Email,
+		IPAddress: ipAddress,
 	}
 }
 
-func (m *AuthMapper) ToVerifyTokenRequest(dto *dtos.VerifyTokenRequest) *authUC.VerifyTokenRequest {
-	return &authUC.VerifyTokenRequest{
-		Token: dto.Token,
+func (m *AuthMapper) ToForgotPasswordResponse(ucResponse *authUC.ForgotPasswordResponse) *dtos.PasswordResponse {
+	return &dtos.PasswordResponse{
+		Success: ucResponse.Success,
+		Message: ucResponse.Message,
 	}
 }
 
-func (m *AuthMapper) ToVerifyTokenResponse(ucResponse *authUC.VerifyTokenResponse) *dtos.VerifyTokenResponse {
-	return &dtos.VerifyTokenResponse{
-		Valid:  ucResponse.Valid,
-		UserID: ucResponse.UserID,
+func (m *AuthMapper) ToResetPasswordRequest(dto *dtos.ResetPasswordRequest) *authUC.ResetPasswordRequest {
+	return &authUC.ResetPasswordRequest{
+		Token:       dto.Token,
+		NewPassword: dto.NewPassword,
+	}
+}
+
+func (m *AuthMapper) ToResetPasswordResponse(ucResponse *authUC.ResetPasswordResponse) *dtos.PasswordResponse {
+	return &dtos.PasswordResponse{
+		Success: ucResponse.Success,
+		Message: ucResponse.Message,
+	}
+}
+
+func (m *AuthMapper) ToChangePasswordRequest(dto *dtos.ChangePasswordRequest, claims *middleware.Claims) *authUC.ChangePasswordRequest {
+	return &authUC.ChangePasswordRequest{
+		UserID:          claims.UserID.String(),
+		CurrentPassword: dto.CurrentPassword,
+		NewPassword:     dto.NewPassword,
+		AccessTokenID:   claims.ID,
+	}
+}
+
+func (m *AuthMapper) ToChangePasswordResponse(ucResponse *authUC.ChangePasswordResponse) *dtos.PasswordResponse {
+	return &dtos.PasswordResponse{
+		Success: ucResponse.Success,
+		Message: ucResponse.Message,
+	}
+}
+
+func (m *AuthMapper) ToLogoutRequest(dto *dtos.LogoutRequest, claims *middleware.Claims) *authUC.LogoutRequest {
+	return &authUC.LogoutRequest{
+		UserID:               claims.UserID.String(),
+		RefreshToken:         dto.RefreshToken,
+		AccessTokenID:        claims.ID,
+		AccessTokenExpiresAt: claims.ExpiresAt.Time,
+	}
+}
+
+func (m *AuthMapper) ToLogoutAllRequest(claims *middleware.Claims) *authUC.LogoutAllRequest {
+	return &authUC.LogoutAllRequest{
+		UserID:               claims.UserID.String(),
+		AccessTokenID:        claims.ID,
+		AccessTokenExpiresAt: claims.ExpiresAt.Time,
+	}
+}
+
+func (m *AuthMapper) ToLogoutResponse(ucResponse *authUC.LogoutResponse) *dtos.LogoutResponse {
+	return &dtos.LogoutResponse{
+		Success: ucResponse.Success,
+		Message: ucResponse.Message,
+	}
+}
+
+func (m *AuthMapper) ToOAuthURLRequest(provider, codeChallenge string) *authUC.GetOAuthURLRequest {
+	return &authUC.GetOAuthURLRequest{
+		Provider:      provider,
+		CodeChallenge: codeChallenge,
+	}
+}
+
+func (m *AuthMapper) ToOAuthLinkURLRequest(provider string, claims *middleware.Claims) *authUC.GetOAuthURLRequest {
+	return &authUC.GetOAuthURLRequest{
+		Provider: provider,
+		UserID:   claims.UserID.String(),
 	}
 }
 
-func (m *AuthMapper) ToSpotifyAuthURLResponse(ucResponse *authUC.GetUrlSpotifyResponse) *dtos.SpotifyAuthURLResponse {
-	return &dtos.SpotifyAuthURLResponse{
+func (m *AuthMapper) ToOAuthAuthURLResponse(ucResponse *authUC.GetOAuthURLResponse) *dtos.OAuthAuthURLResponse {
+	return &dtos.OAuthAuthURLResponse{
 		URL:   ucResponse.URL,
 		State: ucResponse.State,
 	}
 }
 
-func (m *AuthMapper) ToSpotifyCallbackRequest(dto *dtos.SpotifyCallbackRequest) *authUC.LoginSpotifyCallbackRequest {
-	return &authUC.LoginSpotifyCallbackRequest{
-		Code:  dto.Code,
-		State: dto.State,
+func (m *AuthMapper) ToOAuthCallbackRequest(dto *dtos.OAuthCallbackRequest, browserNonce string) *authUC.LoginOAuthCallbackRequest {
+	// The name is optional: a malformed payload is treated as absent
+	var user struct {
+		Name struct {
+			FirstName string `json:"firstName"`
+			LastName  string `json:"lastName"`
+		} `json:"name"`
+	}
+	if dto.User != "" {
+		_ = json.Unmarshal([]byte(dto.User), &user)
+	}
+
+	return &authUC.LoginOAuthCallbackRequest{
+		Provider:     dto.Provider,
+		Code:         dto.Code,
+		State:        dto.State,
+		FirstName:    user.Name.FirstName,
+		LastName:     user.Name.LastName,
+		BrowserNonce: browserNonce,
 	}
 }
 
-func (m *AuthMapper) ToSpotifyCallbackResponse(ucResponse *authUC.LoginSpotifyCallbackResponse) *dtos.SpotifyCallbackResponse {
-	return &dtos.SpotifyCallbackResponse{
-		AccessToken:               ucResponse.AccessToken,
-		RefreshToken:              ucResponse.RefreshToken,
+func (m *AuthMapper) ToOAuthCallbackResponse(ucResponse *authUC.LoginOAuthCallbackResponse) *dtos.OAuthCallbackResponse {
+	return &dtos.OAuthCallbackResponse{
 		UserID:                    ucResponse.UserID,
 		IsNewUser:                 ucResponse.IsNewUser,
 		FrontendVerificationToken: ucResponse.FrontendVerificationToken,
 	}
 }
 
-func (m *AuthMapper) ToLinkSpotifyRequest(dto *dtos.LinkSpotifyRequest, userID string) *authUC.LinkSpotifyAccountRequest {
-	return &authUC.LinkSpotifyAccountRequest{
-		UserID: userID,
-		Code:   dto.Code,
-		State:  dto.State,
+func (m *AuthMapper) ToVerifyTokenRequest(dto *dtos.VerifyTokenRequest, userAgent, ipAddress string) *authUC.VerifyTokenRequest {
+	return &authUC.VerifyTokenRequest{
+		Token:        dto.Token,
+		CodeVerifier: dto.CodeVerifier,
+		UserAgent:    userAgent,
+		IPAddress:    ipAddress,
+	}
+}
+
+// ToVerifyTokenResponse leaves the refresh token out of the body when it is
+// delivered as a cookie
+func (m *AuthMapper) ToVerifyTokenResponse(ucResponse *authUC.VerifyTokenResponse, refreshTokenInCookie bool) *dtos.VerifyTokenResponse {
+	response := &dtos.VerifyTokenResponse{
+		Valid:        ucResponse.Valid,
+		UserID:       ucResponse.UserID,
+		AccessToken:  ucResponse.AccessToken,
+		RefreshToken: ucResponse.RefreshToken,
+	}
+	if refreshTokenInCookie {
+		response.RefreshToken = ""
+	}
+	return response
+}
+
+func (m *AuthMapper) ToLinkOAuthAccountRequest(dto *dtos.LinkOAuthAccountRequest, userID, provider string) *authUC.LinkOAuthAccountRequest {
+	return &authUC.LinkOAuthAccountRequest{
+		UserID:   userID,
+		Provider: provider,
+		Code:     dto.Code,
+		State:    dto.State,
 	}
 }
 
-func (m *AuthMapper) ToLinkSpotifyResponse(ucResponse *authUC.LinkSpotifyAccountResponse) *dtos.LinkSpotifyResponse {
-	return &dtos.LinkSpotifyResponse{
+func (m *AuthMapper) ToLinkOAuthAccountResponse(ucResponse *authUC.LinkOAuthAccountResponse) *dtos.LinkOAuthAccountResponse {
+	return &dtos.LinkOAuthAccountResponse{
 		Success: ucResponse.Success,
 		Message: ucResponse.Message,
 	}
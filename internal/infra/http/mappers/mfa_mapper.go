@@ -0,0 +1,71 @@
+package mappers
+
+import (
+	"github.com/zandomed/sync-playlist-api/internal/infra/http/dtos"
+	"github.com/zandomed/sync-playlist-api/internal/middleware"
+	authUC "github.com/zandomed/sync-playlist-api/internal/usecases/auth"
+)
+
+type MFAMapper struct{}
+
+func NewMFAMapper() *MFAMapper {
+	return &MFAMapper{}
+}
+
+func (m *MFAMapper) ToStartTOTPEnrollmentRequest(claims *middleware.Claims) *authUC.StartTOTPEnrollmentRequest {
+	return &authUC.StartTOTPEnrollmentRequest{
+		UserID: claims.UserID.String(),
+	}
+}
+
+func (m *MFAMapper) ToStartTOTPEnrollmentResponse(ucResponse *authUC.StartTOTPEnrollmentResponse) *dtos.StartTOTPEnrollmentResponse {
+	return &dtos.StartTOTPEnrollmentResponse{
+		Secret:          ucResponse.Secret,
+		ProvisioningURI: ucResponse.ProvisioningURI,
+	}
+}
+
+func (m *MFAMapper) ToConfirmTOTPEnrollmentRequest(dto *dtos.ConfirmTOTPEnrollmentRequest, claims *middleware.Claims) *authUC.ConfirmTOTPEnrollmentRequest {
+	return &authUC.ConfirmTOTPEnrollmentRequest{
+		UserID: claims.UserID.String(),
+		Code:   dto.Code,
+	}
+}
+
+func (m *MFAMapper) ToConfirmTOTPEnrollmentResponse(ucResponse *authUC.ConfirmTOTPEnrollmentResponse) *dtos.ConfirmTOTPEnrollmentResponse {
+	return &dtos.ConfirmTOTPEnrollmentResponse{
+		RecoveryCodes: ucResponse.RecoveryCodes,
+	}
+}
+
+func (m *MFAMapper) ToDisableTOTPRequest(dto *dtos.DisableTOTPRequest, claims *middleware.Claims) *authUC.DisableTOTPRequest {
+	return &authUC.DisableTOTPRequest{
+		UserID: claims.UserID.String(),
+		Code:   dto.Code,
+	}
+}
+
+func (m *MFAMapper) ToDisableTOTPResponse(ucResponse *authUC.DisableTOTPResponse) *dtos.DisableTOTPResponse {
+	return &dtos.DisableTOTPResponse{
+		Success: ucResponse.Success,
+		Message: ucResponse.Message,
+	}
+}
+
+func (m *MFAMapper) ToVerifyMFARequest(dto *dtos.VerifyMFARequest, userAgent, ipAddress string) *authUC.VerifyMFARequest {
+	return &authUC.VerifyMFARequest{
+		MFAToken:  dto.MFAToken,
+		Code:      dto.Code,
+		UserAgent: userAgent,
+		IPAddress: ipAddress,
+	}
+}
+
+func (m *MFAMapper) ToVerifyMFAResponse(ucResponse *authUC.VerifyMFAResponse) *dtos.VerifyMFAResponse {
+	return &dtos.VerifyMFAResponse{
+		AccessToken:            ucResponse.AccessToken,
+		RefreshToken:           ucResponse.RefreshToken,
+		UserID:                 ucResponse.UserID,
+		RemainingRecoveryCodes: ucResponse.RemainingRecoveryCodes,
+	}
+}
@@ -0,0 +1,54 @@
+package mappers
+
+import (
+	"github.com/zandomed/sync-playlist-api/internal/infra/http/dtos"
+	"github.com/zandomed/sync-playlist-api/internal/middleware"
+	authUC "github.com/zandomed/sync-playlist-api/internal/usecases/auth"
+)
+
+type SessionMapper struct{}
+
+func NewSessionMapper() *SessionMapper {
+	return &SessionMapper{}
+}
+
+func (m *SessionMapper) ToListSessionsRequest(claims *middleware.Claims) *authUC.ListSessionsRequest {
+	return &authUC.ListSessionsRequest{
+		UserID:               claims.UserID.String(),
+		CurrentAccessTokenID: claims.ID,
+	}
+}
+
+func (m *SessionMapper) ToListSessionsResponse(ucResponse *authUC.ListSessionsResponse) *dtos.ListSessionsResponse {
+	sessions := make([]dtos.SessionResponse, 0, len(ucResponse.Sessions))
+	for _, session := range ucResponse.Sessions {
+		sessions = append(sessions, dtos.SessionResponse{
+			ID:          session.ID,
+			DeviceLabel: session.DeviceLabel,
+			UserAgent:   session.UserAgent,
+			IPAddress:   session.IPAddress,
+			CreatedAt:   session.CreatedAt,
+			LastUsedAt:  session.LastUsedAt,
+			ExpiresAt:   session.ExpiresAt,
+			Current:     session.Current,
+		})
+	}
+
+	return &dtos.ListSessionsResponse{
+		Sessions: sessions,
+	}
+}
+
+func (m *SessionMapper) ToRevokeSessionRequest(claims *middleware.Claims, sessionID string) *authUC.RevokeSessionRequest {
+	return &authUC.RevokeSessionRequest{
+		UserID:    claims.UserID.String(),
+		SessionID: sessionID,
+	}
+}
+
+func (m *SessionMapper) ToRevokeSessionResponse(ucResponse *authUC.RevokeSessionResponse) *dtos.RevokeSessionResponse {
+	return &dtos.RevokeSessionResponse{
+		Success: ucResponse.Success,
+		Message: ucResponse.Message,
+	}
+}
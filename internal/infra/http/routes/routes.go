@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
 	"github.com/zandomed/sync-playlist-api/internal/infra/container"
 	"github.com/zandomed/sync-playlist-api/internal/middleware"
 )
@@ -11,22 +12,63 @@ func SetupRoutes(e *echo.Echo, container *container.Container) {
 
 	e.GET("/health", container.HealthHandler.GetStatus)
 	e.GET("/", container.HealthHandler.GetStatus)
+	e.GET("/.well-known/jwks.json", container.JWKSHandler.GetKeys)
 	api := e.Group("/v1")
 
 	api.Use(middleware.Logger())
+	api.Use(middleware.CSRF())
 
 	oauth := api.Group("/oauth")
 	{
-		oauth.GET("/google", container.AuthHandler.GoogleAuth)
-		oauth.GET("/google/callback", container.AuthHandler.GoogleCallback)
-		oauth.GET("/spotify", container.AuthHandler.SpotifyAuth)
-		oauth.GET("/spotify/callback", container.AuthHandler.SpotifyCallback)
 		oauth.POST("/verify", container.AuthHandler.VerifyToken)
+		oauth.GET("/:provider", container.AuthHandler.OAuthAuthorize)
+		oauth.GET("/:provider/callback", container.AuthHandler.OAuthCallback)
+		oauth.POST("/:provider/callback", container.AuthHandler.OAuthCallback)
 	}
 
 	auth := api.Group("/auth")
 	{
 		auth.POST("/register", container.AuthHandler.Register)
 		auth.POST("/login", container.AuthHandler.Login)
+		auth.POST("/refresh", container.AuthHandler.RefreshToken)
+		auth.POST("/verify-email", container.AuthHandler.VerifyEmail)
+		auth.POST("/verify-email/resend", container.AuthHandler.ResendVerificationEmail)
+		auth.POST("/forgot-password", container.AuthHandler.ForgotPassword)
+		auth.POST("/reset-password", container.AuthHandler.ResetPassword)
+		auth.POST("/change-password", container.AuthHandler.ChangePassword, container.JWTMiddleware)
+		auth.POST("/mfa/verify", container.MFAHandler.VerifyMFA)
+		auth.POST("/logout", container.AuthHandler.Logout, container.JWTMiddleware)
+		auth.POST("/logout-all", container.AuthHandler.LogoutAll, container.JWTMiddleware)
+	}
+
+	me := api.Group("/me", container.JWTMiddleware)
+	{
+		me.GET("/sessions", container.SessionHandler.ListSessions)
+		me.DELETE("/sessions/:id", container.SessionHandler.RevokeSession)
+		me.POST("/mfa/totp", container.MFAHandler.StartTOTPEnrollment)
+		me.POST("/mfa/totp/confirm", container.MFAHandler.ConfirmTOTPEnrollment)
+		me.DELETE("/mfa/totp", container.MFAHandler.DisableTOTP)
+		me.DELETE("/accounts/:provider", container.AccountHandler.UnlinkAccount)
+		me.GET("/accounts/apple/developer-token", container.AccountHandler.GetAppleMusicDeveloperToken)
+		me.PUT("/accounts/apple/music-token", container.AccountHandler.SetAppleMusicToken)
+		me.GET("/accounts/:provider/link", container.AuthHandler.OAuthLink)
+		me.POST("/accounts/:provider/link", container.AuthHandler.LinkOAuthAccount)
+		me.GET("/api-keys", container.APIKeyHandler.ListAPIKeys)
+		me.POST("/api-keys", container.APIKeyHandler.CreateAPIKey)
+		me.PATCH("/api-keys/:id", container.APIKeyHandler.UpdateAPIKey)
+		me.DELETE("/api-keys/:id", container.APIKeyHandler.DeleteAPIKey)
+	}
+
+	// Routes scripts can call with a personal API key, each behind the scope it
+	// needs. JWT sessions reach them as before. Sessions, MFA, account links and the keys themselves stay under
+	// JWTMiddleware, so a leaked key cannot take over the account
+	scripts := api.Group("/me")
+	{
+		scripts.GET("/accounts", container.AccountHandler.ListLinkedAccounts, container.AuthMiddleware, middleware.RequireAPIKeyScope(entities.ScopeAccountsRead))
+	}
+
+	admin := api.Group("/admin", container.JWTMiddleware, middleware.RequireRole("admin"))
+	{
+		admin.PUT("/users/:id/role", container.AdminHandler.ChangeUserRole, middleware.RequireScope("users:write"))
 	}
 }
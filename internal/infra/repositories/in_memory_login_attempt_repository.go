@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+)
+
+// InMemoryLoginAttemptRepository keeps login attempts in process memory. It
+// suits tests and single-node deployments; counters are lost on restart
+type InMemoryLoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[string]*entities.LoginAttempts
+}
+
+func NewInMemoryLoginAttemptRepository() repositories.LoginAttemptRepository {
+	return &InMemoryLoginAttemptRepository{
+		attempts: make(map[string]*entities.LoginAttempts),
+	}
+}
+
+func (r *InMemoryLoginAttemptRepository) Find(ctx context.Context, key string) (*entities.LoginAttempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.copyOf(key), nil
+}
+
+func (r *InMemoryLoginAttemptRepository) RegisterFailure(
+	ctx context.Context,
+	key string,
+	now time.Time,
+	policy entities.LockoutPolicy,
+) (*entities.LoginAttempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts, ok := r.attempts[key]
+	if !ok {
+		attempts = entities.NewLoginAttempts(key)
+		r.attempts[key] = attempts
+	}
+
+	attempts.RegisterFailure(now, policy)
+	return r.copyOf(key), nil
+}
+
+func (r *InMemoryLoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
+
+func (r *InMemoryLoginAttemptRepository) CleanupExpired(ctx context.Context, olderThan time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for key, attempts := range r.attempts {
+		if attempts.LastFailureAt().Before(olderThan) && attempts.RetryAfter(now) == 0 {
+			delete(r.attempts, key)
+		}
+	}
+	return nil
+}
+
+// copyOf returns a snapshot so callers never share the stored record
+func (r *InMemoryLoginAttemptRepository) copyOf(key string) *entities.LoginAttempts {
+	attempts, ok := r.attempts[key]
+	if !ok {
+		return entities.NewLoginAttempts(key)
+	}
+	return entities.ReconstructLoginAttempts(key, attempts.Failures(), attempts.LastFailureAt(), attempts.LockedUntil())
+}
@@ -3,31 +3,60 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	stderrors "errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
 	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
 	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
 	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
 	"github.com/zandomed/sync-playlist-api/pkg/database"
+	"github.com/zandomed/sync-playlist-api/pkg/logger"
 )
 
+// SecretCipher encrypts provider tokens before they reach the database.
+// associatedData binds each ciphertext to its row and column
+type SecretCipher interface {
+	Encrypt(plaintext, associatedData []byte) (string, error)
+	Decrypt(ciphertext string, associatedData []byte) ([]byte, error)
+}
+
 type PostgresAccountRepository struct {
-	db *database.DB
+	db     *database.DB
+	cipher SecretCipher
+	logger *logger.Logger
 }
 
-func NewPostgresAccountRepository(db *database.DB) repositories.AccountRepository {
-	return &PostgresAccountRepository{db: db}
+func NewPostgresAccountRepository(db *database.DB, cipher SecretCipher, logger *logger.Logger) repositories.AccountRepository {
+	return &PostgresAccountRepository{db: db, cipher: cipher, logger: logger}
 }
 
+const accountColumns = `a.id, a.user_id, a.provider, a.password, a.provider_subject,
+		a.access_token, a.refresh_token, a.access_token_expires_at, a.refresh_token_expires_at, a.scope,
+		a.reauth_required, a.music_user_token, a.created_at, a.updated_at`
+
 func (r *PostgresAccountRepository) Save(ctx context.Context, account *entities.Account) error {
 	query := `
-		INSERT INTO accounts (id, user_id, provider, password, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO accounts (
+			id, user_id, provider, password, provider_subject,
+			access_token, refresh_token, access_token_expires_at, refresh_token_expires_at, scope,
+			reauth_required, music_user_token, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		ON CONFLICT (id) DO UPDATE SET
 			provider = EXCLUDED.provider,
 			password = EXCLUDED.password,
+			provider_subject = EXCLUDED.provider_subject,
+			access_token = EXCLUDED.access_token,
+			refresh_token = EXCLUDED.refresh_token,
+			access_token_expires_at = EXCLUDED.access_token_expires_at,
+			refresh_token_expires_at = EXCLUDED.refresh_token_expires_at,
+			scope = EXCLUDED.scope,
+			reauth_required = EXCLUDED.reauth_required,
+			music_user_token = EXCLUDED.music_user_token,
 			updated_at = EXCLUDED.updated_at`
 
 	var password interface{}
@@ -35,61 +64,98 @@ func (r *PostgresAccountRepository) Save(ctx context.Context, account *entities.
 		password = account.Password().Value()
 	}
 
-	_, err := r.db.ExecContext(
+	credentials := account.Credentials()
+
+	accessToken, err := r.encrypt(account.ID(), "access_token", credentials.AccessToken())
+	if err != nil {
+		return err
+	}
+
+	refreshToken, err := r.encrypt(account.ID(), "refresh_token", credentials.RefreshToken())
+	if err != nil {
+		return err
+	}
+
+	musicUserToken, err := r.encrypt(account.ID(), "music_user_token", account.MusicUserToken())
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(
 		ctx,
 		query,
 		account.ID().Value(),
 		account.UserID().Value(),
 		string(account.Provider()),
 		password,
+		nullString(account.Subject()),
+		accessToken,
+		refreshToken,
+		nullTime(credentials.AccessTokenExpiresAt()),
+		nullTime(credentials.RefreshTokenExpiresAt()),
+		nullString(credentials.Scope()),
+		account.ReauthRequired(),
+		musicUserToken,
 		account.CreatedAt(),
 		account.UpdatedAt(),
 	)
 
+	if isUniqueViolation(err, "idx_accounts_provider_subject") {
+		return errors.NewDomainError("account_linked_to_other_user", "The provider account is already linked to another user")
+	}
+
 	return err
 }
 
-func (r *PostgresAccountRepository) FindByUserIDAndProvider(
+func (r *PostgresAccountRepository) FindByProviderSubject(
 	ctx context.Context,
-	userID valueobjects.UserID,
 	provider entities.AccountProvider,
+	subject string,
 ) (*entities.Account, error) {
 	query := `
-		SELECT id, user_id, provider, password, created_at, updated_at
-		FROM accounts
-		WHERE user_id = $1 AND provider = $2`
+		SELECT ` + accountColumns + `
+		FROM accounts a
+		WHERE a.provider = $1 AND a.provider_subject = $2`
 
-	var accountID, userIDStr, providerStr string
-	var password sql.NullString
-	var createdAt, updatedAt time.Time
+	return r.scanAccount(r.db.QueryRowContext(ctx, query, string(provider), subject))
+}
 
-	err := r.db.QueryRowContext(ctx, query, userID.Value(), string(provider)).Scan(
-		&accountID, &userIDStr, &providerStr, &password, &createdAt, &updatedAt,
-	)
+func (r *PostgresAccountRepository) FindByUserID(ctx context.Context, userID valueobjects.UserID) ([]*entities.Account, error) {
+	query := `
+		SELECT ` + accountColumns + `
+		FROM accounts a
+		WHERE a.user_id = $1
+		ORDER BY a.created_at`
 
+	rows, err := r.db.QueryContext(ctx, query, userID.Value())
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.NewNotFoundError("account", "Account not found")
-		}
 		return nil, err
 	}
+	defer rows.Close()
 
-	parsedAccountID, err := uuid.Parse(accountID)
-	if err != nil {
-		return nil, err
+	var accounts []*entities.Account
+	for rows.Next() {
+		account, err := r.scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
 	}
 
-	parsedUserID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		return nil, err
-	}
+	return accounts, rows.Err()
+}
 
-	passwordValue := ""
-	if password.Valid {
-		passwordValue = password.String
-	}
+func (r *PostgresAccountRepository) FindByUserIDAndProvider(
+	ctx context.Context,
+	userID valueobjects.UserID,
+	provider entities.AccountProvider,
+) (*entities.Account, error) {
+	query := `
+		SELECT ` + accountColumns + `
+		FROM accounts a
+		WHERE a.user_id = $1 AND a.provider = $2`
 
-	return entities.ReconstructAccount(parsedAccountID, parsedUserID, providerStr, passwordValue, createdAt, updatedAt)
+	return r.scanAccount(r.db.QueryRowContext(ctx, query, userID.Value(), string(provider)))
 }
 
 func (r *PostgresAccountRepository) FindUserpassAccountByEmail(
@@ -97,17 +163,31 @@ func (r *PostgresAccountRepository) FindUserpassAccountByEmail(
 	email valueobjects.Email,
 ) (*entities.Account, error) {
 	query := `
-		SELECT a.id, a.user_id, a.provider, a.password, a.created_at, a.updated_at
+		SELECT ` + accountColumns + `
 		FROM accounts a
 		JOIN users u ON a.user_id = u.id
 		WHERE u.email = $1 AND a.provider = 'userpass'`
 
+	return r.scanAccount(r.db.QueryRowContext(ctx, query, email.Value()))
+}
+
+func (r *PostgresAccountRepository) Delete(ctx context.Context, id valueobjects.AccountID) error {
+	query := `DELETE FROM accounts WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id.Value())
+	return err
+}
+
+func (r *PostgresAccountRepository) scanAccount(row rowScanner) (*entities.Account, error) {
 	var accountID, userIDStr, providerStr string
-	var password sql.NullString
+	var password, subject, accessToken, refreshToken, scope, musicUserToken sql.NullString
+	var accessTokenExpiresAt, refreshTokenExpiresAt sql.NullTime
+	var reauthRequired bool
 	var createdAt, updatedAt time.Time
 
-	err := r.db.QueryRowContext(ctx, query, email.Value()).Scan(
-		&accountID, &userIDStr, &providerStr, &password, &createdAt, &updatedAt,
+	err := row.Scan(
+		&accountID, &userIDStr, &providerStr, &password, &subject,
+		&accessToken, &refreshToken, &accessTokenExpiresAt, &refreshTokenExpiresAt, &scope,
+		&reauthRequired, &musicUserToken, &createdAt, &updatedAt,
 	)
 
 	if err != nil {
@@ -127,16 +207,80 @@ func (r *PostgresAccountRepository) FindUserpassAccountByEmail(
 		return nil, err
 	}
 
-	passwordValue := ""
-	if password.Valid {
-		passwordValue = password.String
+	id, err := valueobjects.ReconstructAccountID(parsedAccountID)
+	if err != nil {
+		return nil, err
 	}
 
-	return entities.ReconstructAccount(parsedAccountID, parsedUserID, providerStr, passwordValue, createdAt, updatedAt)
+	// A token that no longer decrypts (e.g. its key was retired) is treated as
+	// missing, so the account asks for a new link instead of failing every read
+	accessTokenValue, ok := r.decryptOrDiscard(id, "access_token", accessToken)
+	reauthRequired = reauthRequired || !ok
+
+	refreshTokenValue, ok := r.decryptOrDiscard(id, "refresh_token", refreshToken)
+	reauthRequired = reauthRequired || !ok
+
+	musicUserTokenValue, ok := r.decryptOrDiscard(id, "music_user_token", musicUserToken)
+	reauthRequired = reauthRequired || !ok
+
+	credentials := entities.ReconstructProviderCredentials(
+		accessTokenValue,
+		refreshTokenValue,
+		accessTokenExpiresAt.Time,
+		refreshTokenExpiresAt.Time,
+		scope.String,
+	)
+
+	return entities.ReconstructAccount(parsedAccountID, parsedUserID, providerStr, password.String, subject.String, credentials, reauthRequired, musicUserTokenValue, createdAt, updatedAt)
 }
 
-func (r *PostgresAccountRepository) Delete(ctx context.Context, id valueobjects.AccountID) error {
-	query := `DELETE FROM accounts WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id.Value())
-	return err
-}
\ No newline at end of file
+// encrypt returns NULL for empty tokens so missing credentials stay visible in the table
+func (r *PostgresAccountRepository) encrypt(id valueobjects.AccountID, column, value string) (sql.NullString, error) {
+	if value == "" {
+		return sql.NullString{}, nil
+	}
+
+	ciphertext, err := r.cipher.Encrypt([]byte(value), tokenAssociatedData(id, column))
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("failed to encrypt %s: %w", column, err)
+	}
+	return sql.NullString{String: ciphertext, Valid: true}, nil
+}
+
+func (r *PostgresAccountRepository) decrypt(id valueobjects.AccountID, column string, value sql.NullString) (string, error) {
+	if !value.Valid || value.String == "" {
+		return "", nil
+	}
+
+	plaintext, err := r.cipher.Decrypt(value.String, tokenAssociatedData(id, column))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s of account %s: %w", column, id.String(), err)
+	}
+	return string(plaintext), nil
+}
+
+// decryptOrDiscard logs values that fail to decrypt and reports them as empty
+func (r *PostgresAccountRepository) decryptOrDiscard(id valueobjects.AccountID, column string, value sql.NullString) (string, bool) {
+	plaintext, err := r.decrypt(id, column, value)
+	if err != nil {
+		r.logger.Sugar().Errorf("Discarding unreadable provider token: %v", err)
+		return "", false
+	}
+	return plaintext, true
+}
+
+// tokenAssociatedData stops a ciphertext from being moved to another account
+// or column and decrypting there
+func tokenAssociatedData(id valueobjects.AccountID, column string) []byte {
+	return []byte("accounts:" + id.String() + ":" + column)
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// isUniqueViolation reports whether err is a unique_violation of constraint
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return stderrors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
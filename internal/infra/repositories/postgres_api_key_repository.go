@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+	"github.com/zandomed/sync-playlist-api/pkg/database"
+)
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at, updated_at`
+
+type PostgresAPIKeyRepository struct {
+	db *database.DB
+}
+
+func NewPostgresAPIKeyRepository(db *database.DB) repositories.APIKeyRepository {
+	return &PostgresAPIKeyRepository{db: db}
+}
+
+func (r *PostgresAPIKeyRepository) Save(ctx context.Context, key *entities.APIKey) error {
+	query := `
+		INSERT INTO api_keys (` + apiKeyColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			last_used_at = EXCLUDED.last_used_at,
+			updated_at = EXCLUDED.updated_at`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		key.ID().Value(),
+		key.UserID().Value(),
+		key.Name(),
+		key.Prefix(),
+		key.Hash(),
+		pq.Array(key.Scopes()),
+		key.ExpiresAt(),
+		key.LastUsedAt(),
+		key.CreatedAt(),
+		key.UpdatedAt(),
+	)
+
+	return err
+}
+
+func (r *PostgresAPIKeyRepository) FindByID(ctx context.Context, id valueobjects.APIKeyID) (*entities.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE id = $1`
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, id.Value()))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("api_key", "API key not found")
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *PostgresAPIKeyRepository) FindByHash(ctx context.Context, hash string) (*entities.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE key_hash = $1`
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, hash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("api_key", "API key not found")
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *PostgresAPIKeyRepository) FindByUserID(ctx context.Context, userID valueobjects.UserID) ([]*entities.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE user_id = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID.Value())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*entities.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *PostgresAPIKeyRepository) Delete(ctx context.Context, id valueobjects.APIKeyID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM api_keys WHERE id = $1`, id.Value())
+	return err
+}
+
+func scanAPIKey(row rowScanner) (*entities.APIKey, error) {
+	var idStr, userIDStr, name, prefix, hash string
+	var scopes []string
+	var expiresAt, lastUsedAt sql.NullTime
+	var createdAt, updatedAt time.Time
+
+	err := row.Scan(
+		&idStr, &userIDStr, &name, &prefix, &hash, pq.Array(&scopes),
+		&expiresAt, &lastUsedAt, &createdAt, &updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := valueobjects.ReconstructAPIKeyID(uuid.MustParse(idStr))
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := valueobjects.ReconstructUserID(uuid.MustParse(userIDStr))
+	if err != nil {
+		return nil, err
+	}
+
+	var expiresAtPtr, lastUsedAtPtr *time.Time
+	if expiresAt.Valid {
+		expiresAtPtr = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		lastUsedAtPtr = &lastUsedAt.Time
+	}
+
+	return entities.ReconstructAPIKey(
+		id,
+		userID,
+		name,
+		prefix,
+		hash,
+		scopes,
+		expiresAtPtr,
+		lastUsedAtPtr,
+		createdAt,
+		updatedAt,
+	), nil
+}
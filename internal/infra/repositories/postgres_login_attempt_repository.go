@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/pkg/database"
+)
+
+type PostgresLoginAttemptRepository struct {
+	db *database.DB
+}
+
+func NewPostgresLoginAttemptRepository(db *database.DB) repositories.LoginAttemptRepository {
+	return &PostgresLoginAttemptRepository{db: db}
+}
+
+func (r *PostgresLoginAttemptRepository) Find(ctx context.Context, key string) (*entities.LoginAttempts, error) {
+	query := `SELECT failures, last_failure_at, locked_until FROM login_attempts WHERE key = $1`
+
+	attempts, err := scanLoginAttempts(key, r.db.QueryRowContext(ctx, query, key))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entities.NewLoginAttempts(key), nil
+		}
+		return nil, err
+	}
+	return attempts, nil
+}
+
+func (r *PostgresLoginAttemptRepository) RegisterFailure(
+	ctx context.Context,
+	key string,
+	now time.Time,
+	policy entities.LockoutPolicy,
+) (*entities.LoginAttempts, error) {
+	var attempts *entities.LoginAttempts
+
+	err := r.db.Transaction(func(tx *sqlx.Tx) error {
+		// Make sure the row exists so it can be locked
+		_, err := tx.ExecContext(ctx, `INSERT INTO login_attempts (key) VALUES ($1) ON CONFLICT (key) DO NOTHING`, key)
+		if err != nil {
+			return err
+		}
+
+		query := `SELECT failures, last_failure_at, locked_until FROM login_attempts WHERE key = $1 FOR UPDATE`
+		attempts, err = scanLoginAttempts(key, tx.QueryRowContext(ctx, query, key))
+		if err != nil {
+			return err
+		}
+
+		attempts.RegisterFailure(now, policy)
+
+		_, err = tx.ExecContext(
+			ctx,
+			`UPDATE login_attempts SET failures = $1, last_failure_at = $2, locked_until = $3 WHERE key = $4`,
+			attempts.Failures(),
+			attempts.LastFailureAt(),
+			nullTime(attempts.LockedUntil()),
+			key,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
+
+func (r *PostgresLoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	query := `DELETE FROM login_attempts WHERE key = $1`
+	_, err := r.db.ExecContext(ctx, query, key)
+	return err
+}
+
+func (r *PostgresLoginAttemptRepository) CleanupExpired(ctx context.Context, olderThan time.Time) error {
+	query := `
+		DELETE FROM login_attempts
+		WHERE (last_failure_at IS NULL OR last_failure_at < $1)
+		  AND (locked_until IS NULL OR locked_until < NOW())`
+	_, err := r.db.ExecContext(ctx, query, olderThan)
+	return err
+}
+
+func scanLoginAttempts(key string, row rowScanner) (*entities.LoginAttempts, error) {
+	var failures int
+	var lastFailureAt, lockedUntil sql.NullTime
+
+	if err := row.Scan(&failures, &lastFailureAt, &lockedUntil); err != nil {
+		return nil, err
+	}
+
+	return entities.ReconstructLoginAttempts(key, failures, lastFailureAt.Time, lockedUntil.Time), nil
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+	"github.com/zandomed/sync-playlist-api/pkg/database"
+)
+
+type PostgresRevokedTokenRepository struct {
+	db *database.DB
+}
+
+func NewPostgresRevokedTokenRepository(db *database.DB) repositories.RevokedTokenRepository {
+	return &PostgresRevokedTokenRepository{db: db}
+}
+
+func (r *PostgresRevokedTokenRepository) Revoke(ctx context.Context, tokenID string, userID valueobjects.UserID, expiresAt time.Time) error {
+	query := `
+		INSERT INTO revoked_access_tokens (jti, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (jti) DO NOTHING`
+
+	_, err := r.db.ExecContext(ctx, query, tokenID, userID.Value(), expiresAt)
+	return err
+}
+
+func (r *PostgresRevokedTokenRepository) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM revoked_access_tokens WHERE jti = $1)`
+
+	var revoked bool
+	err := r.db.QueryRowContext(ctx, query, tokenID).Scan(&revoked)
+	return revoked, err
+}
+
+func (r *PostgresRevokedTokenRepository) CleanupExpired(ctx context.Context) error {
+	query := `DELETE FROM revoked_access_tokens WHERE expires_at < NOW()`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
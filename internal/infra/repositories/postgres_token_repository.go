@@ -2,7 +2,9 @@ package repositories
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +15,8 @@ import (
 	"github.com/zandomed/sync-playlist-api/pkg/database"
 )
 
+const refreshTokenColumns = `session_id, user_id, token, access_token_jti, user_agent, ip_address, device_label, expires_at, created_at, last_used_at`
+
 type PostgresTokenRepository struct {
 	db *database.DB
 }
@@ -22,20 +26,105 @@ func NewPostgresTokenRepository(db *database.DB) repositories.TokenRepository {
 }
 
 func (r *PostgresTokenRepository) SaveRefreshToken(ctx context.Context, token *entities.RefreshToken) error {
+	return saveRefreshToken(ctx, r.db, token)
+}
+
+func (r *PostgresTokenRepository) RotateRefreshToken(ctx context.Context, previous string, next *entities.RefreshToken) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// A concurrent rotation of the same token waits on the row lock and then
+	// finds nothing to delete. The deleted token is remembered, so presenting
+	// it again can be told apart from a token removed on logout
+	query := `
+		WITH rotated AS (
+			DELETE FROM refresh_tokens WHERE token = $1
+			RETURNING session_id, user_id, expires_at
+		)
+		INSERT INTO rotated_refresh_tokens (token_hash, session_id, user_id, expires_at)
+		SELECT $2, session_id, user_id, expires_at FROM rotated
+		ON CONFLICT (token_hash) DO NOTHING`
+
+	result, err := tx.ExecContext(ctx, query, previous, refreshTokenHash(previous))
+	if err != nil {
+		return err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return errors.NewNotFoundError("refresh_token", "Refresh token not found")
+	}
+
+	if err := saveRefreshToken(ctx, tx, next); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresTokenRepository) FindRotatedSession(ctx context.Context, token string) (valueobjects.SessionID, error) {
+	query := `
+		SELECT session_id
+		FROM rotated_refresh_tokens
+		WHERE token_hash = $1 AND expires_at > NOW()`
+
+	var sessionID uuid.UUID
+	if err := r.db.QueryRowContext(ctx, query, refreshTokenHash(token)).Scan(&sessionID); err != nil {
+		if err == sql.ErrNoRows {
+			return valueobjects.SessionID{}, errors.NewNotFoundError("refresh_token", "Refresh token was not rotated")
+		}
+		return valueobjects.SessionID{}, err
+	}
+	return valueobjects.ReconstructSessionID(sessionID)
+}
+
+// refreshTokenHash identifies rotated tokens without keeping them usable
+func refreshTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func saveRefreshToken(ctx context.Context, db execer, token *entities.RefreshToken) error {
 	query := `
-		INSERT INTO refresh_tokens (user_id, token, expires_at, created_at)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO refresh_tokens (session_id, user_id, token, access_token_jti, user_agent, ip_address, device_label, expires_at, created_at, last_used_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		ON CONFLICT (token) DO UPDATE SET
+			access_token_jti = EXCLUDED.access_token_jti,
+			user_agent = EXCLUDED.user_agent,
+			ip_address = EXCLUDED.ip_address,
+			device_label = EXCLUDED.device_label,
 			expires_at = EXCLUDED.expires_at,
-			created_at = EXCLUDED.created_at`
+			created_at = EXCLUDED.created_at,
+			last_used_at = EXCLUDED.last_used_at`
 
-	_, err := r.db.ExecContext(
+	var accessTokenID interface{}
+	if token.AccessTokenID() != "" {
+		accessTokenID = token.AccessTokenID()
+	}
+
+	_, err := db.ExecContext(
 		ctx,
 		query,
+		token.SessionID().Value(),
 		token.UserID().Value(),
 		token.Token(),
+		accessTokenID,
+		token.Device().UserAgent(),
+		token.Device().IPAddress(),
+		token.Device().Label(),
 		token.ExpiresAt(),
 		token.CreatedAt(),
+		token.LastUsedAt(),
 	)
 
 	return err
@@ -43,31 +132,58 @@ func (r *PostgresTokenRepository) SaveRefreshToken(ctx context.Context, token *e
 
 func (r *PostgresTokenRepository) FindRefreshToken(ctx context.Context, token string) (*entities.RefreshToken, error) {
 	query := `
-		SELECT user_id, token, expires_at, created_at
+		SELECT ` + refreshTokenColumns + `
 		FROM refresh_tokens
 		WHERE token = $1`
 
-	var userIDStr, tokenStr string
-	var expiresAt, createdAt time.Time
-
-	err := r.db.QueryRowContext(ctx, query, token).Scan(
-		&userIDStr, &tokenStr, &expiresAt, &createdAt,
-	)
-
+	refreshToken, err := scanRefreshToken(r.db.QueryRowContext(ctx, query, token))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.NewNotFoundError("refresh_token", "Refresh token not found")
 		}
 		return nil, err
 	}
+	return refreshToken, nil
+}
 
-	userID, err := valueobjects.ReconstructUserID(uuid.MustParse(userIDStr))
+func (r *PostgresTokenRepository) FindUserRefreshTokens(ctx context.Context, userID valueobjects.UserID) ([]*entities.RefreshToken, error) {
+	query := `
+		SELECT ` + refreshTokenColumns + `
+		FROM refresh_tokens
+		WHERE user_id = $1
+		ORDER BY last_used_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID.Value())
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	var tokens []*entities.RefreshToken
+	for rows.Next() {
+		refreshToken, err := scanRefreshToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, refreshToken)
+	}
+
+	return tokens, rows.Err()
+}
+
+func (r *PostgresTokenRepository) FindSession(ctx context.Context, sessionID valueobjects.SessionID) (*entities.RefreshToken, error) {
+	// Rotation replaces the token of a session in one transaction, so a
+	// session has a single row
+	query := `
+		SELECT ` + refreshTokenColumns + `
+		FROM refresh_tokens
+		WHERE session_id = $1`
 
-	refreshToken, err := entities.ReconstructRefreshToken(userID, tokenStr, expiresAt, createdAt)
+	refreshToken, err := scanRefreshToken(r.db.QueryRowContext(ctx, query, sessionID.Value()))
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("session", "Session not found")
+		}
 		return nil, err
 	}
 	return refreshToken, nil
@@ -85,8 +201,64 @@ func (r *PostgresTokenRepository) DeleteUserRefreshTokens(ctx context.Context, u
 	return err
 }
 
+func (r *PostgresTokenRepository) DeleteSession(ctx context.Context, sessionID valueobjects.SessionID) error {
+	query := `DELETE FROM refresh_tokens WHERE session_id = $1`
+	_, err := r.db.ExecContext(ctx, query, sessionID.Value())
+	return err
+}
+
 func (r *PostgresTokenRepository) CleanupExpiredTokens(ctx context.Context) error {
 	query := `DELETE FROM refresh_tokens WHERE expires_at < NOW()`
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	query = `DELETE FROM rotated_refresh_tokens WHERE expires_at < NOW()`
 	_, err := r.db.ExecContext(ctx, query)
 	return err
 }
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRefreshToken(row rowScanner) (*entities.RefreshToken, error) {
+	var sessionIDStr, userIDStr, tokenStr string
+	var accessTokenID, userAgent, ipAddress, deviceLabel sql.NullString
+	var expiresAt, createdAt time.Time
+	var lastUsedAt sql.NullTime
+
+	err := row.Scan(
+		&sessionIDStr, &userIDStr, &tokenStr, &accessTokenID, &userAgent, &ipAddress, &deviceLabel,
+		&expiresAt, &createdAt, &lastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := valueobjects.ReconstructSessionID(uuid.MustParse(sessionIDStr))
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := valueobjects.ReconstructUserID(uuid.MustParse(userIDStr))
+	if err != nil {
+		return nil, err
+	}
+
+	lastUsed := createdAt
+	if lastUsedAt.Valid {
+		lastUsed = lastUsedAt.Time
+	}
+
+	return entities.ReconstructRefreshToken(
+		sessionID,
+		userID,
+		tokenStr,
+		accessTokenID.String,
+		valueobjects.ReconstructDeviceInfo(userAgent.String, ipAddress.String, deviceLabel.String),
+		expiresAt,
+		createdAt,
+		lastUsed,
+	)
+}
@@ -0,0 +1,236 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+	"github.com/zandomed/sync-playlist-api/pkg/database"
+)
+
+// PostgresTwoFactorRepository stores the TOTP secret encrypted with the same
+// cipher as the provider tokens
+type PostgresTwoFactorRepository struct {
+	db     *database.DB
+	cipher SecretCipher
+}
+
+func NewPostgresTwoFactorRepository(db *database.DB, cipher SecretCipher) repositories.TwoFactorRepository {
+	return &PostgresTwoFactorRepository{db: db, cipher: cipher}
+}
+
+func (r *PostgresTwoFactorRepository) Save(ctx context.Context, twoFactor *entities.TwoFactor) error {
+	secret, err := r.encryptSecret(twoFactor.UserID(), twoFactor.Secret())
+	if err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *sqlx.Tx) error {
+		query := `
+			INSERT INTO user_two_factor (user_id, totp_secret, enabled, last_used_step, confirmed_at, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (user_id) DO UPDATE SET
+				totp_secret = EXCLUDED.totp_secret,
+				enabled = EXCLUDED.enabled,
+				last_used_step = EXCLUDED.last_used_step,
+				confirmed_at = EXCLUDED.confirmed_at,
+				created_at = EXCLUDED.created_at,
+				updated_at = EXCLUDED.updated_at`
+
+		_, err := tx.ExecContext(
+			ctx,
+			query,
+			twoFactor.UserID().Value(),
+			secret,
+			twoFactor.IsEnabled(),
+			twoFactor.LastUsedStep(),
+			twoFactor.ConfirmedAt(),
+			twoFactor.CreatedAt(),
+			twoFactor.UpdatedAt(),
+		)
+		if err != nil {
+			return err
+		}
+
+		// The set of recovery codes is replaced as a whole
+		_, err = tx.ExecContext(ctx, `DELETE FROM two_factor_recovery_codes WHERE user_id = $1`, twoFactor.UserID().Value())
+		if err != nil {
+			return err
+		}
+
+		for _, code := range twoFactor.RecoveryCodes() {
+			_, err = tx.ExecContext(
+				ctx,
+				`INSERT INTO two_factor_recovery_codes (user_id, code_hash, used_at) VALUES ($1, $2, $3)`,
+				twoFactor.UserID().Value(),
+				code.Hash(),
+				code.UsedAt(),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// RecordUse matches the recovery code while it is unused, or the TOTP step while
+// it is newer than the last accepted one, like the verification token Update
+func (r *PostgresTwoFactorRepository) RecordUse(ctx context.Context, twoFactor *entities.TwoFactor) error {
+	var result sql.Result
+	var err error
+
+	if code := twoFactor.UsedRecoveryCode(); code != nil {
+		query := `
+			UPDATE two_factor_recovery_codes
+			SET used_at = $1
+			WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL`
+		result, err = r.db.ExecContext(ctx, query, code.UsedAt(), twoFactor.UserID().Value(), code.Hash())
+	} else {
+		query := `
+			UPDATE user_two_factor
+			SET last_used_step = $1, updated_at = $2
+			WHERE user_id = $3 AND last_used_step < $1`
+		result, err = r.db.ExecContext(ctx, query, twoFactor.LastUsedStep(), twoFactor.UpdatedAt(), twoFactor.UserID().Value())
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.NewAuthenticationError("mfa_code_reused", "This code has already been used")
+	}
+	return nil
+}
+
+func (r *PostgresTwoFactorRepository) FindByUserID(ctx context.Context, userID valueobjects.UserID) (*entities.TwoFactor, error) {
+	query := `
+		SELECT totp_secret, enabled, last_used_step, confirmed_at, created_at, updated_at
+		FROM user_two_factor
+		WHERE user_id = $1`
+
+	var secretStr string
+	var enabled bool
+	var lastUsedStep int64
+	var confirmedAt sql.NullTime
+	var createdAt, updatedAt time.Time
+
+	err := r.db.QueryRowContext(ctx, query, userID.Value()).Scan(
+		&secretStr, &enabled, &lastUsedStep, &confirmedAt, &createdAt, &updatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("two_factor", "Two-factor authentication is not set up")
+		}
+		return nil, err
+	}
+
+	secret, err := r.decryptSecret(ctx, userID, secretStr)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := r.findRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var confirmedAtPtr *time.Time
+	if confirmedAt.Valid {
+		confirmedAtPtr = &confirmedAt.Time
+	}
+
+	return entities.ReconstructTwoFactor(
+		userID,
+		secret,
+		enabled,
+		lastUsedStep,
+		recoveryCodes,
+		confirmedAtPtr,
+		createdAt,
+		updatedAt,
+	), nil
+}
+
+func (r *PostgresTwoFactorRepository) findRecoveryCodes(ctx context.Context, userID valueobjects.UserID) ([]entities.RecoveryCode, error) {
+	query := `SELECT code_hash, used_at FROM two_factor_recovery_codes WHERE user_id = $1`
+
+	rows, err := r.db.QueryContext(ctx, query, userID.Value())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codes []entities.RecoveryCode
+	for rows.Next() {
+		var hash string
+		var usedAt sql.NullTime
+		if err := rows.Scan(&hash, &usedAt); err != nil {
+			return nil, err
+		}
+
+		var usedAtPtr *time.Time
+		if usedAt.Valid {
+			usedAtPtr = &usedAt.Time
+		}
+		codes = append(codes, entities.ReconstructRecoveryCode(hash, usedAtPtr))
+	}
+
+	return codes, rows.Err()
+}
+
+func (r *PostgresTwoFactorRepository) Delete(ctx context.Context, userID valueobjects.UserID) error {
+	query := `DELETE FROM user_two_factor WHERE user_id = $1`
+	_, err := r.db.ExecContext(ctx, query, userID.Value())
+	return err
+}
+
+func (r *PostgresTwoFactorRepository) encryptSecret(userID valueobjects.UserID, secret valueobjects.TOTPSecret) (string, error) {
+	ciphertext, err := r.cipher.Encrypt([]byte(secret.String()), totpSecretAssociatedData(userID))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt totp_secret: %w", err)
+	}
+	return ciphertext, nil
+}
+
+// decryptSecret also reads secrets stored in plain base32 before they were
+// encrypted, and rewrites them encrypted. A ciphertext never parses as base32
+func (r *PostgresTwoFactorRepository) decryptSecret(ctx context.Context, userID valueobjects.UserID, stored string) (valueobjects.TOTPSecret, error) {
+	plaintext, err := r.cipher.Decrypt(stored, totpSecretAssociatedData(userID))
+	if err == nil {
+		return valueobjects.ReconstructTOTPSecret(string(plaintext))
+	}
+
+	secret, legacyErr := valueobjects.ReconstructTOTPSecret(stored)
+	if legacyErr != nil {
+		return valueobjects.TOTPSecret{}, fmt.Errorf("failed to decrypt totp_secret of user %s: %w", userID.String(), err)
+	}
+
+	ciphertext, err := r.encryptSecret(userID, secret)
+	if err != nil {
+		return valueobjects.TOTPSecret{}, err
+	}
+
+	query := `UPDATE user_two_factor SET totp_secret = $1 WHERE user_id = $2 AND totp_secret = $3`
+	if _, err := r.db.ExecContext(ctx, query, ciphertext, userID.Value(), stored); err != nil {
+		return valueobjects.TOTPSecret{}, err
+	}
+	return secret, nil
+}
+
+// totpSecretAssociatedData binds the ciphertext to its user, like
+// tokenAssociatedData does for account tokens
+func totpSecretAssociatedData(userID valueobjects.UserID) []byte {
+	return []byte("user_two_factor:" + userID.String() + ":totp_secret")
+}
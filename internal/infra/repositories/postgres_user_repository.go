@@ -23,12 +23,13 @@ func NewPostgresUserRepository(db *database.DB) repositories.UserRepository {
 
 func (r *PostgresUserRepository) Save(ctx context.Context, user *entities.User) error {
 	query := `
-		INSERT INTO users (id, email, name, last_name, is_email_verified, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO users (id, email, name, last_name, role, is_email_verified, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		ON CONFLICT (id) DO UPDATE SET
 			email = EXCLUDED.email,
 			name = EXCLUDED.name,
 			last_name = EXCLUDED.last_name,
+			role = EXCLUDED.role,
 			is_email_verified = EXCLUDED.is_email_verified,
 			updated_at = EXCLUDED.updated_at`
 
@@ -39,6 +40,7 @@ func (r *PostgresUserRepository) Save(ctx context.Context, user *entities.User)
 		user.Email().Value(),
 		user.Profile().Name(),
 		user.Profile().LastName(),
+		string(user.Role()),
 		user.IsEmailVerified(),
 		user.CreatedAt(),
 		user.UpdatedAt(),
@@ -49,16 +51,16 @@ func (r *PostgresUserRepository) Save(ctx context.Context, user *entities.User)
 
 func (r *PostgresUserRepository) FindByID(ctx context.Context, id valueobjects.UserID) (*entities.User, error) {
 	query := `
-		SELECT id, email, name, last_name, is_email_verified, created_at, updated_at
+		SELECT id, email, name, last_name, role, is_email_verified, created_at, updated_at
 		FROM users
 		WHERE id = $1`
 
-	var userID, email, name, lastName string
+	var userID, email, name, lastName, role string
 	var isEmailVerified bool
 	var createdAt, updatedAt time.Time
 
 	err := r.db.QueryRowContext(ctx, query, id.Value()).Scan(
-		&userID, &email, &name, &lastName, &isEmailVerified, &createdAt, &updatedAt,
+		&userID, &email, &name, &lastName, &role, &isEmailVerified, &createdAt, &updatedAt,
 	)
 
 	if err != nil {
@@ -73,21 +75,21 @@ func (r *PostgresUserRepository) FindByID(ctx context.Context, id valueobjects.U
 		return nil, err
 	}
 
-	return entities.ReconstructUser(parsedID, email, name, lastName, isEmailVerified, createdAt, updatedAt)
+	return entities.ReconstructUser(parsedID, email, name, lastName, entities.Role(role), isEmailVerified, createdAt, updatedAt)
 }
 
 func (r *PostgresUserRepository) FindByEmail(ctx context.Context, email valueobjects.Email) (*entities.User, error) {
 	query := `
-		SELECT id, email, name, last_name, is_email_verified, created_at, updated_at
+		SELECT id, email, name, last_name, role, is_email_verified, created_at, updated_at
 		FROM users
 		WHERE email = $1`
 
-	var userID, emailStr, name, lastName string
+	var userID, emailStr, name, lastName, role string
 	var isEmailVerified bool
 	var createdAt, updatedAt time.Time
 
 	err := r.db.QueryRowContext(ctx, query, email.Value()).Scan(
-		&userID, &emailStr, &name, &lastName, &isEmailVerified, &createdAt, &updatedAt,
+		&userID, &emailStr, &name, &lastName, &role, &isEmailVerified, &createdAt, &updatedAt,
 	)
 
 	if err != nil {
@@ -102,7 +104,7 @@ func (r *PostgresUserRepository) FindByEmail(ctx context.Context, email valueobj
 		return nil, err
 	}
 
-	return entities.ReconstructUser(parsedID, emailStr, name, lastName, isEmailVerified, createdAt, updatedAt)
+	return entities.ReconstructUser(parsedID, emailStr, name, lastName, entities.Role(role), isEmailVerified, createdAt, updatedAt)
 }
 
 func (r *PostgresUserRepository) Exists(ctx context.Context, email valueobjects.Email) (bool, error) {
@@ -23,8 +23,8 @@ func NewPostgresVerificationRepository(db *database.DB) repositories.Verificatio
 
 func (r *PostgresVerificationRepository) Save(ctx context.Context, token *entities.VerificationToken) error {
 	query := `
-		INSERT INTO verification_tokens (token, token_type, user_id, expires_at, created_at, used_at)
-		VALUES ($1, $2, $3, $4, $5, $6)`
+		INSERT INTO verification_tokens (token, token_type, user_id, intent, code_verifier, provider, browser_nonce, client_challenge, expires_at, created_at, used_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
 
 	var userIDValue interface{}
 	if token.UserID() != nil {
@@ -37,6 +37,11 @@ func (r *PostgresVerificationRepository) Save(ctx context.Context, token *entiti
 		token.Token(),
 		string(token.TokenType()),
 		userIDValue,
+		nullString(string(token.Intent())),
+		nullString(token.CodeVerifier()),
+		nullString(string(token.Provider())),
+		nullString(token.BrowserNonce()),
+		nullString(token.ClientChallenge()),
 		token.ExpiresAt(),
 		token.CreatedAt(),
 		token.UsedAt(),
@@ -47,17 +52,17 @@ func (r *PostgresVerificationRepository) Save(ctx context.Context, token *entiti
 
 func (r *PostgresVerificationRepository) FindByToken(ctx context.Context, tokenStr string) (*entities.VerificationToken, error) {
 	query := `
-		SELECT token, token_type, user_id, expires_at, created_at, used_at
+		SELECT token, token_type, user_id, intent, code_verifier, provider, browser_nonce, client_challenge, expires_at, created_at, used_at
 		FROM verification_tokens
 		WHERE token = $1`
 
 	var token, tokenType string
-	var userIDStr sql.NullString
+	var userIDStr, intent, codeVerifier, provider, browserNonce, clientChallenge sql.NullString
 	var expiresAt, createdAt time.Time
 	var usedAt sql.NullTime
 
 	err := r.db.QueryRowContext(ctx, query, tokenStr).Scan(
-		&token, &tokenType, &userIDStr, &expiresAt, &createdAt, &usedAt,
+		&token, &tokenType, &userIDStr, &intent, &codeVerifier, &provider, &browserNonce, &clientChallenge, &expiresAt, &createdAt, &usedAt,
 	)
 
 	if err != nil {
@@ -89,6 +94,11 @@ func (r *PostgresVerificationRepository) FindByToken(ctx context.Context, tokenS
 		token,
 		entities.VerificationTokenType(tokenType),
 		userID,
+		entities.OAuthIntent(intent.String),
+		codeVerifier.String,
+		entities.AccountProvider(provider.String),
+		browserNonce.String,
+		clientChallenge.String,
 		expiresAt,
 		createdAt,
 		usedAtPtr,
@@ -100,14 +110,27 @@ func (r *PostgresVerificationRepository) FindByToken(ctx context.Context, tokenS
 	return verificationToken, nil
 }
 
+// Update only matches a token that is still unused, so of two concurrent
+// requests redeeming the same token exactly one succeeds
 func (r *PostgresVerificationRepository) Update(ctx context.Context, token *entities.VerificationToken) error {
 	query := `
 		UPDATE verification_tokens
 		SET used_at = $1
-		WHERE token = $2`
+		WHERE token = $2 AND used_at IS NULL`
 
-	_, err := r.db.ExecContext(ctx, query, token.UsedAt(), token.Token())
-	return err
+	result, err := r.db.ExecContext(ctx, query, token.UsedAt(), token.Token())
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.NewDomainError("token_already_used", "Token has already been used")
+	}
+	return nil
 }
 
 func (r *PostgresVerificationRepository) Delete(ctx context.Context, token string) error {
@@ -116,6 +139,12 @@ func (r *PostgresVerificationRepository) Delete(ctx context.Context, token strin
 	return err
 }
 
+func (r *PostgresVerificationRepository) DeleteByUser(ctx context.Context, userID valueobjects.UserID, tokenType entities.VerificationTokenType) error {
+	query := `DELETE FROM verification_tokens WHERE user_id = $1 AND token_type = $2`
+	_, err := r.db.ExecContext(ctx, query, userID.Value(), string(tokenType))
+	return err
+}
+
 func (r *PostgresVerificationRepository) CleanupExpired(ctx context.Context) error {
 	query := `DELETE FROM verification_tokens WHERE expires_at < NOW()`
 	_, err := r.db.ExecContext(ctx, query)
@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/zandomed/sync-playlist-api/pkg/jwtkeys"
+	"golang.org/x/oauth2"
+)
+
+const (
+	AppleIssuer    = "https://appleid.apple.com"
+	AppleJWKSURL   = "https://appleid.apple.com/auth/keys"
+	AppleRevokeURL = "https://appleid.apple.com/auth/revoke"
+
+	// Apple accepts client secrets valid for up to six months; a short-lived
+	// one limits the damage if it leaks from logs
+	appleClientSecretTTL = time.Hour
+	// appleClientSecretRenewal regenerates the secret before Apple rejects it
+	appleClientSecretRenewal = 5 * time.Minute
+	// appleJWKSCacheTTL is how long Apple's public keys are reused; unknown
+	// kids trigger an earlier refresh
+	appleJWKSCacheTTL = 24 * time.Hour
+)
+
+var appleEndpoint = oauth2.Endpoint{
+	AuthURL:   "https://appleid.apple.com/auth/authorize",
+	TokenURL:  "https://appleid.apple.com/auth/token",
+	AuthStyle: oauth2.AuthStyleInParams,
+}
+
+type AppleOAuthService struct {
+	config *oauth2.Config
+	teamID string
+	// signer holds the ES256 key downloaded from the Apple Developer portal
+	signer *jwtkeys.KeyRing
+	keySet *jwtkeys.RemoteKeySet
+
+	mu                 sync.Mutex
+	clientSecret       string
+	clientSecretExpiry time.Time
+}
+
+type AppleUserInfo struct {
+	Subject        string
+	Email          string
+	EmailVerified  bool
+	IsPrivateEmail bool
+}
+
+// appleBool decodes Apple's boolean claims, which are sometimes sent as strings
+type appleBool bool
+
+func (b *appleBool) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case bool:
+		*b = appleBool(v)
+	case string:
+		*b = appleBool(v == "true")
+	default:
+		*b = false
+	}
+	return nil
+}
+
+type appleIDTokenClaims struct {
+	Email          string    `json:"email"`
+	EmailVerified  appleBool `json:"email_verified"`
+	IsPrivateEmail appleBool `json:"is_private_email"`
+	jwt.RegisteredClaims
+}
+
+// NewAppleOAuthService configures Sign in with Apple. clientID is the Services
+// ID, and privateKey the PEM of the key identified by keyID. Without a private
+// key the service is created but every code exchange fails, so deployments
+// that do not offer Apple still start
+func NewAppleOAuthService(
+	clientID, teamID, keyID, privateKey, redirectURL string,
+	jwksFetcher jwtkeys.JWKSFetcher,
+) (*AppleOAuthService, error) {
+	service := &AppleOAuthService{
+		config: &oauth2.Config{
+			ClientID:    clientID,
+			RedirectURL: redirectURL,
+			Scopes:      []string{"name", "email"},
+			Endpoint:    appleEndpoint,
+		},
+		teamID: teamID,
+		keySet: jwtkeys.NewRemoteKeySet(jwksFetcher, appleJWKSCacheTTL),
+	}
+
+	if privateKey == "" {
+		return service, nil
+	}
+
+	// Env files usually carry the PEM on a single line with escaped newlines
+	key, err := jwtkeys.ParsePrivateKeyPEM(keyID, []byte(strings.ReplaceAll(privateKey, `\n`, "\n")))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Apple private key: %w", err)
+	}
+
+	if key.Algorithm() != jwt.SigningMethodES256.Alg() {
+		return nil, fmt.Errorf("invalid Apple private key: expected an ES256 key, got %s", key.Algorithm())
+	}
+
+	service.signer, err = jwtkeys.NewKeyRing(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+// GetAuthURL asks Apple to POST the result back, which is required when the
+// name or email scopes are requested
+func (s *AppleOAuthService) GetAuthURL(state string) string {
+	return s.config.AuthCodeURL(state, oauth2.SetAuthURLParam("response_mode", "form_post"))
+}
+
+func (s *AppleOAuthService) ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error) {
+	clientSecret, err := s.getClientSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	config := *s.config
+	config.ClientSecret = clientSecret
+
+	token, err := config.Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	return token, nil
+}
+
+// RevokeToken revokes a refresh or access token, which Apple requires apps to
+// do when the user disconnects Sign in with Apple
+func (s *AppleOAuthService) RevokeToken(ctx context.Context, token, tokenTypeHint string) error {
+	clientSecret, err := s.getClientSecret()
+	if err != nil {
+		return err
+	}
+
+	return revokeToken(ctx, AppleRevokeURL, url.Values{
+		"client_id":       {s.config.ClientID},
+		"client_secret":   {clientSecret},
+		"token":           {token},
+		"token_type_hint": {tokenTypeHint},
+	})
+}
+
+// VerifyIDToken checks the id_token signature against Apple's published keys
+// and that it was issued by Apple for this client
+func (s *AppleOAuthService) VerifyIDToken(ctx context.Context, idToken string) (*AppleUserInfo, error) {
+	var claims appleIDTokenClaims
+
+	_, err := s.keySet.Parse(
+		ctx,
+		idToken,
+		&claims,
+		jwt.WithIssuer(AppleIssuer),
+		jwt.WithAudience(s.config.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	if claims.Subject == "" {
+		return nil, errors.New("invalid id_token: missing subject")
+	}
+
+	return &AppleUserInfo{
+		Subject:        claims.Subject,
+		Email:          claims.Email,
+		EmailVerified:  bool(claims.EmailVerified),
+		IsPrivateEmail: bool(claims.IsPrivateEmail),
+	}, nil
+}
+
+// getClientSecret returns the ES256-signed JWT Apple expects as client_secret,
+// reusing it until it is about to expire
+func (s *AppleOAuthService) getClientSecret() (string, error) {
+	if s.signer == nil {
+		return "", errors.New("sign in with Apple is not configured")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.clientSecret != "" && now.Add(appleClientSecretRenewal).Before(s.clientSecretExpiry) {
+		return s.clientSecret, nil
+	}
+
+	expiresAt := now.Add(appleClientSecretTTL)
+	clientSecret, err := s.signer.Sign(jwt.RegisteredClaims{
+		Issuer:    s.teamID,
+		Subject:   s.config.ClientID,
+		Audience:  jwt.ClaimStrings{AppleIssuer},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to sign Apple client secret: %w", err)
+	}
+
+	s.clientSecret = clientSecret
+	s.clientSecretExpiry = expiresAt
+	return clientSecret, nil
+}
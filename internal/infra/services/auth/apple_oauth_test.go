@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/zandomed/sync-playlist-api/pkg/jwtkeys"
+)
+
+const testAppleClientID = "com.example.sync.web"
+
+func newTestAppleKeyRing(t *testing.T, kid string) *jwtkeys.KeyRing {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+	}
+
+	key, err := jwtkeys.ParsePrivateKeyPEM(kid, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("ParsePrivateKeyPEM: %v", err)
+	}
+
+	ring, err := jwtkeys.NewKeyRing(key)
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	return ring
+}
+
+func TestAppleVerifyIDToken(t *testing.T) {
+	apple := newTestAppleKeyRing(t, "apple-1")
+	attacker := newTestAppleKeyRing(t, "apple-2")
+
+	fetcher := func(ctx context.Context) (*jwtkeys.JSONWebKeySet, error) {
+		set := apple.JWKS()
+		return &set, nil
+	}
+
+	service, err := NewAppleOAuthService(testAppleClientID, "TEAMID", "", "", "https://api.example.com/v1/oauth/apple/callback", fetcher)
+	if err != nil {
+		t.Fatalf("NewAppleOAuthService: %v", err)
+	}
+
+	now := time.Now()
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":            AppleIssuer,
+			"aud":            testAppleClientID,
+			"sub":            "001234.abcd",
+			"iat":            now.Unix(),
+			"exp":            now.Add(10 * time.Minute).Unix(),
+			"email":          "relay@privaterelay.appleid.com",
+			"email_verified": "true",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		signer  *jwtkeys.KeyRing
+		edit    func(claims jwt.MapClaims)
+		wantErr bool
+		want    AppleUserInfo
+	}{
+		{
+			name:   "boolean claims sent as strings",
+			signer: apple,
+			edit:   func(claims jwt.MapClaims) { claims["is_private_email"] = "true" },
+			want:   AppleUserInfo{Subject: "001234.abcd", Email: "relay@privaterelay.appleid.com", EmailVerified: true, IsPrivateEmail: true},
+		},
+		{
+			name:   "boolean claims sent as booleans",
+			signer: apple,
+			edit:   func(claims jwt.MapClaims) { claims["email_verified"] = false },
+			want:   AppleUserInfo{Subject: "001234.abcd", Email: "relay@privaterelay.appleid.com"},
+		},
+		{"issued for another client", apple, func(claims jwt.MapClaims) { claims["aud"] = "com.other.app" }, true, AppleUserInfo{}},
+		{"not issued by Apple", apple, func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" }, true, AppleUserInfo{}},
+		{"expired", apple, func(claims jwt.MapClaims) { claims["exp"] = now.Add(-time.Minute).Unix() }, true, AppleUserInfo{}},
+		{"without expiry", apple, func(claims jwt.MapClaims) { delete(claims, "exp") }, true, AppleUserInfo{}},
+		{"without subject", apple, func(claims jwt.MapClaims) { delete(claims, "sub") }, true, AppleUserInfo{}},
+		{"signed with a key Apple does not publish", attacker, func(claims jwt.MapClaims) {}, true, AppleUserInfo{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := valid()
+			tt.edit(claims)
+
+			idToken, err := tt.signer.Sign(claims)
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+
+			got, err := service.VerifyIDToken(context.Background(), idToken)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("VerifyIDToken accepted the token: %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyIDToken: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("VerifyIDToken = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
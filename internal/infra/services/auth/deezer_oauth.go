@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DeezerPermissions are granted as a whole: Deezer has no partial consent, so
+// they double as the scopes of the linked account. offline_access makes the
+// access token never expire, as Deezer has no refresh tokens
+var DeezerPermissions = []string{
+	"basic_access",
+	"email",
+	"offline_access",
+	"manage_library",
+	"delete_library",
+}
+
+// DeezerOAuthService implements Deezer's OAuth, which follows the
+// authorization code flow but not OAuth 2.0: the exchange is a GET with the
+// app secret in the query, there is no PKCE and no refresh token
+type DeezerOAuthService struct {
+	appID       string
+	secret      string
+	redirectURL string
+	connectURL  string
+	APIUrl      string
+	httpClient  *http.Client
+}
+
+type DeezerToken struct {
+	AccessToken string `json:"access_token"`
+	// Expires is the lifetime in seconds, 0 for offline_access tokens
+	Expires json.Number `json:"expires"`
+}
+
+type DeezerUserInfo struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	FirstName string `json:"firstname"`
+	LastName  string `json:"lastname"`
+	Email     string `json:"email"`
+	Picture   string `json:"picture_medium"`
+	Error     *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func NewDeezerOAuthService(appID, secret, redirectURL, connectURL, apiUrl string) *DeezerOAuthService {
+	return &DeezerOAuthService{
+		appID:       appID,
+		secret:      secret,
+		redirectURL: redirectURL,
+		connectURL:  strings.TrimRight(connectURL, "/"),
+		APIUrl:      strings.TrimRight(apiUrl, "/"),
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *DeezerOAuthService) GetAuthURL(state string) string {
+	query := url.Values{}
+	query.Set("app_id", s.appID)
+	query.Set("redirect_uri", s.redirectURL)
+	query.Set("perms", strings.Join(DeezerPermissions, ","))
+	query.Set("state", state)
+
+	return fmt.Sprintf("%s/oauth/auth.php?%s", s.connectURL, query.Encode())
+}
+
+func (s *DeezerOAuthService) ExchangeCode(ctx context.Context, code string) (*DeezerToken, error) {
+	query := url.Values{}
+	query.Set("app_id", s.appID)
+	query.Set("secret", s.secret)
+	query.Set("code", code)
+	query.Set("output", "json")
+
+	body, err := s.get(ctx, fmt.Sprintf("%s/oauth/access_token.php?%s", s.connectURL, query.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	// Invalid codes are answered with 200 and a plain text body such as "wrong code"
+	var token DeezerToken
+	if err := json.Unmarshal(body, &token); err != nil || token.AccessToken == "" {
+		return nil, fmt.Errorf("failed to exchange code: %s", strings.TrimSpace(string(body)))
+	}
+
+	return &token, nil
+}
+
+func (s *DeezerOAuthService) GetUserInfo(ctx context.Context, accessToken string) (*DeezerUserInfo, error) {
+	body, err := s.get(ctx, fmt.Sprintf("%s/user/me?access_token=%s", s.APIUrl, url.QueryEscape(accessToken)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	var userInfo DeezerUserInfo
+	if err := json.Unmarshal(body, &userInfo); err != nil {
+		return nil, fmt.Errorf("failed to parse user info: %w", err)
+	}
+
+	// Deezer reports API errors in the body of 200 responses
+	if userInfo.Error != nil {
+		return nil, fmt.Errorf("deezer API error (%s): %s", userInfo.Error.Type, userInfo.Error.Message)
+	}
+
+	return &userInfo, nil
+}
+
+func (s *DeezerOAuthService) get(ctx context.Context, endpoint string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		// The URL carries the app secret or the user's token, so it is left out
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("deezer request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("deezer error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	return body, nil
+}
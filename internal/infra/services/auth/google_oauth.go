@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -11,6 +12,8 @@ import (
 	"google.golang.org/api/option"
 )
 
+const GoogleRevokeURL = "https://oauth2.googleapis.com/revoke"
+
 type GoogleOAuthService struct {
 	config *oauth2.Config
 }
@@ -40,18 +43,33 @@ func NewGoogleOAuthService(clientID, clientSecret, redirectURL string) *GoogleOA
 	}
 }
 
-func (s *GoogleOAuthService) GetAuthURL(state string) string {
-	return s.config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+func (s *GoogleOAuthService) GetAuthURL(state, codeVerifier string) string {
+	return s.config.AuthCodeURL(state, withCodeChallenge(codeVerifier, oauth2.AccessTypeOffline)...)
 }
 
-func (s *GoogleOAuthService) ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error) {
-	token, err := s.config.Exchange(ctx, code)
+func (s *GoogleOAuthService) ExchangeCode(ctx context.Context, code, codeVerifier string) (*oauth2.Token, error) {
+	token, err := s.config.Exchange(ctx, code, withCodeVerifier(codeVerifier)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange code: %w", err)
 	}
 	return token, nil
 }
 
+// RefreshToken asks the token endpoint for a new access token. The refresh
+// token in the result is the same one when the provider does not rotate it
+func (s *GoogleOAuthService) RefreshToken(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
+	token, err := s.config.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+	return token, nil
+}
+
+// RevokeToken revokes the grant; revoking either token invalidates both
+func (s *GoogleOAuthService) RevokeToken(ctx context.Context, token string) error {
+	return revokeToken(ctx, GoogleRevokeURL, url.Values{"token": {token}})
+}
+
 func (s *GoogleOAuthService) GetUserInfo(ctx context.Context, token *oauth2.Token) (*GoogleUserInfo, error) {
 	client := s.config.Client(ctx, token)
 
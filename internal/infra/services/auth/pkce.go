@@ -0,0 +1,21 @@
+package auth
+
+import "golang.org/x/oauth2"
+
+// withCodeChallenge adds the S256 PKCE challenge of codeVerifier to the
+// authorization URL options
+func withCodeChallenge(codeVerifier string, opts ...oauth2.AuthCodeOption) []oauth2.AuthCodeOption {
+	if codeVerifier == "" {
+		return opts
+	}
+	return append(opts, oauth2.S256ChallengeOption(codeVerifier))
+}
+
+// withCodeVerifier sends codeVerifier on the code exchange. Flows started
+// before PKCE was enabled have no verifier and are exchanged without one
+func withCodeVerifier(codeVerifier string) []oauth2.AuthCodeOption {
+	if codeVerifier == "" {
+		return nil
+	}
+	return []oauth2.AuthCodeOption{oauth2.VerifierOption(codeVerifier)}
+}
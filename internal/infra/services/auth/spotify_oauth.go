@@ -38,6 +38,8 @@ func NewSpotifyOAuthService(clientID, clientSecret, redirectURL, apiUrl string)
 				"user-read-private",
 				"playlist-read-private",
 				"playlist-read-collaborative",
+				"playlist-modify-private",
+				"playlist-modify-public",
 			},
 			Endpoint: spotify.Endpoint,
 		},
@@ -45,18 +47,28 @@ func NewSpotifyOAuthService(clientID, clientSecret, redirectURL, apiUrl string)
 	}
 }
 
-func (s *SpotifyOAuthService) GetAuthURL(state string) string {
-	return s.config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+func (s *SpotifyOAuthService) GetAuthURL(state, codeVerifier string) string {
+	return s.config.AuthCodeURL(state, withCodeChallenge(codeVerifier, oauth2.AccessTypeOffline)...)
 }
 
-func (s *SpotifyOAuthService) ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error) {
-	token, err := s.config.Exchange(ctx, code)
+func (s *SpotifyOAuthService) ExchangeCode(ctx context.Context, code, codeVerifier string) (*oauth2.Token, error) {
+	token, err := s.config.Exchange(ctx, code, withCodeVerifier(codeVerifier)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange code: %w", err)
 	}
 	return token, nil
 }
 
+// RefreshToken asks the token endpoint for a new access token. The refresh
+// token in the result is the same one when the provider does not rotate it
+func (s *SpotifyOAuthService) RefreshToken(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
+	token, err := s.config.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+	return token, nil
+}
+
 func (s *SpotifyOAuthService) GetUserInfo(ctx context.Context, token *oauth2.Token) (*SpotifyUserInfo, error) {
 	client := s.config.Client(ctx, token)
 
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+type TidalOAuthService struct {
+	config *oauth2.Config
+	APIUrl string
+}
+
+// TidalUserInfo is the JSON:API document of the current user
+type TidalUserInfo struct {
+	Data struct {
+		ID         string `json:"id"`
+		Attributes struct {
+			Username      string `json:"username"`
+			Country       string `json:"country"`
+			Email         string `json:"email"`
+			EmailVerified bool   `json:"emailVerified"`
+			FirstName     string `json:"firstName"`
+			LastName      string `json:"lastName"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+// NewTidalOAuthService takes the login and auth bases separately, as Tidal
+// serves the consent screen and the token endpoint from different hosts
+func NewTidalOAuthService(clientID, clientSecret, redirectURL, loginURL, authURL, apiUrl string) *TidalOAuthService {
+	return &TidalOAuthService{
+		config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes: []string{
+				"user.read",
+				"collection.read",
+				"collection.write",
+				"playlists.read",
+				"playlists.write",
+				"search.read",
+			},
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  strings.TrimRight(loginURL, "/") + "/authorize",
+				TokenURL: strings.TrimRight(authURL, "/") + "/oauth2/token",
+			},
+		},
+		APIUrl: strings.TrimRight(apiUrl, "/"),
+	}
+}
+
+func (s *TidalOAuthService) GetAuthURL(state, codeVerifier string) string {
+	return s.config.AuthCodeURL(state, withCodeChallenge(codeVerifier)...)
+}
+
+func (s *TidalOAuthService) ExchangeCode(ctx context.Context, code, codeVerifier string) (*oauth2.Token, error) {
+	token, err := s.config.Exchange(ctx, code, withCodeVerifier(codeVerifier)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	return token, nil
+}
+
+// RefreshToken asks the token endpoint for a new access token. The refresh
+// token in the result is the same one when the provider does not rotate it
+func (s *TidalOAuthService) RefreshToken(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
+	token, err := s.config.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+	return token, nil
+}
+
+func (s *TidalOAuthService) GetUserInfo(ctx context.Context, token *oauth2.Token) (*TidalUserInfo, error) {
+	client := s.config.Client(ctx, token)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/users/me", s.APIUrl), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.api+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("tidal API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var userInfo TidalUserInfo
+	if err := json.Unmarshal(body, &userInfo); err != nil {
+		return nil, fmt.Errorf("failed to parse user info: %w", err)
+	}
+
+	return &userInfo, nil
+}
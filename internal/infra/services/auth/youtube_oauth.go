@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// YouTubeScope lets the app manage the user's YouTube playlists
+const YouTubeScope = "https://www.googleapis.com/auth/youtube"
+
+// NewYouTubeOAuthService configures the Google client for the youtube link
+// flow. The profile scopes are kept so the linked account is identified by the
+// Google user, and sign in with Google stays free of the youtube scope
+func NewYouTubeOAuthService(clientID, clientSecret, redirectURL string) *GoogleOAuthService {
+	return &GoogleOAuthService{
+		config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes: []string{
+				"https://www.googleapis.com/auth/userinfo.email",
+				"https://www.googleapis.com/auth/userinfo.profile",
+				YouTubeScope,
+			},
+			Endpoint: google.Endpoint,
+		},
+	}
+}
@@ -0,0 +1,180 @@
+package music
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 3
+	// defaultRetryAfter is waited when a 429 comes without a usable header
+	defaultRetryAfter = time.Second
+	// maxRetryAfter bounds a single wait; longer ones fail the call so the
+	// caller can resume later instead of holding the request open
+	maxRetryAfter = time.Minute
+)
+
+// APIError is a non-2xx answer from a music service API
+type APIError struct {
+	Service    string
+	StatusCode int
+	Body       string
+	// RetryAfter is set on 429 answers that were not retried
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API error (status %d): %s", e.Service, e.StatusCode, e.Body)
+}
+
+// apiClient sends JSON requests to a music service API, retrying rate limited
+// calls (429) after the delay in their Retry-After header
+type apiClient struct {
+	service    string
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+}
+
+func newAPIClient(service string, client *http.Client, baseURL string) apiClient {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return apiClient{
+		service:    service,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: client,
+		maxRetries: defaultMaxRetries,
+	}
+}
+
+type apiRequest struct {
+	method string
+	// path is relative to the base URL
+	path   string
+	query  url.Values
+	header http.Header
+	// body is sent as JSON when not nil
+	body any
+}
+
+// do sends the request and decodes the JSON answer into out, which may be nil
+func (c *apiClient) do(ctx context.Context, req apiRequest, out any) error {
+	var payload []byte
+	if req.body != nil {
+		var err error
+		payload, err = json.Marshal(req.body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	endpoint := c.baseURL + req.path
+	if len(req.query) > 0 {
+		endpoint += "?" + req.query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		httpReq, err := http.NewRequestWithContext(ctx, req.method, endpoint, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		httpReq.Header.Set("Accept", "application/json")
+		if payload != nil {
+			httpReq.Header.Set("Content-Type", "application/json")
+		}
+		// Request headers go last so services with their own media type override the defaults
+		for name, values := range req.header {
+			httpReq.Header[name] = values
+		}
+
+		resp, err := c.httpClient.Do(httpReq)
+		if err != nil {
+			// Some services take the access token in the query, so the URL is
+			// left out of the error
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+			return fmt.Errorf("%s request failed: %w", c.service, err)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			wait := retryAfter(resp.Header.Get("Retry-After"))
+			apiErr := c.errorFrom(resp)
+			if attempt >= c.maxRetries || wait > maxRetryAfter {
+				apiErr.RetryAfter = wait
+				return apiErr
+			}
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+			continue
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return c.errorFrom(resp)
+		}
+
+		defer resp.Body.Close()
+		if out == nil || resp.StatusCode == http.StatusNoContent {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			return nil
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to parse %s response: %w", c.service, err)
+		}
+		return nil
+	}
+}
+
+func (c *apiClient) errorFrom(resp *http.Response) *APIError {
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	return &APIError{
+		Service:    c.service,
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+	}
+}
+
+// retryAfter reads a Retry-After header, given in seconds or as an HTTP date
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return defaultRetryAfter
+	}
+
+	if seconds, err := strconv.Atoi(strings.TrimSpace(header)); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if at, err := http.ParseTime(header); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+		return 0
+	}
+
+	return defaultRetryAfter
+}
+
+func bearer(accessToken string) http.Header {
+	return http.Header{"Authorization": []string{"Bearer " + accessToken}}
+}
@@ -0,0 +1,107 @@
+package music
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAPIClientRetriesRateLimitedCalls(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		// limited is how many 429 answers come before a 200
+		limited     int
+		wantCalls   int
+		wantErr     bool
+		wantWaitErr time.Duration
+	}{
+		{"no rate limit", "0", 0, 1, false, 0},
+		{"retried until accepted", "0", 2, 3, false, 0},
+		{"retries exhausted", "0", 10, defaultMaxRetries + 1, true, 0},
+		{"wait too long to hold the request", "3600", 1, 1, true, time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if int(calls.Add(1)) <= tt.limited {
+					w.Header().Set("Retry-After", tt.retryAfter)
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				_, _ = w.Write([]byte(`{"ok":true}`))
+			}))
+			defer server.Close()
+
+			client := newAPIClient("test", server.Client(), server.URL)
+
+			var out struct {
+				OK bool `json:"ok"`
+			}
+			err := client.do(context.Background(), apiRequest{method: http.MethodGet, path: "/"}, &out)
+
+			if got := int(calls.Load()); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+
+			if !tt.wantErr {
+				if err != nil || !out.OK {
+					t.Fatalf("do: err = %v, ok = %v", err, out.OK)
+				}
+				return
+			}
+
+			var apiErr *APIError
+			if !stderrors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+				t.Fatalf("do: error = %v, want 429 APIError", err)
+			}
+			if apiErr.RetryAfter != tt.wantWaitErr {
+				t.Errorf("RetryAfter = %s, want %s", apiErr.RetryAfter, tt.wantWaitErr)
+			}
+		})
+	}
+}
+
+func TestAPIClientStopsWaitingWhenContextEnds(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	client := newAPIClient("test", server.Client(), server.URL)
+	err := client.do(ctx, apiRequest{method: http.MethodGet, path: "/"}, nil)
+	if !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("do: error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", defaultRetryAfter},
+		{"0", 0},
+		{"7", 7 * time.Second},
+		{" 2 ", 2 * time.Second},
+		{"-1", defaultRetryAfter},
+		{"soon", defaultRetryAfter},
+		{"Mon, 01 Jan 2001 00:00:00 GMT", 0},
+	}
+
+	for _, tt := range tests {
+		if got := retryAfter(tt.header); got != tt.want {
+			t.Errorf("retryAfter(%q) = %s, want %s", tt.header, got, tt.want)
+		}
+	}
+}
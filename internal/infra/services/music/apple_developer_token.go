@@ -0,0 +1,85 @@
+package music
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/zandomed/sync-playlist-api/pkg/jwtkeys"
+)
+
+const (
+	// Apple accepts developer tokens valid for up to six months; browsers
+	// receive the token, so a shorter one limits the damage of a leak
+	appleDeveloperTokenTTL = 12 * time.Hour
+	// appleDeveloperTokenRenewal regenerates the token before clients that
+	// just received it see it expire
+	appleDeveloperTokenRenewal = time.Hour
+)
+
+// AppleDeveloperTokenGenerator signs MusicKit developer tokens with the key
+// downloaded from the Apple Developer portal, reusing each until it is about
+// to expire
+type AppleDeveloperTokenGenerator struct {
+	teamID string
+	signer *jwtkeys.KeyRing
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewAppleDeveloperTokenGenerator parses privateKey, the PEM of the MusicKit
+// key identified by keyID
+func NewAppleDeveloperTokenGenerator(teamID, keyID, privateKey string) (*AppleDeveloperTokenGenerator, error) {
+	if teamID == "" || keyID == "" {
+		return nil, errors.New("apple music requires a team ID and key ID")
+	}
+
+	// Env files usually carry the PEM on a single line with escaped newlines
+	key, err := jwtkeys.ParsePrivateKeyPEM(keyID, []byte(strings.ReplaceAll(privateKey, `\n`, "\n")))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MusicKit private key: %w", err)
+	}
+
+	if key.Algorithm() != jwt.SigningMethodES256.Alg() {
+		return nil, fmt.Errorf("invalid MusicKit private key: expected an ES256 key, got %s", key.Algorithm())
+	}
+
+	signer, err := jwtkeys.NewKeyRing(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AppleDeveloperTokenGenerator{
+		teamID: teamID,
+		signer: signer,
+	}, nil
+}
+
+func (g *AppleDeveloperTokenGenerator) DeveloperToken() (string, time.Time, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	if g.token != "" && now.Add(appleDeveloperTokenRenewal).Before(g.expiresAt) {
+		return g.token, g.expiresAt, nil
+	}
+
+	expiresAt := now.Add(appleDeveloperTokenTTL)
+	token, err := g.signer.Sign(jwt.RegisteredClaims{
+		Issuer:    g.teamID,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign Apple Music developer token: %w", err)
+	}
+
+	g.token = token
+	g.expiresAt = expiresAt
+	return token, expiresAt, nil
+}
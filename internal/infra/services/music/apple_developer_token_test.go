@@ -0,0 +1,118 @@
+package music
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func encodePKCS8(t *testing.T, privateKey any) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func TestNewAppleDeveloperTokenGenerator(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	ecPEM := encodePKCS8(t, ecKey)
+
+	tests := []struct {
+		name       string
+		teamID     string
+		keyID      string
+		privateKey string
+		wantErr    bool
+	}{
+		{"ES256 key", "TEAMID", "KEYID", ecPEM, false},
+		{"PEM on a single line with escaped newlines", "TEAMID", "KEYID", strings.ReplaceAll(ecPEM, "\n", `\n`), false},
+		{"RSA key", "TEAMID", "KEYID", encodePKCS8(t, rsaKey), true},
+		{"not a PEM", "TEAMID", "KEYID", "not a key", true},
+		{"missing team ID", "", "KEYID", ecPEM, true},
+		{"missing key ID", "TEAMID", "", ecPEM, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAppleDeveloperTokenGenerator(tt.teamID, tt.keyID, tt.privateKey)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewAppleDeveloperTokenGenerator error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAppleDeveloperTokenClaimsAndReuse(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	generator, err := NewAppleDeveloperTokenGenerator("TEAMID", "KEYID", encodePKCS8(t, ecKey))
+	if err != nil {
+		t.Fatalf("NewAppleDeveloperTokenGenerator: %v", err)
+	}
+
+	token, expiresAt, err := generator.DeveloperToken()
+	if err != nil {
+		t.Fatalf("DeveloperToken: %v", err)
+	}
+
+	var claims jwt.RegisteredClaims
+	parsed, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return &ecKey.PublicKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodES256.Alg()}))
+	if err != nil {
+		t.Fatalf("ParseWithClaims: %v", err)
+	}
+
+	if kid := parsed.Header["kid"]; kid != "KEYID" {
+		t.Errorf("kid = %v, want KEYID", kid)
+	}
+	if claims.Issuer != "TEAMID" {
+		t.Errorf("iss = %s, want TEAMID", claims.Issuer)
+	}
+	if lifetime := claims.ExpiresAt.Sub(claims.IssuedAt.Time); lifetime != appleDeveloperTokenTTL {
+		t.Errorf("lifetime = %s, want %s", lifetime, appleDeveloperTokenTTL)
+	}
+	if !claims.ExpiresAt.Time.Equal(expiresAt.Truncate(time.Second)) {
+		t.Errorf("exp = %s, want %s", claims.ExpiresAt.Time, expiresAt)
+	}
+
+	again, _, err := generator.DeveloperToken()
+	if err != nil {
+		t.Fatalf("DeveloperToken: %v", err)
+	}
+	if again != token {
+		t.Errorf("DeveloperToken signed a new token while the previous one was fresh")
+	}
+
+	// A token close to expiry is replaced
+	generator.expiresAt = time.Now().Add(appleDeveloperTokenRenewal / 2)
+	renewed, _, err := generator.DeveloperToken()
+	if err != nil {
+		t.Fatalf("DeveloperToken: %v", err)
+	}
+	if renewed == token {
+		t.Errorf("DeveloperToken reused a token about to expire")
+	}
+}
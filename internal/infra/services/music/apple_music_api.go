@@ -0,0 +1,220 @@
+package music
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// AppleMaxBatchSize is the most tracks sent in a single add call
+const AppleMaxBatchSize = 100
+
+// AppleMusicAPIClient calls the Apple Music API on behalf of a user. Every
+// call carries the app's developer token and the user's Music-User-Token
+type AppleMusicAPIClient struct {
+	api             apiClient
+	developerTokens *AppleDeveloperTokenGenerator
+}
+
+func NewAppleMusicAPIClient(client *http.Client, baseURL string, developerTokens *AppleDeveloperTokenGenerator) *AppleMusicAPIClient {
+	return &AppleMusicAPIClient{
+		api:             newAPIClient("apple music", client, baseURL),
+		developerTokens: developerTokens,
+	}
+}
+
+// ApplePage is a page of resources. Next is the path of the following page,
+// empty on the last one
+type ApplePage[T any] struct {
+	Data []T    `json:"data"`
+	Next string `json:"next"`
+	Meta struct {
+		Total int `json:"total"`
+	} `json:"meta"`
+}
+
+type AppleDescription struct {
+	Standard string `json:"standard"`
+	Short    string `json:"short"`
+}
+
+type ApplePlaylist struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	Attributes struct {
+		Name        string            `json:"name"`
+		Description *AppleDescription `json:"description"`
+		CanEdit     bool              `json:"canEdit"`
+		IsPublic    bool              `json:"isPublic"`
+	} `json:"attributes"`
+}
+
+type ApplePlayParams struct {
+	ID        string `json:"id"`
+	CatalogID string `json:"catalogId"`
+}
+
+// AppleSong is a catalog song ("songs") or a song in the user's library
+// ("library-songs"). Library songs lack the ISRC, which comes with their
+// catalog relationship when it is included
+type AppleSong struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	Attributes struct {
+		Name             string           `json:"name"`
+		ArtistName       string           `json:"artistName"`
+		AlbumName        string           `json:"albumName"`
+		DurationInMillis int              `json:"durationInMillis"`
+		ISRC             string           `json:"isrc"`
+		ContentRating    string           `json:"contentRating"` // "explicit" or "clean"
+		PlayParams       *ApplePlayParams `json:"playParams"`
+	} `json:"attributes"`
+	Relationships struct {
+		Catalog *struct {
+			Data []AppleSong `json:"data"`
+		} `json:"catalog"`
+	} `json:"relationships"`
+}
+
+// AppleTrackReference identifies a song to add to a library playlist
+type AppleTrackReference struct {
+	ID   string `json:"id"`
+	Type string `json:"type"` // "songs" or "library-songs"
+}
+
+// Storefront returns the storefront (country catalog) of the user's account
+func (c *AppleMusicAPIClient) Storefront(ctx context.Context, musicUserToken string) (string, error) {
+	var page ApplePage[struct {
+		ID string `json:"id"`
+	}]
+	if err := c.get(ctx, musicUserToken, "/me/storefront", nil, &page); err != nil {
+		return "", err
+	}
+
+	if len(page.Data) == 0 {
+		return "", errors.New("apple music returned no storefront")
+	}
+	return page.Data[0].ID, nil
+}
+
+func (c *AppleMusicAPIClient) GetLibraryPlaylists(ctx context.Context, musicUserToken string, offset, limit int) (*ApplePage[ApplePlaylist], error) {
+	var page ApplePage[ApplePlaylist]
+	if err := c.get(ctx, musicUserToken, "/me/library/playlists", pagingQuery(offset, limit), &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func (c *AppleMusicAPIClient) GetLibraryPlaylistTracks(ctx context.Context, musicUserToken, playlistID string, offset, limit int) (*ApplePage[AppleSong], error) {
+	query := pagingQuery(offset, limit)
+	query.Set("include", "catalog")
+
+	var page ApplePage[AppleSong]
+	path := "/me/library/playlists/" + url.PathEscape(playlistID) + "/tracks"
+	if err := c.get(ctx, musicUserToken, path, query, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func (c *AppleMusicAPIClient) GetCatalogSongsByISRC(ctx context.Context, musicUserToken, storefront, isrc string) ([]AppleSong, error) {
+	query := url.Values{}
+	query.Set("filter[isrc]", isrc)
+
+	var page ApplePage[AppleSong]
+	path := "/catalog/" + url.PathEscape(storefront) + "/songs"
+	if err := c.get(ctx, musicUserToken, path, query, &page); err != nil {
+		return nil, err
+	}
+	return page.Data, nil
+}
+
+func (c *AppleMusicAPIClient) SearchCatalogSongs(ctx context.Context, musicUserToken, storefront, term string, limit int) ([]AppleSong, error) {
+	query := url.Values{}
+	query.Set("term", term)
+	query.Set("types", "songs")
+	query.Set("limit", strconv.Itoa(limit))
+
+	var result struct {
+		Results struct {
+			Songs *ApplePage[AppleSong] `json:"songs"`
+		} `json:"results"`
+	}
+	path := "/catalog/" + url.PathEscape(storefront) + "/search"
+	if err := c.get(ctx, musicUserToken, path, query, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Results.Songs == nil {
+		return nil, nil
+	}
+	return result.Results.Songs.Data, nil
+}
+
+// CreateLibraryPlaylist creates a playlist in the user's library. Apple Music
+// does not let apps choose whether it is public
+func (c *AppleMusicAPIClient) CreateLibraryPlaylist(ctx context.Context, musicUserToken, name, description string) (*ApplePlaylist, error) {
+	body := map[string]any{
+		"attributes": map[string]string{
+			"name":        name,
+			"description": description,
+		},
+	}
+
+	var page ApplePage[ApplePlaylist]
+	if err := c.send(ctx, musicUserToken, http.MethodPost, "/me/library/playlists", body, &page); err != nil {
+		return nil, err
+	}
+
+	if len(page.Data) == 0 {
+		return nil, errors.New("apple music returned no playlist")
+	}
+	return &page.Data[0], nil
+}
+
+// AddLibraryPlaylistTracks appends up to AppleMaxBatchSize tracks
+func (c *AppleMusicAPIClient) AddLibraryPlaylistTracks(ctx context.Context, musicUserToken, playlistID string, tracks []AppleTrackReference) error {
+	path := "/me/library/playlists/" + url.PathEscape(playlistID) + "/tracks"
+	return c.send(ctx, musicUserToken, http.MethodPost, path, map[string]any{"data": tracks}, nil)
+}
+
+func (c *AppleMusicAPIClient) get(ctx context.Context, musicUserToken, path string, query url.Values, out any) error {
+	header, err := c.header(musicUserToken)
+	if err != nil {
+		return err
+	}
+
+	return c.api.do(ctx, apiRequest{
+		method: http.MethodGet,
+		path:   path,
+		query:  query,
+		header: header,
+	}, out)
+}
+
+func (c *AppleMusicAPIClient) send(ctx context.Context, musicUserToken, method, path string, body, out any) error {
+	header, err := c.header(musicUserToken)
+	if err != nil {
+		return err
+	}
+
+	return c.api.do(ctx, apiRequest{
+		method: method,
+		path:   path,
+		header: header,
+		body:   body,
+	}, out)
+}
+
+func (c *AppleMusicAPIClient) header(musicUserToken string) (http.Header, error) {
+	developerToken, _, err := c.developerTokens.DeveloperToken()
+	if err != nil {
+		return nil, err
+	}
+
+	header := bearer(developerToken)
+	header.Set("Music-User-Token", musicUserToken)
+	return header, nil
+}
@@ -0,0 +1,258 @@
+package music
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DeezerMaxBatchSize is the most tracks sent in a single add or remove call
+	DeezerMaxBatchSize = 100
+	// deezerQuotaWindow is the window of Deezer's limit of 50 calls per app
+	// and user; calls over it are answered with error code 4
+	deezerQuotaWindow = 5 * time.Second
+)
+
+// Error codes Deezer reports in the body of its answers
+const (
+	deezerQuotaCode        = 4
+	deezerPermissionCode   = 200
+	deezerInvalidTokenCode = 300
+	deezerNotFoundCode     = 800
+)
+
+// DeezerAPIClient calls the Deezer API with a user's access token. baseURL is
+// config.DeezerConfig.APIUrl, so it can point to a stand-in
+type DeezerAPIClient struct {
+	api apiClient
+}
+
+func NewDeezerAPIClient(client *http.Client, baseURL string) *DeezerAPIClient {
+	return &DeezerAPIClient{
+		api: newAPIClient("deezer", client, baseURL),
+	}
+}
+
+// DeezerList is a page of a listing; Next is the URL of the following page,
+// empty on the last one
+type DeezerList[T any] struct {
+	Data  []T    `json:"data"`
+	Total int    `json:"total"`
+	Next  string `json:"next"`
+}
+
+type DeezerArtist struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type DeezerAlbum struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	UPC   string `json:"upc"`
+}
+
+// DeezerTrack is a track as listed in playlists and searches; only tracks
+// fetched on their own carry the ISRC and the contributors
+type DeezerTrack struct {
+	ID       int64  `json:"id"`
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Readable bool   `json:"readable"`
+	// Duration is in seconds
+	Duration       int            `json:"duration"`
+	ExplicitLyrics bool           `json:"explicit_lyrics"`
+	ISRC           string         `json:"isrc"`
+	Artist         DeezerArtist   `json:"artist"`
+	Contributors   []DeezerArtist `json:"contributors"`
+	Album          DeezerAlbum    `json:"album"`
+}
+
+type DeezerPlaylist struct {
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Public      bool   `json:"public"`
+	NbTracks    int    `json:"nb_tracks"`
+	Creator     struct {
+		ID int64 `json:"id"`
+	} `json:"creator"`
+}
+
+type deezerErrorBody struct {
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+}
+
+// GetMyPlaylists lists the playlists the user created or added to their library
+func (c *DeezerAPIClient) GetMyPlaylists(ctx context.Context, accessToken string, index, limit int) (*DeezerList[DeezerPlaylist], error) {
+	var list DeezerList[DeezerPlaylist]
+	err := c.call(ctx, accessToken, http.MethodGet, "/user/me/playlists", deezerIndexQuery(index, limit), &list)
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (c *DeezerAPIClient) GetPlaylist(ctx context.Context, accessToken, playlistID string) (*DeezerPlaylist, error) {
+	var playlist DeezerPlaylist
+	err := c.call(ctx, accessToken, http.MethodGet, "/playlist/"+url.PathEscape(playlistID), nil, &playlist)
+	if err != nil {
+		return nil, err
+	}
+	return &playlist, nil
+}
+
+func (c *DeezerAPIClient) GetPlaylistTracks(ctx context.Context, accessToken, playlistID string, index, limit int) (*DeezerList[DeezerTrack], error) {
+	var list DeezerList[DeezerTrack]
+	err := c.call(ctx, accessToken, http.MethodGet, "/playlist/"+url.PathEscape(playlistID)+"/tracks", deezerIndexQuery(index, limit), &list)
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetTrackByISRC looks the ISRC up in the catalog; unknown codes are answered
+// as not found
+func (c *DeezerAPIClient) GetTrackByISRC(ctx context.Context, accessToken, isrc string) (*DeezerTrack, error) {
+	var track DeezerTrack
+	err := c.call(ctx, accessToken, http.MethodGet, "/track/isrc:"+url.PathEscape(isrc), nil, &track)
+	if err != nil {
+		return nil, err
+	}
+	return &track, nil
+}
+
+// SearchTracks runs a catalog search; q accepts the advanced filters such as
+// artist:"..." and track:"..."
+func (c *DeezerAPIClient) SearchTracks(ctx context.Context, accessToken, q string, limit int) ([]DeezerTrack, error) {
+	query := url.Values{}
+	query.Set("q", q)
+	query.Set("limit", strconv.Itoa(limit))
+
+	var list DeezerList[DeezerTrack]
+	if err := c.call(ctx, accessToken, http.MethodGet, "/search/track", query, &list); err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
+
+// CreatePlaylist creates the playlist and returns its ID. Deezer takes only
+// the title on creation; UpdatePlaylist sets the rest
+func (c *DeezerAPIClient) CreatePlaylist(ctx context.Context, accessToken, title string) (string, error) {
+	query := url.Values{}
+	query.Set("title", title)
+
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	if err := c.call(ctx, accessToken, http.MethodPost, "/user/me/playlists", query, &created); err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(created.ID, 10), nil
+}
+
+func (c *DeezerAPIClient) UpdatePlaylist(ctx context.Context, accessToken, playlistID, description string, public bool) error {
+	query := url.Values{}
+	query.Set("description", description)
+	query.Set("public", strconv.FormatBool(public))
+
+	return c.call(ctx, accessToken, http.MethodPost, "/playlist/"+url.PathEscape(playlistID), query, nil)
+}
+
+// AddTracks appends up to DeezerMaxBatchSize tracks. Deezer refuses tracks
+// already in the playlist
+func (c *DeezerAPIClient) AddTracks(ctx context.Context, accessToken, playlistID string, trackIDs []string) error {
+	query := url.Values{}
+	query.Set("songs", strings.Join(trackIDs, ","))
+
+	return c.call(ctx, accessToken, http.MethodPost, "/playlist/"+url.PathEscape(playlistID)+"/tracks", query, nil)
+}
+
+func (c *DeezerAPIClient) RemoveTracks(ctx context.Context, accessToken, playlistID string, trackIDs []string) error {
+	query := url.Values{}
+	query.Set("songs", strings.Join(trackIDs, ","))
+
+	return c.call(ctx, accessToken, http.MethodDelete, "/playlist/"+url.PathEscape(playlistID)+"/tracks", query, nil)
+}
+
+// call sends the request with the token as query parameter, which is how
+// Deezer takes it. Deezer answers errors with 200 and an error object, so
+// those are turned into an APIError with the matching HTTP status, after
+// waiting out the quota window on quota errors
+func (c *DeezerAPIClient) call(ctx context.Context, accessToken, method, path string, query url.Values, out any) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("access_token", accessToken)
+
+	for attempt := 0; ; attempt++ {
+		var raw json.RawMessage
+		if err := c.api.do(ctx, apiRequest{method: method, path: path, query: query}, &raw); err != nil {
+			return err
+		}
+
+		var body deezerErrorBody
+		if err := json.Unmarshal(raw, &body); err == nil && body.Error != nil {
+			if body.Error.Code == deezerQuotaCode && attempt < c.api.maxRetries {
+				timer := time.NewTimer(deezerQuotaWindow)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+				continue
+			}
+
+			apiErr := &APIError{
+				Service:    c.api.service,
+				StatusCode: deezerErrorStatus(body.Error.Type, body.Error.Code),
+				Body:       fmt.Sprintf("%s: %s", body.Error.Type, body.Error.Message),
+			}
+			if body.Error.Code == deezerQuotaCode {
+				apiErr.RetryAfter = deezerQuotaWindow
+			}
+			return apiErr
+		}
+
+		if out == nil {
+			return nil
+		}
+		if err := json.Unmarshal(raw, out); err != nil {
+			return fmt.Errorf("failed to parse deezer response: %w", err)
+		}
+		return nil
+	}
+}
+
+func deezerErrorStatus(errorType string, code int) int {
+	switch {
+	case code == deezerQuotaCode:
+		return http.StatusTooManyRequests
+	case code == deezerNotFoundCode:
+		return http.StatusNotFound
+	case code == deezerPermissionCode:
+		return http.StatusForbidden
+	case code == deezerInvalidTokenCode || errorType == "OAuthException":
+		return http.StatusUnauthorized
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func deezerIndexQuery(index, limit int) url.Values {
+	query := url.Values{}
+	query.Set("index", strconv.Itoa(index))
+	query.Set("limit", strconv.Itoa(limit))
+	return query
+}
@@ -0,0 +1,66 @@
+package music
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeezerErrorsInOKAnswers(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		wantStatus     int
+		wantRetryAfter time.Duration
+	}{
+		{"no error", `{"id":1,"title":"Mix"}`, 0, 0},
+		{"quota exceeded", `{"error":{"type":"Exception","message":"Quota limit exceeded","code":4}}`, http.StatusTooManyRequests, deezerQuotaWindow},
+		{"permission denied", `{"error":{"type":"OAuthException","message":"Permission denied","code":200}}`, http.StatusForbidden, 0},
+		{"invalid token", `{"error":{"type":"OAuthException","message":"Invalid OAuth access token.","code":300}}`, http.StatusUnauthorized, 0},
+		{"other OAuth error", `{"error":{"type":"OAuthException","message":"An active access token must be used","code":0}}`, http.StatusUnauthorized, 0},
+		{"not found", `{"error":{"type":"DataException","message":"no data","code":800}}`, http.StatusNotFound, 0},
+		{"unknown error", `{"error":{"type":"ParameterException","message":"Wrong parameter","code":500}}`, http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.URL.Query().Get("access_token"); got != "token" {
+					t.Errorf("access_token = %q", got)
+				}
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewDeezerAPIClient(server.Client(), server.URL)
+			// Quota errors would otherwise wait out the quota window
+			client.api.maxRetries = 0
+
+			playlist, err := client.GetPlaylist(context.Background(), "token", "1")
+
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("GetPlaylist: %v", err)
+				}
+				if playlist.ID != 1 || playlist.Title != "Mix" {
+					t.Errorf("playlist = %+v", playlist)
+				}
+				return
+			}
+
+			var apiErr *APIError
+			if !stderrors.As(err, &apiErr) {
+				t.Fatalf("GetPlaylist: error = %v, want APIError", err)
+			}
+			if apiErr.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, tt.wantStatus)
+			}
+			if apiErr.RetryAfter != tt.wantRetryAfter {
+				t.Errorf("RetryAfter = %s, want %s", apiErr.RetryAfter, tt.wantRetryAfter)
+			}
+		})
+	}
+}
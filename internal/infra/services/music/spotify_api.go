@@ -0,0 +1,226 @@
+package music
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// SpotifyMaxBatchSize is the most items a single add or remove call accepts
+const SpotifyMaxBatchSize = 100
+
+// SpotifyAPIClient calls the Spotify Web API with a user's access token.
+// baseURL is config.SpotifyConfig.APIUrl, so it can point to a stand-in
+type SpotifyAPIClient struct {
+	api apiClient
+}
+
+func NewSpotifyAPIClient(client *http.Client, baseURL string) *SpotifyAPIClient {
+	return &SpotifyAPIClient{
+		api: newAPIClient("spotify", client, baseURL),
+	}
+}
+
+// SpotifyPaging is Spotify's paging object. Next is the URL of the following
+// page, empty on the last one
+type SpotifyPaging[T any] struct {
+	Items  []T    `json:"items"`
+	Next   string `json:"next"`
+	Total  int    `json:"total"`
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+}
+
+type SpotifyExternalIDs struct {
+	ISRC string `json:"isrc"`
+	UPC  string `json:"upc"`
+	EAN  string `json:"ean"`
+}
+
+type SpotifyArtist struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type SpotifyAlbum struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	ExternalIDs SpotifyExternalIDs `json:"external_ids"`
+}
+
+type SpotifyTrack struct {
+	ID          string             `json:"id"`
+	URI         string             `json:"uri"`
+	Type        string             `json:"type"` // "track" or "episode"
+	Name        string             `json:"name"`
+	DurationMS  int                `json:"duration_ms"`
+	Explicit    bool               `json:"explicit"`
+	IsLocal     bool               `json:"is_local"`
+	Artists     []SpotifyArtist    `json:"artists"`
+	Album       SpotifyAlbum       `json:"album"`
+	ExternalIDs SpotifyExternalIDs `json:"external_ids"`
+}
+
+// SpotifyPlaylistItem is an entry of a playlist. The entry is under "item" in
+// the /items endpoints and under "track" in the older /tracks ones
+type SpotifyPlaylistItem struct {
+	Item  *SpotifyTrack `json:"item"`
+	Track *SpotifyTrack `json:"track"`
+}
+
+// Entry returns the track or episode of the item, nil for removed content
+func (i SpotifyPlaylistItem) Entry() *SpotifyTrack {
+	if i.Item != nil {
+		return i.Item
+	}
+	return i.Track
+}
+
+type spotifyTotal struct {
+	Total int `json:"total"`
+}
+
+type SpotifyPlaylist struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Public      bool   `json:"public"`
+	Owner       struct {
+		ID string `json:"id"`
+	} `json:"owner"`
+	// Items and Tracks reference the entries; which one is set depends on the
+	// API version, as with SpotifyPlaylistItem
+	Items  *spotifyTotal `json:"items"`
+	Tracks *spotifyTotal `json:"tracks"`
+}
+
+func (p SpotifyPlaylist) TrackCount() int {
+	if p.Items != nil {
+		return p.Items.Total
+	}
+	if p.Tracks != nil {
+		return p.Tracks.Total
+	}
+	return 0
+}
+
+// GetMyPlaylists lists the playlists the user owns or follows
+func (c *SpotifyAPIClient) GetMyPlaylists(ctx context.Context, accessToken string, offset, limit int) (*SpotifyPaging[SpotifyPlaylist], error) {
+	var page SpotifyPaging[SpotifyPlaylist]
+	err := c.api.do(ctx, apiRequest{
+		method: http.MethodGet,
+		path:   "/me/playlists",
+		query:  pagingQuery(offset, limit),
+		header: bearer(accessToken),
+	}, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func (c *SpotifyAPIClient) GetPlaylistItems(ctx context.Context, accessToken, playlistID string, offset, limit int) (*SpotifyPaging[SpotifyPlaylistItem], error) {
+	query := pagingQuery(offset, limit)
+	// Relinks tracks unavailable in the user's market to playable versions
+	query.Set("market", "from_token")
+
+	var page SpotifyPaging[SpotifyPlaylistItem]
+	err := c.api.do(ctx, apiRequest{
+		method: http.MethodGet,
+		path:   "/playlists/" + url.PathEscape(playlistID) + "/items",
+		query:  query,
+		header: bearer(accessToken),
+	}, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// SearchTracks runs a catalog search; q accepts field filters such as
+// isrc:, track: and artist:
+func (c *SpotifyAPIClient) SearchTracks(ctx context.Context, accessToken, q string, limit int) ([]SpotifyTrack, error) {
+	query := url.Values{}
+	query.Set("q", q)
+	query.Set("type", "track")
+	query.Set("market", "from_token")
+	query.Set("limit", strconv.Itoa(limit))
+
+	var result struct {
+		Tracks SpotifyPaging[SpotifyTrack] `json:"tracks"`
+	}
+	err := c.api.do(ctx, apiRequest{
+		method: http.MethodGet,
+		path:   "/search",
+		query:  query,
+		header: bearer(accessToken),
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result.Tracks.Items, nil
+}
+
+func (c *SpotifyAPIClient) CreatePlaylist(ctx context.Context, accessToken, name, description string, public bool) (*SpotifyPlaylist, error) {
+	var playlist SpotifyPlaylist
+	err := c.api.do(ctx, apiRequest{
+		method: http.MethodPost,
+		path:   "/me/playlists",
+		header: bearer(accessToken),
+		body: map[string]any{
+			"name":        name,
+			"description": description,
+			"public":      public,
+		},
+	}, &playlist)
+	if err != nil {
+		return nil, err
+	}
+	return &playlist, nil
+}
+
+// AddItems appends up to SpotifyMaxBatchSize items to the playlist
+func (c *SpotifyAPIClient) AddItems(ctx context.Context, accessToken, playlistID string, uris []string) error {
+	return c.api.do(ctx, apiRequest{
+		method: http.MethodPost,
+		path:   "/playlists/" + url.PathEscape(playlistID) + "/items",
+		header: bearer(accessToken),
+		body:   map[string]any{"uris": uris},
+	}, nil)
+}
+
+// RemoveItems removes every occurrence of up to SpotifyMaxBatchSize items
+func (c *SpotifyAPIClient) RemoveItems(ctx context.Context, accessToken, playlistID string, uris []string) error {
+	tracks := make([]map[string]string, 0, len(uris))
+	for _, uri := range uris {
+		tracks = append(tracks, map[string]string{"uri": uri})
+	}
+
+	return c.api.do(ctx, apiRequest{
+		method: http.MethodDelete,
+		path:   "/playlists/" + url.PathEscape(playlistID) + "/items",
+		header: bearer(accessToken),
+		body:   map[string]any{"tracks": tracks},
+	}, nil)
+}
+
+func (c *SpotifyAPIClient) ReorderItems(ctx context.Context, accessToken, playlistID string, rangeStart, rangeLength, insertBefore int) error {
+	return c.api.do(ctx, apiRequest{
+		method: http.MethodPut,
+		path:   "/playlists/" + url.PathEscape(playlistID) + "/items",
+		header: bearer(accessToken),
+		body: map[string]any{
+			"range_start":   rangeStart,
+			"range_length":  rangeLength,
+			"insert_before": insertBefore,
+		},
+	}, nil)
+}
+
+func pagingQuery(offset, limit int) url.Values {
+	query := url.Values{}
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+	return query
+}
@@ -0,0 +1,281 @@
+package music
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// TidalMaxBatchSize is the most items a single add, remove or filter call
+	// accepts
+	TidalMaxBatchSize = 20
+	tidalMediaType    = "application/vnd.api+json"
+)
+
+// TidalAPIClient calls the TIDAL API, which follows JSON:API: resources come
+// as identifiers plus attributes, and related resources are side loaded in
+// "included". baseURL is config.TidalConfig.APIUrl, so it can point to a
+// stand-in
+type TidalAPIClient struct {
+	api apiClient
+}
+
+func NewTidalAPIClient(client *http.Client, baseURL string) *TidalAPIClient {
+	return &TidalAPIClient{
+		api: newAPIClient("tidal", client, baseURL),
+	}
+}
+
+// TidalIdentifier points to a resource. In playlist items Meta.ItemID tells
+// apart repeated entries of the same track
+type TidalIdentifier struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Meta *struct {
+		ItemID string `json:"itemId"`
+	} `json:"meta,omitempty"`
+}
+
+type TidalRelationship struct {
+	Data []TidalIdentifier `json:"data"`
+}
+
+// TidalLinks holds the link to the following page, empty on the last one
+type TidalLinks struct {
+	Next string `json:"next"`
+}
+
+// NextCursor returns the page[cursor] of the following page
+func (l TidalLinks) NextCursor() string {
+	if l.Next == "" {
+		return ""
+	}
+
+	next, err := url.Parse(l.Next)
+	if err != nil {
+		return ""
+	}
+	return next.Query().Get("page[cursor]")
+}
+
+type TidalUser struct {
+	ID         string `json:"id"`
+	Attributes struct {
+		Country string `json:"country"`
+	} `json:"attributes"`
+}
+
+type TidalPlaylist struct {
+	ID         string `json:"id"`
+	Attributes struct {
+		Name          string `json:"name"`
+		Description   string `json:"description"`
+		AccessType    string `json:"accessType"` // "PUBLIC" or "UNLISTED"
+		NumberOfItems int    `json:"numberOfItems"`
+	} `json:"attributes"`
+}
+
+type TidalTrack struct {
+	ID         string `json:"id"`
+	Attributes struct {
+		Title    string `json:"title"`
+		Version  string `json:"version"`
+		ISRC     string `json:"isrc"`
+		Duration string `json:"duration"` // ISO 8601, e.g. PT3M21S
+		Explicit bool   `json:"explicit"`
+	} `json:"attributes"`
+	Relationships struct {
+		Artists TidalRelationship `json:"artists"`
+		Albums  TidalRelationship `json:"albums"`
+	} `json:"relationships"`
+}
+
+// TidalIncluded is a side loaded artist (Name) or album (Title, BarcodeID)
+type TidalIncluded struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	Attributes struct {
+		Name      string `json:"name"`
+		Title     string `json:"title"`
+		BarcodeID string `json:"barcodeId"`
+	} `json:"attributes"`
+}
+
+type TidalPlaylists struct {
+	Data  []TidalPlaylist `json:"data"`
+	Links TidalLinks      `json:"links"`
+}
+
+type TidalPlaylistItems struct {
+	Data  []TidalIdentifier `json:"data"`
+	Links TidalLinks        `json:"links"`
+}
+
+// TidalTracks are tracks with their artists and albums included
+type TidalTracks struct {
+	Data     []TidalTrack    `json:"data"`
+	Included []TidalIncluded `json:"included"`
+}
+
+// GetMe returns the user, whose country scopes the catalog requests
+func (c *TidalAPIClient) GetMe(ctx context.Context, accessToken string) (*TidalUser, error) {
+	var document struct {
+		Data TidalUser `json:"data"`
+	}
+	err := c.api.do(ctx, apiRequest{
+		method: http.MethodGet,
+		path:   "/users/me",
+		header: tidalHeader(accessToken),
+	}, &document)
+	if err != nil {
+		return nil, err
+	}
+	return &document.Data, nil
+}
+
+// GetUserPlaylists lists the playlists the user owns. TIDAL sets the page size
+func (c *TidalAPIClient) GetUserPlaylists(ctx context.Context, accessToken, userID, countryCode, cursor string) (*TidalPlaylists, error) {
+	query := tidalQuery(countryCode, cursor)
+	query.Set("filter[r.owners.id]", userID)
+
+	var page TidalPlaylists
+	err := c.api.do(ctx, apiRequest{
+		method: http.MethodGet,
+		path:   "/playlists",
+		query:  query,
+		header: tidalHeader(accessToken),
+	}, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// GetPlaylistItems lists the identifiers of the playlist entries in order
+func (c *TidalAPIClient) GetPlaylistItems(ctx context.Context, accessToken, playlistID, countryCode, cursor string) (*TidalPlaylistItems, error) {
+	var page TidalPlaylistItems
+	err := c.api.do(ctx, apiRequest{
+		method: http.MethodGet,
+		path:   "/playlists/" + url.PathEscape(playlistID) + "/relationships/items",
+		query:  tidalQuery(countryCode, cursor),
+		header: tidalHeader(accessToken),
+	}, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// GetTracks describes up to TidalMaxBatchSize tracks; unavailable ones are
+// left out
+func (c *TidalAPIClient) GetTracks(ctx context.Context, accessToken, countryCode string, trackIDs []string) (*TidalTracks, error) {
+	return c.tracks(ctx, accessToken, countryCode, "filter[id]", strings.Join(trackIDs, ","))
+}
+
+func (c *TidalAPIClient) GetTracksByISRC(ctx context.Context, accessToken, countryCode, isrc string) (*TidalTracks, error) {
+	return c.tracks(ctx, accessToken, countryCode, "filter[isrc]", isrc)
+}
+
+// SearchTracks returns the identifiers of the tracks matching q, best first
+func (c *TidalAPIClient) SearchTracks(ctx context.Context, accessToken, countryCode, q string) ([]TidalIdentifier, error) {
+	var page TidalPlaylistItems
+	err := c.api.do(ctx, apiRequest{
+		method: http.MethodGet,
+		path:   "/searchResults/" + url.PathEscape(q) + "/relationships/tracks",
+		query:  tidalQuery(countryCode, ""),
+		header: tidalHeader(accessToken),
+	}, &page)
+	if err != nil {
+		return nil, err
+	}
+	return page.Data, nil
+}
+
+func (c *TidalAPIClient) CreatePlaylist(ctx context.Context, accessToken, name, description, accessType string) (*TidalPlaylist, error) {
+	var document struct {
+		Data TidalPlaylist `json:"data"`
+	}
+	err := c.api.do(ctx, apiRequest{
+		method: http.MethodPost,
+		path:   "/playlists",
+		header: tidalHeader(accessToken),
+		body: map[string]any{
+			"data": map[string]any{
+				"type": "playlists",
+				"attributes": map[string]string{
+					"name":        name,
+					"description": description,
+					"accessType":  accessType,
+				},
+			},
+		},
+	}, &document)
+	if err != nil {
+		return nil, err
+	}
+	return &document.Data, nil
+}
+
+// AddItems appends up to TidalMaxBatchSize tracks to the playlist
+func (c *TidalAPIClient) AddItems(ctx context.Context, accessToken, playlistID string, trackIDs []string) error {
+	items := make([]TidalIdentifier, 0, len(trackIDs))
+	for _, id := range trackIDs {
+		items = append(items, TidalIdentifier{ID: id, Type: "tracks"})
+	}
+
+	return c.api.do(ctx, apiRequest{
+		method: http.MethodPost,
+		path:   "/playlists/" + url.PathEscape(playlistID) + "/relationships/items",
+		header: tidalHeader(accessToken),
+		body:   map[string]any{"data": items},
+	}, nil)
+}
+
+// RemoveItems removes up to TidalMaxBatchSize entries, identified by the
+// item IDs returned by GetPlaylistItems
+func (c *TidalAPIClient) RemoveItems(ctx context.Context, accessToken, playlistID string, items []TidalIdentifier) error {
+	return c.api.do(ctx, apiRequest{
+		method: http.MethodDelete,
+		path:   "/playlists/" + url.PathEscape(playlistID) + "/relationships/items",
+		header: tidalHeader(accessToken),
+		body:   map[string]any{"data": items},
+	}, nil)
+}
+
+func (c *TidalAPIClient) tracks(ctx context.Context, accessToken, countryCode, filter, value string) (*TidalTracks, error) {
+	query := tidalQuery(countryCode, "")
+	query.Set(filter, value)
+	query.Set("include", "artists,albums")
+
+	var tracks TidalTracks
+	err := c.api.do(ctx, apiRequest{
+		method: http.MethodGet,
+		path:   "/tracks",
+		query:  query,
+		header: tidalHeader(accessToken),
+	}, &tracks)
+	if err != nil {
+		return nil, err
+	}
+	return &tracks, nil
+}
+
+func tidalHeader(accessToken string) http.Header {
+	header := bearer(accessToken)
+	header.Set("Accept", tidalMediaType)
+	header.Set("Content-Type", tidalMediaType)
+	return header
+}
+
+func tidalQuery(countryCode, cursor string) url.Values {
+	query := url.Values{}
+	if countryCode != "" {
+		query.Set("countryCode", countryCode)
+	}
+	if cursor != "" {
+		query.Set("page[cursor]", cursor)
+	}
+	return query
+}
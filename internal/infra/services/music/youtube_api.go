@@ -0,0 +1,261 @@
+package music
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// youtubeMusicCategory is the video category of music uploads
+const youtubeMusicCategory = "10"
+
+// YouTubeAPIClient calls the YouTube Data API with a user's access token,
+// charging every call to the shared daily quota
+type YouTubeAPIClient struct {
+	api   apiClient
+	quota *YouTubeQuota
+}
+
+func NewYouTubeAPIClient(client *http.Client, baseURL string, quota *YouTubeQuota) *YouTubeAPIClient {
+	api := newAPIClient("youtube", client, baseURL)
+	// Every request Google receives is charged, but the quota is reserved
+	// once per call: rate limited requests are returned, not retried, so
+	// the local count never falls behind the project's
+	api.maxRetries = 0
+
+	return &YouTubeAPIClient{
+		api:   api,
+		quota: quota,
+	}
+}
+
+// YouTubeList is a page of resources; NextPageToken is empty on the last one
+type YouTubeList[T any] struct {
+	Items         []T    `json:"items"`
+	NextPageToken string `json:"nextPageToken"`
+	PageInfo      struct {
+		TotalResults int `json:"totalResults"`
+	} `json:"pageInfo"`
+}
+
+type YouTubePlaylist struct {
+	ID      string `json:"id"`
+	Snippet struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		ChannelID   string `json:"channelId"`
+	} `json:"snippet"`
+	ContentDetails struct {
+		ItemCount int `json:"itemCount"`
+	} `json:"contentDetails"`
+	Status struct {
+		PrivacyStatus string `json:"privacyStatus"` // "public", "unlisted" or "private"
+	} `json:"status"`
+}
+
+// YouTubePlaylistItem is an entry of a playlist. Deleted and private videos
+// stay in the playlist without VideoOwnerChannelTitle
+type YouTubePlaylistItem struct {
+	ID      string `json:"id"`
+	Snippet struct {
+		Title                  string `json:"title"`
+		VideoOwnerChannelTitle string `json:"videoOwnerChannelTitle"`
+	} `json:"snippet"`
+	ContentDetails struct {
+		VideoID string `json:"videoId"`
+	} `json:"contentDetails"`
+}
+
+type YouTubeSearchResult struct {
+	ID struct {
+		VideoID string `json:"videoId"`
+	} `json:"id"`
+	Snippet struct {
+		Title        string `json:"title"`
+		ChannelTitle string `json:"channelTitle"`
+	} `json:"snippet"`
+}
+
+type YouTubeVideo struct {
+	ID      string `json:"id"`
+	Snippet struct {
+		Title        string `json:"title"`
+		ChannelTitle string `json:"channelTitle"`
+	} `json:"snippet"`
+	ContentDetails struct {
+		Duration string `json:"duration"` // ISO 8601, e.g. PT3M21S
+	} `json:"contentDetails"`
+}
+
+// RemainingQuota returns the quota units left today
+func (c *YouTubeAPIClient) RemainingQuota() int {
+	return c.quota.Remaining()
+}
+
+func (c *YouTubeAPIClient) GetMyPlaylists(ctx context.Context, accessToken, pageToken string, maxResults int) (*YouTubeList[YouTubePlaylist], error) {
+	query := listQuery("snippet,contentDetails,status", pageToken, maxResults)
+	query.Set("mine", "true")
+
+	var list YouTubeList[YouTubePlaylist]
+	err := c.call(ctx, youtubeListCost, apiRequest{
+		method: http.MethodGet,
+		path:   "/playlists",
+		query:  query,
+		header: bearer(accessToken),
+	}, &list)
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (c *YouTubeAPIClient) GetPlaylistItems(ctx context.Context, accessToken, playlistID, pageToken string, maxResults int) (*YouTubeList[YouTubePlaylistItem], error) {
+	query := listQuery("snippet,contentDetails", pageToken, maxResults)
+	query.Set("playlistId", playlistID)
+
+	var list YouTubeList[YouTubePlaylistItem]
+	err := c.call(ctx, youtubeListCost, apiRequest{
+		method: http.MethodGet,
+		path:   "/playlistItems",
+		query:  query,
+		header: bearer(accessToken),
+	}, &list)
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetVideos describes up to 50 videos; unavailable ones are left out
+func (c *YouTubeAPIClient) GetVideos(ctx context.Context, accessToken string, videoIDs []string) ([]YouTubeVideo, error) {
+	query := url.Values{}
+	query.Set("part", "snippet,contentDetails")
+	query.Set("id", strings.Join(videoIDs, ","))
+
+	var list YouTubeList[YouTubeVideo]
+	err := c.call(ctx, youtubeListCost, apiRequest{
+		method: http.MethodGet,
+		path:   "/videos",
+		query:  query,
+		header: bearer(accessToken),
+	}, &list)
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// SearchVideos looks for music videos. At 100 units it is by far the most
+// expensive call, so callers should search once per track
+func (c *YouTubeAPIClient) SearchVideos(ctx context.Context, accessToken, q string, maxResults int) ([]YouTubeSearchResult, error) {
+	query := url.Values{}
+	query.Set("part", "snippet")
+	query.Set("type", "video")
+	query.Set("videoCategoryId", youtubeMusicCategory)
+	query.Set("q", q)
+	query.Set("maxResults", strconv.Itoa(maxResults))
+
+	var list YouTubeList[YouTubeSearchResult]
+	err := c.call(ctx, youtubeSearchCost, apiRequest{
+		method: http.MethodGet,
+		path:   "/search",
+		query:  query,
+		header: bearer(accessToken),
+	}, &list)
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (c *YouTubeAPIClient) CreatePlaylist(ctx context.Context, accessToken, title, description, privacyStatus string) (*YouTubePlaylist, error) {
+	query := url.Values{}
+	query.Set("part", "snippet,status")
+
+	var playlist YouTubePlaylist
+	err := c.call(ctx, youtubeWriteCost, apiRequest{
+		method: http.MethodPost,
+		path:   "/playlists",
+		query:  query,
+		header: bearer(accessToken),
+		body: map[string]any{
+			"snippet": map[string]string{
+				"title":       title,
+				"description": description,
+			},
+			"status": map[string]string{
+				"privacyStatus": privacyStatus,
+			},
+		},
+	}, &playlist)
+	if err != nil {
+		return nil, err
+	}
+	return &playlist, nil
+}
+
+// InsertPlaylistItem appends a video; the API takes one item per call
+func (c *YouTubeAPIClient) InsertPlaylistItem(ctx context.Context, accessToken, playlistID, videoID string) error {
+	query := url.Values{}
+	query.Set("part", "snippet")
+
+	return c.call(ctx, youtubeWriteCost, apiRequest{
+		method: http.MethodPost,
+		path:   "/playlistItems",
+		query:  query,
+		header: bearer(accessToken),
+		body: map[string]any{
+			"snippet": map[string]any{
+				"playlistId": playlistID,
+				"resourceId": map[string]string{
+					"kind":    "youtube#video",
+					"videoId": videoID,
+				},
+			},
+		},
+	}, nil)
+}
+
+func (c *YouTubeAPIClient) DeletePlaylistItem(ctx context.Context, accessToken, playlistItemID string) error {
+	query := url.Values{}
+	query.Set("id", playlistItemID)
+
+	return c.call(ctx, youtubeWriteCost, apiRequest{
+		method: http.MethodDelete,
+		path:   "/playlistItems",
+		query:  query,
+		header: bearer(accessToken),
+	}, nil)
+}
+
+// call charges the quota and sends the request. Google's own quota errors
+// mark the quota as spent so no further calls are made today
+func (c *YouTubeAPIClient) call(ctx context.Context, cost int, req apiRequest, out any) error {
+	if err := c.quota.Reserve(cost); err != nil {
+		return err
+	}
+
+	err := c.api.do(ctx, req, out)
+
+	var apiErr *APIError
+	if stderrors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden &&
+		(strings.Contains(apiErr.Body, "quotaExceeded") || strings.Contains(apiErr.Body, "dailyLimitExceeded")) {
+		c.quota.Exhaust()
+		return fmt.Errorf("%w: %s", ErrYouTubeQuotaExhausted, apiErr.Body)
+	}
+	return err
+}
+
+func listQuery(part, pageToken string, maxResults int) url.Values {
+	query := url.Values{}
+	query.Set("part", part)
+	query.Set("maxResults", strconv.Itoa(maxResults))
+	if pageToken != "" {
+		query.Set("pageToken", pageToken)
+	}
+	return query
+}
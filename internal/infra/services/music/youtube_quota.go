@@ -0,0 +1,88 @@
+package music
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Cost in quota units of the YouTube Data API calls used here
+const (
+	youtubeListCost   = 1
+	youtubeSearchCost = 100
+	youtubeWriteCost  = 50
+)
+
+// ErrYouTubeQuotaExhausted is returned instead of calls that would go over
+// the daily quota
+var ErrYouTubeQuotaExhausted = errors.New("youtube daily quota exhausted")
+
+// YouTubeQuota counts the quota units spent today. The quota belongs to the
+// Google Cloud project, not to users, and resets at midnight Pacific time.
+// The count lives in memory, so with several instances each needs its share
+// of the quota as limit
+type YouTubeQuota struct {
+	limit    int
+	location *time.Location
+	now      func() time.Time
+
+	mu   sync.Mutex
+	day  string
+	used int
+}
+
+func NewYouTubeQuota(dailyLimit int) *YouTubeQuota {
+	location, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		// Without tzdata the reset drifts an hour during daylight saving time
+		location = time.FixedZone("PST", -8*60*60)
+	}
+
+	return &YouTubeQuota{
+		limit:    dailyLimit,
+		location: location,
+		now:      time.Now,
+	}
+}
+
+// Reserve spends units before a call, failing when they would go over the
+// limit. Google charges rejected requests too, so units are not given back
+func (q *YouTubeQuota) Reserve(units int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.rollover()
+	if q.used+units > q.limit {
+		return ErrYouTubeQuotaExhausted
+	}
+
+	q.used += units
+	return nil
+}
+
+// Exhaust marks today's quota as spent after Google reported it so, which
+// happens when other clients share the project
+func (q *YouTubeQuota) Exhaust() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.rollover()
+	q.used = q.limit
+}
+
+// Remaining returns the units left today
+func (q *YouTubeQuota) Remaining() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.rollover()
+	return q.limit - q.used
+}
+
+func (q *YouTubeQuota) rollover() {
+	day := q.now().In(q.location).Format(time.DateOnly)
+	if day != q.day {
+		q.day = day
+		q.used = 0
+	}
+}
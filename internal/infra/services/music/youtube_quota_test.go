@@ -0,0 +1,105 @@
+package music
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	// The reset follows America/Los_Angeles even where tzdata is not installed
+	_ "time/tzdata"
+)
+
+func TestYouTubeQuotaRollover(t *testing.T) {
+	pacific := time.FixedZone("PDT", -7*60*60)
+
+	type step struct {
+		at            time.Time
+		reserve       int
+		exhaust       bool
+		wantErr       bool
+		wantRemaining int
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "spent quota is refused until midnight Pacific",
+			steps: []step{
+				{at: time.Date(2026, 10, 17, 23, 0, 0, 0, pacific), reserve: 80, wantRemaining: 20},
+				{at: time.Date(2026, 10, 17, 23, 59, 59, 0, pacific), reserve: 50, wantErr: true, wantRemaining: 20},
+				{at: time.Date(2026, 10, 18, 0, 0, 0, 0, pacific), reserve: 50, wantRemaining: 50},
+			},
+		},
+		{
+			name: "midnight UTC is not a reset",
+			steps: []step{
+				{at: time.Date(2026, 10, 17, 23, 30, 0, 0, time.UTC), reserve: 100, wantRemaining: 0},
+				{at: time.Date(2026, 10, 18, 0, 30, 0, 0, time.UTC), reserve: 1, wantErr: true, wantRemaining: 0},
+				{at: time.Date(2026, 10, 18, 7, 0, 0, 0, time.UTC), reserve: 1, wantRemaining: 99},
+			},
+		},
+		{
+			name: "quota reported spent by Google lasts until the reset",
+			steps: []step{
+				{at: time.Date(2026, 10, 17, 10, 0, 0, 0, pacific), reserve: 1, wantRemaining: 99},
+				{at: time.Date(2026, 10, 17, 10, 1, 0, 0, pacific), exhaust: true, wantRemaining: 0},
+				{at: time.Date(2026, 10, 17, 18, 0, 0, 0, pacific), reserve: 1, wantErr: true, wantRemaining: 0},
+				{at: time.Date(2026, 10, 18, 0, 0, 1, 0, pacific), reserve: 1, wantRemaining: 99},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quota := NewYouTubeQuota(100)
+
+			for i, s := range tt.steps {
+				quota.now = func() time.Time { return s.at }
+
+				if s.exhaust {
+					quota.Exhaust()
+				}
+				if s.reserve > 0 {
+					err := quota.Reserve(s.reserve)
+					if s.wantErr != stderrors.Is(err, ErrYouTubeQuotaExhausted) {
+						t.Fatalf("step %d: Reserve(%d) error = %v, want exhausted %v", i, s.reserve, err, s.wantErr)
+					}
+				}
+
+				if got := quota.Remaining(); got != s.wantRemaining {
+					t.Errorf("step %d: Remaining = %d, want %d", i, got, s.wantRemaining)
+				}
+			}
+		})
+	}
+}
+
+func TestYouTubeRateLimitedCallsAreNotRetried(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewYouTubeAPIClient(server.Client(), server.URL, NewYouTubeQuota(100))
+
+	_, err := client.GetMyPlaylists(context.Background(), "token", "", 50)
+
+	var apiErr *APIError
+	if !stderrors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("GetMyPlaylists: error = %v, want rate limited APIError", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+	if got := client.RemainingQuota(); got != 100-youtubeListCost {
+		t.Errorf("RemainingQuota = %d, want %d", got, 100-youtubeListCost)
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+)
+
+// APIKeyHeaderName es la alternativa al header Authorization para las API keys
+const APIKeyHeaderName = "X-API-Key"
+
+// APIKeyAuthenticator valida una API key personal y devuelve los claims
+// equivalentes a los de un JWT. Devuelve nil sin error si la key no es válida
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*Claims, error)
+}
+
+// JWTOrAPIKey acepta un JWT o una API key (Authorization: Bearer spk_... o
+// X-API-Key) y deja en el contexto los mismos claims que JWT, de modo que
+// GetUserFromContext y RequireScope funcionan igual con ambos. Las API keys
+// no tienen rol ni jti: las rutas de sesión (logout, /me) deben seguir usando JWT
+func JWTOrAPIKey(parser TokenParser, denylist TokenDenylist, apiKeys APIKeyAuthenticator) echo.MiddlewareFunc {
+	jwtMiddleware := JWT(parser, denylist)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		jwtNext := jwtMiddleware(next)
+
+		return func(c echo.Context) error {
+			key := apiKeyFromRequest(c)
+			if key == "" {
+				return jwtNext(c)
+			}
+
+			claims, err := apiKeys.AuthenticateAPIKey(c.Request().Context(), key)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, "failed to validate api key")
+			}
+			if claims == nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid or expired api key")
+			}
+
+			// Mismo formato que guarda JWT para que GetUserFromContext no distinga
+			c.Set("user", &jwt.Token{Claims: claims, Valid: true})
+
+			return next(c)
+		}
+	}
+}
+
+// RequireAPIKeyScope exige los permisos solo a las peticiones con API key. Para
+// rutas de /me que antes solo aceptaban JWT: las sesiones siguen entrando como
+// hasta ahora, sin depender de los scopes de access tokens ya emitidos
+func RequireAPIKeyScope(scopes ...string) echo.MiddlewareFunc {
+	requireScope := RequireScope(scopes...)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		scoped := requireScope(next)
+
+		return func(c echo.Context) error {
+			claims, err := GetUserFromContext(c)
+			if err != nil {
+				return err
+			}
+			if claims.APIKeyID == "" {
+				return next(c)
+			}
+			return scoped(c)
+		}
+	}
+}
+
+func apiKeyFromRequest(c echo.Context) string {
+	if key := c.Request().Header.Get(APIKeyHeaderName); key != "" {
+		return key
+	}
+
+	auth := c.Request().Header.Get("Authorization")
+	if token, ok := strings.CutPrefix(auth, "Bearer "); ok && strings.HasPrefix(token, entities.APIKeyPrefix) {
+		return token
+	}
+	return ""
+}
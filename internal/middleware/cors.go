@@ -11,14 +11,14 @@ func CORS() echo.MiddlewareFunc {
 
 	allowOrigins := []string{"http://localhost:3000", "http://localhost:5173"}
 	if cfg.Server.Environment == config.Production {
-		// Add your production frontend URLs here
-		allowOrigins = []string{"https://yourdomain.com"}
+		// Las cookies de sesión solo se envían desde el frontend configurado
+		allowOrigins = []string{cfg.Server.FrontendURL}
 	}
 
 	return middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     allowOrigins,
 		AllowMethods:     []string{echo.GET, echo.POST, echo.PUT, echo.DELETE, echo.PATCH, echo.OPTIONS},
-		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, CSRFHeaderName},
 		AllowCredentials: true,
 		ExposeHeaders:    []string{echo.HeaderContentLength, echo.HeaderContentType},
 		MaxAge:           86400, // 24 hours
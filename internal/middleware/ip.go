@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// IPExtractor decide de dónde sale c.RealIP(). Sin proxies de confianza se usa
+// la IP de la conexión, ya que X-Forwarded-For y X-Real-IP los puede enviar
+// cualquier cliente para esquivar los límites por IP
+func IPExtractor(trustedProxies string) (echo.IPExtractor, error) {
+	var options []echo.TrustOption
+	for _, cidr := range strings.Split(trustedProxies, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		_, ipRange, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy range %q: %w", cidr, err)
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+
+	if len(options) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	// Solo los rangos configurados: Echo confía por defecto en loopback y redes privadas
+	options = append(options, echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false))
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -9,15 +11,47 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// TokenUseAccess es el valor del claim token_use de los access tokens; los
+// refresh tokens se firman con las mismas claves y llevan "refresh"
+const TokenUseAccess = "access"
+
 // Claims estructura personalizada para JWT
 type Claims struct {
 	UserID uuid.UUID `json:"user_id"`
 	Email  string    `json:"email"`
+	Role   string    `json:"role"`
+	// Scope son los permisos del rol separados por espacios
+	Scope string `json:"scope,omitempty"`
+	// TokenUse distingue los access tokens de los refresh tokens
+	TokenUse string `json:"token_use"`
+	// APIKeyID es el id de la API key cuando la petición no se autenticó con un JWT
+	APIKeyID string `json:"-"`
 	jwt.RegisteredClaims
 }
 
+// HasRole indica si el token tiene alguno de los roles
+func (c *Claims) HasRole(roles ...string) bool {
+	return slices.Contains(roles, c.Role)
+}
+
+// HasScope indica si el token incluye el permiso
+func (c *Claims) HasScope(scope string) bool {
+	return slices.Contains(strings.Fields(c.Scope), scope)
+}
+
+// TokenDenylist indica si un access token (por su jti) fue revocado antes de expirar
+type TokenDenylist interface {
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+}
+
+// TokenParser verifica la firma de un token eligiendo la clave por su kid
+type TokenParser interface {
+	Parse(tokenString string, claims jwt.Claims, options ...jwt.ParserOption) (*jwt.Token, error)
+}
+
 // JWT middleware personalizado
-func JWT(secret string) echo.MiddlewareFunc {
+// Si denylist no es nil, los tokens sin jti o revocados son rechazados
+func JWT(parser TokenParser, denylist TokenDenylist) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			// Obtener token del header Authorization
@@ -37,24 +71,34 @@ func JWT(secret string) echo.MiddlewareFunc {
 				}
 			}
 
-			// Parsear y validar token
-			token, err := jwt.ParseWithClaims(auth, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-				// Verificar método de firma
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, echo.NewHTTPError(http.StatusUnauthorized, "invalid token signing method")
-				}
-				return []byte(secret), nil
-			})
+			// Parsear y validar token (el parser rechaza kids desconocidos y algoritmos que no coinciden)
+			token, err := parser.Parse(auth, &Claims{}, jwt.WithExpirationRequired())
 
 			if err != nil {
 				return echo.NewHTTPError(http.StatusUnauthorized, "invalid or expired token")
 			}
 
-			// Verificar que el token es válido
-			if !token.Valid {
+			// Verificar que el token es válido y es un access token
+			claims, ok := token.Claims.(*Claims)
+			if !token.Valid || !ok || claims.TokenUse != TokenUseAccess {
 				return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
 			}
 
+			// Verificar que el token no fue revocado (logout)
+			if denylist != nil {
+				if claims.ID == "" {
+					return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+				}
+
+				revoked, err := denylist.IsRevoked(c.Request().Context(), claims.ID)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, "failed to validate token")
+				}
+				if revoked {
+					return echo.NewHTTPError(http.StatusUnauthorized, "token has been revoked")
+				}
+			}
+
 			// Guardar token en el contexto
 			c.Set("user", token)
 
@@ -88,3 +132,37 @@ func RequireAuth(next echo.HandlerFunc) echo.HandlerFunc {
 		return next(c)
 	}
 }
+
+// RequireRole middleware que requiere alguno de los roles. Va después de JWT
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, err := GetUserFromContext(c)
+			if err != nil {
+				return err
+			}
+			if !claims.HasRole(roles...) {
+				return echo.NewHTTPError(http.StatusForbidden, "insufficient role")
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireScope middleware que requiere todos los permisos. Va después de JWT
+func RequireScope(scopes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, err := GetUserFromContext(c)
+			if err != nil {
+				return err
+			}
+			for _, scope := range scopes {
+				if !claims.HasScope(scope) {
+					return echo.NewHTTPError(http.StatusForbidden, "insufficient scope")
+				}
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+// maxAPIKeysPerUser keeps a leaked session from minting keys without bound
+const maxAPIKeysPerUser = 25
+
+type APIKeyResponse struct {
+	ID         string
+	Name       string
+	Prefix     string
+	Scopes     []string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+func toAPIKeyResponse(key *entities.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         key.ID().String(),
+		Name:       key.Name(),
+		Prefix:     key.Prefix(),
+		Scopes:     key.Scopes(),
+		ExpiresAt:  key.ExpiresAt(),
+		LastUsedAt: key.LastUsedAt(),
+		CreatedAt:  key.CreatedAt(),
+	}
+}
+
+type CreateAPIKeyRequest struct {
+	UserID    string
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time // nil for a key that does not expire
+}
+
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	// Key is the plain key, returned only once
+	Key string
+}
+
+type CreateAPIKeyUseCase struct {
+	apiKeyRepo repositories.APIKeyRepository
+	clock      providers.Clock
+}
+
+func NewCreateAPIKeyUseCase(apiKeyRepo repositories.APIKeyRepository, clock providers.Clock) *CreateAPIKeyUseCase {
+	return &CreateAPIKeyUseCase{
+		apiKeyRepo: apiKeyRepo,
+		clock:      clock,
+	}
+}
+
+func (uc *CreateAPIKeyUseCase) Execute(ctx context.Context, req CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
+	userID, err := valueobjects.ParseUserID(req.UserID)
+	if err != nil {
+		return nil, errors.NewDomainError("invalid_user_id", "Invalid user ID")
+	}
+
+	existing, err := uc.apiKeyRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxAPIKeysPerUser {
+		return nil, errors.NewDomainError("api_key_limit", fmt.Sprintf("A user can have at most %d API keys", maxAPIKeysPerUser))
+	}
+
+	key, plain, err := entities.NewAPIKey(userID, req.Name, req.Scopes, req.ExpiresAt, uc.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.apiKeyRepo.Save(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return &CreateAPIKeyResponse{
+		APIKeyResponse: toAPIKeyResponse(key),
+		Key:            plain,
+	}, nil
+}
+
+type ListAPIKeysRequest struct {
+	UserID string
+}
+
+type ListAPIKeysResponse struct {
+	Keys []APIKeyResponse
+}
+
+type ListAPIKeysUseCase struct {
+	apiKeyRepo repositories.APIKeyRepository
+}
+
+func NewListAPIKeysUseCase(apiKeyRepo repositories.APIKeyRepository) *ListAPIKeysUseCase {
+	return &ListAPIKeysUseCase{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+func (uc *ListAPIKeysUseCase) Execute(ctx context.Context, req ListAPIKeysRequest) (*ListAPIKeysResponse, error) {
+	userID, err := valueobjects.ParseUserID(req.UserID)
+	if err != nil {
+		return nil, errors.NewDomainError("invalid_user_id", "Invalid user ID")
+	}
+
+	keys, err := uc.apiKeyRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, toAPIKeyResponse(key))
+	}
+
+	return &ListAPIKeysResponse{
+		Keys: responses,
+	}, nil
+}
+
+type UpdateAPIKeyRequest struct {
+	UserID string
+	KeyID  string
+	Name   string
+}
+
+// UpdateAPIKeyUseCase renames a key. Scopes and expiry are fixed at creation:
+// widening them would silently empower a key that may already be deployed
+type UpdateAPIKeyUseCase struct {
+	apiKeyRepo repositories.APIKeyRepository
+	clock      providers.Clock
+}
+
+func NewUpdateAPIKeyUseCase(apiKeyRepo repositories.APIKeyRepository, clock providers.Clock) *UpdateAPIKeyUseCase {
+	return &UpdateAPIKeyUseCase{
+		apiKeyRepo: apiKeyRepo,
+		clock:      clock,
+	}
+}
+
+func (uc *UpdateAPIKeyUseCase) Execute(ctx context.Context, req UpdateAPIKeyRequest) (*APIKeyResponse, error) {
+	key, err := findUserAPIKey(ctx, uc.apiKeyRepo, req.UserID, req.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := key.Rename(req.Name, uc.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := uc.apiKeyRepo.Save(ctx, key); err != nil {
+		return nil, err
+	}
+
+	response := toAPIKeyResponse(key)
+	return &response, nil
+}
+
+type DeleteAPIKeyRequest struct {
+	UserID string
+	KeyID  string
+}
+
+type DeleteAPIKeyResponse struct {
+	Success bool
+	Message string
+}
+
+type DeleteAPIKeyUseCase struct {
+	apiKeyRepo repositories.APIKeyRepository
+}
+
+func NewDeleteAPIKeyUseCase(apiKeyRepo repositories.APIKeyRepository) *DeleteAPIKeyUseCase {
+	return &DeleteAPIKeyUseCase{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+func (uc *DeleteAPIKeyUseCase) Execute(ctx context.Context, req DeleteAPIKeyRequest) (*DeleteAPIKeyResponse, error) {
+	key, err := findUserAPIKey(ctx, uc.apiKeyRepo, req.UserID, req.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.apiKeyRepo.Delete(ctx, key.ID()); err != nil {
+		return nil, err
+	}
+
+	return &DeleteAPIKeyResponse{
+		Success: true,
+		Message: "API key deleted successfully",
+	}, nil
+}
+
+// findUserAPIKey loads a key of the user. Keys of other users are reported as
+// missing to avoid leaking them
+func findUserAPIKey(ctx context.Context, apiKeyRepo repositories.APIKeyRepository, rawUserID, rawKeyID string) (*entities.APIKey, error) {
+	userID, err := valueobjects.ParseUserID(rawUserID)
+	if err != nil {
+		return nil, errors.NewDomainError("invalid_user_id", "Invalid user ID")
+	}
+
+	keyID, err := valueobjects.ParseAPIKeyID(rawKeyID)
+	if err != nil {
+		return nil, errors.NewValidationError("id", "invalid_api_key_id", "Invalid API key ID")
+	}
+
+	key, err := apiKeyRepo.FindByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !key.UserID().Equals(userID) {
+		return nil, errors.NewNotFoundError("api_key", "API key not found")
+	}
+
+	return key, nil
+}
+
+type AuthenticateAPIKeyRequest struct {
+	Key string
+}
+
+type AuthenticateAPIKeyResponse struct {
+	KeyID  string
+	UserID string
+	Email  string
+	Scopes []string
+}
+
+// AuthenticateAPIKeyUseCase resolves the user and scopes of a presented key
+type AuthenticateAPIKeyUseCase struct {
+	apiKeyRepo repositories.APIKeyRepository
+	userRepo   repositories.UserRepository
+	clock      providers.Clock
+	policy     entities.AuthenticationPolicy
+}
+
+func NewAuthenticateAPIKeyUseCase(
+	apiKeyRepo repositories.APIKeyRepository,
+	userRepo repositories.UserRepository,
+	clock providers.Clock,
+	policy entities.AuthenticationPolicy,
+) *AuthenticateAPIKeyUseCase {
+	return &AuthenticateAPIKeyUseCase{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+		clock:      clock,
+		policy:     policy,
+	}
+}
+
+func (uc *AuthenticateAPIKeyUseCase) Execute(ctx context.Context, req AuthenticateAPIKeyRequest) (*AuthenticateAPIKeyResponse, error) {
+	if !strings.HasPrefix(req.Key, entities.APIKeyPrefix) {
+		return nil, invalidAPIKeyError()
+	}
+
+	key, err := uc.apiKeyRepo.FindByHash(ctx, entities.HashAPIKey(req.Key))
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); ok {
+			return nil, invalidAPIKeyError()
+		}
+		return nil, err
+	}
+
+	now := uc.clock.Now()
+	if err := key.CanAuthenticate(now); err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, key.UserID())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := user.CanAuthenticate(uc.policy); err != nil {
+		return nil, err
+	}
+
+	if key.MarkUsed(now) {
+		if err := uc.apiKeyRepo.Save(ctx, key); err != nil {
+			return nil, err
+		}
+	}
+
+	return &AuthenticateAPIKeyResponse{
+		KeyID:  key.ID().String(),
+		UserID: user.ID().String(),
+		Email:  user.Email().String(),
+		Scopes: key.Scopes(),
+	}, nil
+}
+
+func invalidAPIKeyError() error {
+	return errors.NewAuthenticationError("invalid_api_key", "Invalid API key")
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+type GetAppleMusicDeveloperTokenResponse struct {
+	DeveloperToken string
+	ExpiresAt      time.Time
+}
+
+// GetAppleMusicDeveloperTokenUseCase hands the developer token to clients, which
+// pass it to MusicKit JS to obtain the user's Music-User-Token
+type GetAppleMusicDeveloperTokenUseCase struct {
+	developerTokens providers.MusicDeveloperTokenProvider
+}
+
+// NewGetAppleMusicDeveloperTokenUseCase accepts a nil provider when Apple
+// Music is not configured
+func NewGetAppleMusicDeveloperTokenUseCase(developerTokens providers.MusicDeveloperTokenProvider) *GetAppleMusicDeveloperTokenUseCase {
+	return &GetAppleMusicDeveloperTokenUseCase{
+		developerTokens: developerTokens,
+	}
+}
+
+func (uc *GetAppleMusicDeveloperTokenUseCase) Execute(ctx context.Context) (*GetAppleMusicDeveloperTokenResponse, error) {
+	if uc.developerTokens == nil {
+		return nil, errors.NewNotFoundError("music_service", "Apple Music is not configured")
+	}
+
+	token, expiresAt, err := uc.developerTokens.DeveloperToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetAppleMusicDeveloperTokenResponse{
+		DeveloperToken: token,
+		ExpiresAt:      expiresAt,
+	}, nil
+}
+
+type SetAppleMusicTokenRequest struct {
+	UserID         string
+	MusicUserToken string
+}
+
+type SetAppleMusicTokenResponse struct {
+	Success bool
+	Message string
+}
+
+// SetAppleMusicTokenUseCase stores the Music-User-Token on the user's
+// apple_music account, creating it on the first authorization. Users do not
+// need to sign in with Apple to use Apple Music
+type SetAppleMusicTokenUseCase struct {
+	accountRepo repositories.AccountRepository
+}
+
+func NewSetAppleMusicTokenUseCase(accountRepo repositories.AccountRepository) *SetAppleMusicTokenUseCase {
+	return &SetAppleMusicTokenUseCase{
+		accountRepo: accountRepo,
+	}
+}
+
+func (uc *SetAppleMusicTokenUseCase) Execute(ctx context.Context, req SetAppleMusicTokenRequest) (*SetAppleMusicTokenResponse, error) {
+	userID, err := valueobjects.ParseUserID(req.UserID)
+	if err != nil {
+		return nil, errors.NewDomainError("invalid_user_id", "Invalid user ID")
+	}
+
+	token := strings.TrimSpace(req.MusicUserToken)
+	if token == "" {
+		return nil, errors.NewValidationError("musicUserToken", "required", "Music user token is required")
+	}
+
+	account, err := uc.accountRepo.FindByUserIDAndProvider(ctx, userID, entities.AppleMusicProvider)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); !ok {
+			return nil, err
+		}
+		account = entities.NewAppleMusicAccount(userID)
+	}
+
+	if err := account.SetMusicUserToken(token); err != nil {
+		return nil, err
+	}
+
+	if err := uc.accountRepo.Save(ctx, account); err != nil {
+		return nil, err
+	}
+
+	return &SetAppleMusicTokenResponse{
+		Success: true,
+		Message: "Apple Music authorized successfully",
+	}, nil
+}
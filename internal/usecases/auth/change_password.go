@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+type ChangePasswordRequest struct {
+	UserID          string
+	CurrentPassword string
+	NewPassword     string
+	// AccessTokenID identifies the session making the request, which is kept
+	AccessTokenID string
+}
+
+type ChangePasswordResponse struct {
+	Success bool
+	Message string
+}
+
+type ChangePasswordUseCase struct {
+	accountRepo      repositories.AccountRepository
+	tokenRepo        repositories.TokenRepository
+	revokedTokenRepo repositories.RevokedTokenRepository
+	tokenGen         TokenGenerator
+}
+
+func NewChangePasswordUseCase(
+	accountRepo repositories.AccountRepository,
+	tokenRepo repositories.TokenRepository,
+	revokedTokenRepo repositories.RevokedTokenRepository,
+	tokenGen TokenGenerator,
+) *ChangePasswordUseCase {
+	return &ChangePasswordUseCase{
+		accountRepo:      accountRepo,
+		tokenRepo:        tokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		tokenGen:         tokenGen,
+	}
+}
+
+func (uc *ChangePasswordUseCase) Execute(ctx context.Context, req ChangePasswordRequest) (*ChangePasswordResponse, error) {
+	userID, err := valueobjects.ParseUserID(req.UserID)
+	if err != nil {
+		return nil, errors.NewDomainError("invalid_user_id", "Invalid user ID")
+	}
+
+	account, err := uc.accountRepo.FindByUserIDAndProvider(ctx, userID, entities.UserpassProvider)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); ok {
+			return nil, errors.NewDomainError("no_password_account", "This user does not sign in with a password")
+		}
+		return nil, err
+	}
+
+	currentPassword, err := valueobjects.NewPlainPassword(req.CurrentPassword)
+	if err != nil || !account.Password().Verify(currentPassword) {
+		return nil, errors.NewValidationError("currentPassword", "invalid_current_password", "Current password is incorrect")
+	}
+
+	newPassword, err := valueobjects.NewPlainPassword(req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.Password().Verify(newPassword) {
+		return nil, errors.NewValidationError("newPassword", "password_unchanged", "New password must be different from the current one")
+	}
+
+	hashedPassword, err := newPassword.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := account.ChangePassword(hashedPassword); err != nil {
+		return nil, err
+	}
+
+	if err := uc.accountRepo.Save(ctx, account); err != nil {
+		return nil, err
+	}
+
+	if err := uc.revokeOtherSessions(ctx, userID, req.AccessTokenID); err != nil {
+		return nil, err
+	}
+
+	return &ChangePasswordResponse{
+		Success: true,
+		Message: "Password changed successfully",
+	}, nil
+}
+
+// revokeOtherSessions signs the user out everywhere except the session whose
+// access token made the request
+func (uc *ChangePasswordUseCase) revokeOtherSessions(ctx context.Context, userID valueobjects.UserID, currentAccessTokenID string) error {
+	refreshTokens, err := uc.tokenRepo.FindUserRefreshTokens(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(time.Duration(uc.tokenGen.GetAccessTokenExpiration()) * time.Second)
+
+	for _, refreshToken := range refreshTokens {
+		if currentAccessTokenID != "" && refreshToken.AccessTokenID() == currentAccessTokenID {
+			continue
+		}
+
+		if err := uc.tokenRepo.DeleteRefreshToken(ctx, refreshToken.Token()); err != nil {
+			return err
+		}
+
+		if refreshToken.AccessTokenID() != "" {
+			if err := uc.revokedTokenRepo.Revoke(ctx, refreshToken.AccessTokenID(), userID, expiresAt); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+type ChangeUserRoleRequest struct {
+	ActorID string // The admin making the change
+	UserID  string
+	Role    string
+}
+
+type ChangeUserRoleResponse struct {
+	Success bool
+	Message string
+	UserID  string
+	Role    string
+}
+
+// ChangeUserRoleUseCase sets the role of a user. The role is embedded in the
+// access tokens, so every session of the user is ended and the next login
+// picks up the new role
+type ChangeUserRoleUseCase struct {
+	userRepo         repositories.UserRepository
+	tokenRepo        repositories.TokenRepository
+	revokedTokenRepo repositories.RevokedTokenRepository
+	tokenGen         TokenGenerator
+}
+
+func NewChangeUserRoleUseCase(
+	userRepo repositories.UserRepository,
+	tokenRepo repositories.TokenRepository,
+	revokedTokenRepo repositories.RevokedTokenRepository,
+	tokenGen TokenGenerator,
+) *ChangeUserRoleUseCase {
+	return &ChangeUserRoleUseCase{
+		userRepo:         userRepo,
+		tokenRepo:        tokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		tokenGen:         tokenGen,
+	}
+}
+
+func (uc *ChangeUserRoleUseCase) Execute(ctx context.Context, req ChangeUserRoleRequest) (*ChangeUserRoleResponse, error) {
+	userID, err := valueobjects.ParseUserID(req.UserID)
+	if err != nil {
+		return nil, errors.NewDomainError("invalid_user_id", "Invalid user ID")
+	}
+
+	role, err := entities.ParseRole(req.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keeps the last admin from locking everyone out by demoting themselves
+	if req.ActorID == userID.String() {
+		return nil, errors.NewDomainError("cannot_change_own_role", "You cannot change your own role")
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := user.ChangeRole(role)
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		if err := uc.userRepo.Save(ctx, user); err != nil {
+			return nil, err
+		}
+
+		expiresAt := time.Now().Add(time.Duration(uc.tokenGen.GetAccessTokenExpiration()) * time.Second)
+		if err := revokeUserSessions(ctx, uc.tokenRepo, uc.revokedTokenRepo, userID, expiresAt); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ChangeUserRoleResponse{
+		Success: true,
+		Message: fmt.Sprintf("User role set to %s", role),
+		UserID:  user.ID().String(),
+		Role:    string(user.Role()),
+	}, nil
+}
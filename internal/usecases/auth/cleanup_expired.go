@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+)
+
+// CleanupExpiredRecordsUseCase deletes the auth records that can no longer be
+// used: expired refresh tokens and denylist entries, expired verification
+// tokens, and login attempt counters past their reset window. It is meant to
+// run periodically
+type CleanupExpiredRecordsUseCase struct {
+	tokenRepo        repositories.TokenRepository
+	revokedTokenRepo repositories.RevokedTokenRepository
+	verificationRepo repositories.VerificationRepository
+	loginAttemptRepo repositories.LoginAttemptRepository
+	clock            providers.Clock
+	// attemptRetention keeps login and mail attempt counters while they still count
+	attemptRetention time.Duration
+}
+
+func NewCleanupExpiredRecordsUseCase(
+	tokenRepo repositories.TokenRepository,
+	revokedTokenRepo repositories.RevokedTokenRepository,
+	verificationRepo repositories.VerificationRepository,
+	loginAttemptRepo repositories.LoginAttemptRepository,
+	clock providers.Clock,
+	throttleConfig LoginThrottleConfig,
+	mailThrottleConfig MailThrottleConfig,
+) *CleanupExpiredRecordsUseCase {
+	return &CleanupExpiredRecordsUseCase{
+		tokenRepo:        tokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		verificationRepo: verificationRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		clock:            clock,
+		attemptRetention: max(
+			throttleConfig.Email.ResetAfter,
+			throttleConfig.IP.ResetAfter,
+			mailThrottleConfig.Email.ResetAfter,
+			mailThrottleConfig.IP.ResetAfter,
+		),
+	}
+}
+
+// Execute runs every cleanup, even when one of them fails
+func (uc *CleanupExpiredRecordsUseCase) Execute(ctx context.Context) error {
+	return stderrors.Join(
+		uc.tokenRepo.CleanupExpiredTokens(ctx),
+		uc.revokedTokenRepo.CleanupExpired(ctx),
+		uc.verificationRepo.CleanupExpired(ctx),
+		uc.loginAttemptRepo.CleanupExpired(ctx, uc.clock.Now().Add(-uc.attemptRetention)),
+	)
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+type GetOAuthURLRequest struct {
+	Provider string
+	// UserID is set when a logged-in user links the provider to their account
+	UserID string
+	// CodeChallenge is sent by login clients that cannot keep the nonce
+	// cookie; they redeem the one-time code with the matching verifier
+	CodeChallenge string
+}
+
+type GetOAuthURLResponse struct {
+	URL   string
+	State string // The generated state to be used in OAuth flow
+	// BrowserNonce is kept by the browser that starts the flow and must come
+	// back with the callback, so a state cannot be completed by someone else
+	BrowserNonce string
+	ExpiresAt    time.Time
+}
+
+type GetOAuthURLUseCase struct {
+	registry         *providers.OAuthProviderRegistry
+	verificationRepo repositories.VerificationRepository
+	expirationState  time.Duration
+}
+
+func NewGetOAuthURLUseCase(
+	registry *providers.OAuthProviderRegistry,
+	verificationRepo repositories.VerificationRepository,
+	expirationState time.Duration,
+) *GetOAuthURLUseCase {
+	return &GetOAuthURLUseCase{
+		registry:         registry,
+		verificationRepo: verificationRepo,
+		expirationState:  expirationState,
+	}
+}
+
+func (uc *GetOAuthURLUseCase) Execute(ctx context.Context, req GetOAuthURLRequest) (*GetOAuthURLResponse, error) {
+	providerName := parseOAuthProvider(req.Provider)
+	provider, err := uc.registry.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.UserID == "" && !providerName.CanSignIn() {
+		return nil, linkOnlyProviderError(providerName)
+	}
+
+	// Create a verification token for OAuth state validation
+	// The token itself is the state parameter, and it records whether the
+	// callback should log in or link the account to the requesting user
+	verificationToken, err := uc.newState(req.UserID, providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CodeChallenge != "" {
+		if err := verificationToken.BindToClient(req.CodeChallenge); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.verificationRepo.Save(ctx, verificationToken); err != nil {
+		return nil, err
+	}
+
+	state := verificationToken.Token()
+	url := provider.GetAuthURL(state, verificationToken.CodeVerifier())
+
+	return &GetOAuthURLResponse{
+		URL:          url,
+		State:        state,
+		BrowserNonce: verificationToken.BrowserNonce(),
+		ExpiresAt:    verificationToken.ExpiresAt(),
+	}, nil
+}
+
+func (uc *GetOAuthURLUseCase) newState(userID string, provider entities.AccountProvider) (*entities.VerificationToken, error) {
+	if userID == "" {
+		return entities.NewOAuthStateToken(provider, uc.expirationState)
+	}
+
+	parsedUserID, err := valueobjects.ParseUserID(userID)
+	if err != nil {
+		return nil, errors.NewDomainError("invalid_user_id", "Invalid user ID")
+	}
+	return entities.NewOAuthLinkStateToken(parsedUserID, provider, uc.expirationState)
+}
+
+func linkOnlyProviderError(provider entities.AccountProvider) error {
+	return errors.NewDomainError("link_only_provider", fmt.Sprintf("%s can only be linked to an existing account", provider))
+}
+
+// parseOAuthProvider maps the provider path segment to an account provider;
+// the registry rejects names that are not configured
+func parseOAuthProvider(name string) entities.AccountProvider {
+	return entities.AccountProvider(strings.ToLower(strings.TrimSpace(name)))
+}
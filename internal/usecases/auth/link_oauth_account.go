@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+type LinkOAuthAccountRequest struct {
+	UserID   string
+	Provider string
+	Code     string
+	State    string // Must come from a link flow started by the same user
+}
+
+type LinkOAuthAccountResponse struct {
+	Success bool
+	Message string
+}
+
+// LinkOAuthAccountUseCase attaches a provider account to an existing user
+type LinkOAuthAccountUseCase struct {
+	userRepo         repositories.UserRepository
+	accountRepo      repositories.AccountRepository
+	verificationRepo repositories.VerificationRepository
+	registry         *providers.OAuthProviderRegistry
+}
+
+func NewLinkOAuthAccountUseCase(
+	userRepo repositories.UserRepository,
+	accountRepo repositories.AccountRepository,
+	verificationRepo repositories.VerificationRepository,
+	registry *providers.OAuthProviderRegistry,
+) *LinkOAuthAccountUseCase {
+	return &LinkOAuthAccountUseCase{
+		userRepo:         userRepo,
+		accountRepo:      accountRepo,
+		verificationRepo: verificationRepo,
+		registry:         registry,
+	}
+}
+
+// Execute completes a link flow for clients that receive the provider
+// callback themselves and forward the code with the user's access token
+func (uc *LinkOAuthAccountUseCase) Execute(ctx context.Context, req LinkOAuthAccountRequest) (*LinkOAuthAccountResponse, error) {
+	userID, err := valueobjects.ParseUserID(req.UserID)
+	if err != nil {
+		return nil, errors.NewDomainError("invalid_user_id", "Invalid user ID")
+	}
+
+	providerName := parseOAuthProvider(req.Provider)
+	provider, err := uc.registry.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := findOAuthState(ctx, uc.verificationRepo, req.State, providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	// A state issued to another user, or for a login, must not link anything:
+	// that would let a victim's code be attached to the attacker's account.
+	// The access token binds the flow here, in place of the browser cookie
+	if state.Intent() != entities.OAuthLinkIntent || !state.UserID().Equals(userID) {
+		return nil, errors.NewAuthenticationError("invalid_state", "OAuth state was not issued to link an account for this user")
+	}
+
+	if err := consumeOAuthState(ctx, uc.verificationRepo, state); err != nil {
+		return nil, err
+	}
+
+	return uc.link(ctx, userID, providerName, provider, req.Code, state.CodeVerifier())
+}
+
+// link exchanges the code and stores the provider account for userID. It is
+// shared with the OAuth callback, which dispatches here for link states
+func (uc *LinkOAuthAccountUseCase) link(
+	ctx context.Context,
+	userID valueobjects.UserID,
+	providerName entities.AccountProvider,
+	provider providers.OAuthProvider,
+	code string,
+	codeVerifier string,
+) (*LinkOAuthAccountResponse, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, errors.NewDomainError("user_not_found", "User not found")
+	}
+
+	providerTokens, identity, err := fetchOAuthIdentity(ctx, provider, providerName, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	// A provider identity signs in a single user
+	owner, err := uc.accountRepo.FindByProviderSubject(ctx, providerName, identity.SubjectID)
+	if err == nil && !owner.UserID().Equals(user.ID()) {
+		return nil, errors.NewDomainError("account_linked_to_other_user", fmt.Sprintf("This %s account is already linked to another user", providerName))
+	}
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); !ok {
+			return nil, err
+		}
+	}
+
+	account, err := uc.accountRepo.FindByUserIDAndProvider(ctx, user.ID(), providerName)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); !ok {
+			return nil, err
+		}
+
+		account, err = entities.NewOAuthAccount(user.ID(), providerName, identity.SubjectID)
+		if err != nil {
+			return nil, err
+		}
+	} else if err := account.AttachSubject(identity.SubjectID); err != nil {
+		// Linking the same identity again only renews its credentials, which
+		// is how an account flagged by a revoked refresh token recovers
+		return nil, errors.NewDomainError("account_already_linked", fmt.Sprintf("A different %s account is already linked to this user", providerName))
+	}
+
+	if err := account.UpdateCredentials(toProviderCredentials(providerTokens)); err != nil {
+		return nil, err
+	}
+
+	if err := uc.accountRepo.Save(ctx, account); err != nil {
+		return nil, err
+	}
+
+	return &LinkOAuthAccountResponse{
+		Success: true,
+		Message: fmt.Sprintf("%s account linked successfully", providerName),
+	}, nil
+}
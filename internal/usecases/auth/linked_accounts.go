@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+type ListLinkedAccountsRequest struct {
+	UserID string
+}
+
+type LinkedAccountResponse struct {
+	Provider string
+	LinkedAt time.Time
+	Scopes   []string
+	// TokenHealth is empty for the password login
+	TokenHealth string
+}
+
+type ListLinkedAccountsResponse struct {
+	Accounts []LinkedAccountResponse
+}
+
+// ListLinkedAccountsUseCase lists the login methods and services connected to
+// a user
+type ListLinkedAccountsUseCase struct {
+	accountRepo repositories.AccountRepository
+	clock       providers.Clock
+}
+
+func NewListLinkedAccountsUseCase(accountRepo repositories.AccountRepository, clock providers.Clock) *ListLinkedAccountsUseCase {
+	return &ListLinkedAccountsUseCase{
+		accountRepo: accountRepo,
+		clock:       clock,
+	}
+}
+
+func (uc *ListLinkedAccountsUseCase) Execute(ctx context.Context, req ListLinkedAccountsRequest) (*ListLinkedAccountsResponse, error) {
+	userID, err := valueobjects.ParseUserID(req.UserID)
+	if err != nil {
+		return nil, errors.NewDomainError("invalid_user_id", "Invalid user ID")
+	}
+
+	accounts, err := uc.accountRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := uc.clock.Now()
+	linked := make([]LinkedAccountResponse, 0, len(accounts))
+	for _, account := range accounts {
+		linked = append(linked, LinkedAccountResponse{
+			Provider:    string(account.Provider()),
+			LinkedAt:    account.CreatedAt(),
+			Scopes:      strings.Fields(account.Credentials().Scope()),
+			TokenHealth: string(account.TokenHealth(now)),
+		})
+	}
+
+	return &ListLinkedAccountsResponse{
+		Accounts: linked,
+	}, nil
+}
+
+type UnlinkAccountRequest struct {
+	UserID   string
+	Provider string
+}
+
+type UnlinkAccountResponse struct {
+	Success bool
+	Message string
+	// RevokedUpstream is false when the provider has no revocation endpoint or
+	// the revocation failed; the account is removed either way
+	RevokedUpstream bool
+}
+
+// UnlinkAccountUseCase disconnects a provider account from a user
+type UnlinkAccountUseCase struct {
+	accountRepo repositories.AccountRepository
+	registry    *providers.OAuthProviderRegistry
+}
+
+func NewUnlinkAccountUseCase(accountRepo repositories.AccountRepository, registry *providers.OAuthProviderRegistry) *UnlinkAccountUseCase {
+	return &UnlinkAccountUseCase{
+		accountRepo: accountRepo,
+		registry:    registry,
+	}
+}
+
+func (uc *UnlinkAccountUseCase) Execute(ctx context.Context, req UnlinkAccountRequest) (*UnlinkAccountResponse, error) {
+	userID, err := valueobjects.ParseUserID(req.UserID)
+	if err != nil {
+		return nil, errors.NewDomainError("invalid_user_id", "Invalid user ID")
+	}
+
+	providerName := parseOAuthProvider(req.Provider)
+	if providerName == entities.UserpassProvider {
+		return nil, errors.NewDomainError("invalid_provider", "The password login cannot be unlinked")
+	}
+
+	accounts, err := uc.accountRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var account *entities.Account
+	signInMethods := 0
+	for _, candidate := range accounts {
+		if candidate.Provider() == providerName {
+			account = candidate
+		} else if candidate.Provider().CanSignIn() {
+			signInMethods++
+		}
+	}
+
+	if account == nil {
+		return nil, errors.NewNotFoundError("account", fmt.Sprintf("No %s account is linked", providerName))
+	}
+
+	// The user must keep a way to sign in
+	if account.Provider().CanSignIn() && signInMethods == 0 {
+		return nil, errors.NewDomainError("last_login_method", "Cannot unlink the only way to sign in. Set a password or link another account first")
+	}
+
+	revoked := uc.revoke(ctx, account)
+
+	if err := uc.accountRepo.Delete(ctx, account.ID()); err != nil {
+		return nil, err
+	}
+
+	return &UnlinkAccountResponse{
+		Success:         true,
+		Message:         fmt.Sprintf("%s account unlinked successfully", providerName),
+		RevokedUpstream: revoked,
+	}, nil
+}
+
+// revoke asks the provider to invalidate the stored tokens. It is best effort:
+// a provider outage must not keep the user from unlinking
+func (uc *UnlinkAccountUseCase) revoke(ctx context.Context, account *entities.Account) bool {
+	credentials := account.Credentials()
+	if credentials.IsEmpty() {
+		return false
+	}
+
+	provider, err := uc.registry.Get(account.Provider())
+	if err != nil {
+		return false
+	}
+
+	revoker, ok := provider.(providers.OAuthTokenRevoker)
+	if !ok {
+		return false
+	}
+
+	err = revoker.RevokeToken(ctx, &providers.OAuthTokens{
+		AccessToken:  credentials.AccessToken(),
+		RefreshToken: credentials.RefreshToken(),
+	})
+	return err == nil
+}
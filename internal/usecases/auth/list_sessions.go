@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+type ListSessionsRequest struct {
+	UserID string
+	// CurrentAccessTokenID is the jti of the access token making the request,
+	// used to flag the caller's own session
+	CurrentAccessTokenID string
+}
+
+type SessionResponse struct {
+	ID          string
+	DeviceLabel string
+	UserAgent   string
+	IPAddress   string
+	CreatedAt   time.Time
+	LastUsedAt  time.Time
+	ExpiresAt   time.Time
+	Current     bool
+}
+
+type ListSessionsResponse struct {
+	Sessions []SessionResponse
+}
+
+type ListSessionsUseCase struct {
+	tokenRepo repositories.TokenRepository
+}
+
+func NewListSessionsUseCase(tokenRepo repositories.TokenRepository) *ListSessionsUseCase {
+	return &ListSessionsUseCase{
+		tokenRepo: tokenRepo,
+	}
+}
+
+func (uc *ListSessionsUseCase) Execute(ctx context.Context, req ListSessionsRequest) (*ListSessionsResponse, error) {
+	userID, err := valueobjects.ParseUserID(req.UserID)
+	if err != nil {
+		return nil, errors.NewDomainError("invalid_user_id", "Invalid user ID")
+	}
+
+	refreshTokens, err := uc.tokenRepo.FindUserRefreshTokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]SessionResponse, 0, len(refreshTokens))
+	for _, refreshToken := range refreshTokens {
+		if !refreshToken.IsValid() {
+			continue
+		}
+
+		sessions = append(sessions, SessionResponse{
+			ID:          refreshToken.SessionID().String(),
+			DeviceLabel: refreshToken.Device().Label(),
+			UserAgent:   refreshToken.Device().UserAgent(),
+			IPAddress:   refreshToken.Device().IPAddress(),
+			CreatedAt:   refreshToken.CreatedAt(),
+			LastUsedAt:  refreshToken.LastUsedAt(),
+			ExpiresAt:   refreshToken.ExpiresAt(),
+			Current:     req.CurrentAccessTokenID != "" && refreshToken.AccessTokenID() == req.CurrentAccessTokenID,
+		})
+	}
+
+	return &ListSessionsResponse{
+		Sessions: sessions,
+	}, nil
+}
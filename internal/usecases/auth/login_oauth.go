@@ -0,0 +1,320 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+type LoginOAuthCallbackRequest struct {
+	Provider string
+	Code     string
+	State    string // The state parameter is used to look up the verification token
+	// FirstName and LastName are profile hints sent in the callback itself, used
+	// when the provider does not include the name in the identity (Apple sends
+	// it only the first time the user authorizes the app)
+	FirstName string
+	LastName  string
+	// BrowserNonce is the nonce cookie set when the flow was started
+	BrowserNonce string
+}
+
+type LoginOAuthCallbackResponse struct {
+	UserID    string
+	IsNewUser bool
+	// FrontendVerificationToken is the one-time code the frontend exchanges
+	// for the session tokens
+	FrontendVerificationToken string
+	// Link is set instead of the code when the flow was started by a
+	// logged-in user to link the provider account
+	Link *LinkOAuthAccountResponse
+	// LinkRequired is set instead of the code when the identity's email
+	// belongs to an existing user but the provider does not assert it is
+	// verified: the user has to sign in and link the provider themselves
+	LinkRequired bool
+}
+
+// LoginOAuthUseCase completes the authorization code flow of any registered
+// provider, creating the user on first login. Flows started to link an
+// account are handed to the linker. The session itself is issued by
+// VerifyTokenUseCase in exchange for the returned one-time code
+type LoginOAuthUseCase struct {
+	userRepo         repositories.UserRepository
+	accountRepo      repositories.AccountRepository
+	verificationRepo repositories.VerificationRepository
+	registry         *providers.OAuthProviderRegistry
+	linker           *LinkOAuthAccountUseCase
+	expirationState  time.Duration
+	policy           entities.AuthenticationPolicy
+}
+
+func NewLoginOAuthUseCase(
+	userRepo repositories.UserRepository,
+	accountRepo repositories.AccountRepository,
+	verificationRepo repositories.VerificationRepository,
+	registry *providers.OAuthProviderRegistry,
+	linker *LinkOAuthAccountUseCase,
+	expirationState time.Duration,
+	policy entities.AuthenticationPolicy,
+) *LoginOAuthUseCase {
+	return &LoginOAuthUseCase{
+		userRepo:         userRepo,
+		accountRepo:      accountRepo,
+		verificationRepo: verificationRepo,
+		registry:         registry,
+		linker:           linker,
+		expirationState:  expirationState,
+		policy:           policy,
+	}
+}
+
+func (uc *LoginOAuthUseCase) Execute(ctx context.Context, req LoginOAuthCallbackRequest) (*LoginOAuthCallbackResponse, error) {
+	providerName := parseOAuthProvider(req.Provider)
+	provider, err := uc.registry.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := findOAuthState(ctx, uc.verificationRepo, req.State, providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	// The callback must reach the browser that started the flow; otherwise a
+	// victim could be made to complete an attacker's login or link. States
+	// bound to a client without cookies are checked when the one-time code
+	// is redeemed instead
+	if state.ClientChallenge() == "" && !state.IssuedToBrowser(req.BrowserNonce) {
+		return nil, errors.NewAuthenticationError("invalid_state", "OAuth state was not issued to this browser")
+	}
+
+	if err := consumeOAuthState(ctx, uc.verificationRepo, state); err != nil {
+		return nil, err
+	}
+
+	if state.Intent() == entities.OAuthLinkIntent {
+		userID := *state.UserID()
+		link, err := uc.linker.link(ctx, userID, providerName, provider, req.Code, state.CodeVerifier())
+		if err != nil {
+			return nil, err
+		}
+
+		return &LoginOAuthCallbackResponse{
+			UserID: userID.String(),
+			Link:   link,
+		}, nil
+	}
+
+	if !providerName.CanSignIn() {
+		return nil, linkOnlyProviderError(providerName)
+	}
+
+	providerTokens, identity, err := fetchOAuthIdentity(ctx, provider, providerName, req.Code, state.CodeVerifier())
+	if err != nil {
+		return nil, err
+	}
+
+	user, account, isNewUser, err := uc.resolveAccount(ctx, providerName, identity, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if account == nil {
+		return &LoginOAuthCallbackResponse{LinkRequired: true}, nil
+	}
+
+	// Keep the provider tokens so its API can be called on the user's behalf
+	if err := account.UpdateCredentials(toProviderCredentials(providerTokens)); err != nil {
+		return nil, err
+	}
+
+	if err := uc.accountRepo.Save(ctx, account); err != nil {
+		return nil, err
+	}
+
+	if err := user.CanAuthenticate(uc.policy); err != nil {
+		return nil, err
+	}
+
+	frontendToken, err := entities.NewFrontendVerificationToken(user.ID(), uc.expirationState)
+	if err != nil {
+		return nil, err
+	}
+
+	if challenge := state.ClientChallenge(); challenge != "" {
+		if err := frontendToken.BindToClient(challenge); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.verificationRepo.Save(ctx, frontendToken); err != nil {
+		return nil, err
+	}
+
+	return &LoginOAuthCallbackResponse{
+		UserID:                    user.ID().String(),
+		IsNewUser:                 isNewUser,
+		FrontendVerificationToken: frontendToken.Token(),
+	}, nil
+}
+
+// resolveAccount finds the user the provider identity signs in. Identities are
+// matched by subject first; the email is only trusted to merge with an
+// existing user when the provider asserts it is verified, otherwise anyone
+// able to set an arbitrary email at the provider could take over the user.
+// A nil account with no error means the user must link the provider from
+// an existing session instead
+func (uc *LoginOAuthUseCase) resolveAccount(
+	ctx context.Context,
+	providerName entities.AccountProvider,
+	identity *providers.OAuthIdentity,
+	req LoginOAuthCallbackRequest,
+) (*entities.User, *entities.Account, bool, error) {
+	account, err := uc.accountRepo.FindByProviderSubject(ctx, providerName, identity.SubjectID)
+	if err == nil {
+		user, err := uc.userRepo.FindByID(ctx, account.UserID())
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return user, account, false, nil
+	}
+	if _, ok := err.(*errors.NotFoundError); !ok {
+		return nil, nil, false, err
+	}
+
+	if identity.Email == "" {
+		return nil, nil, false, errors.NewAuthenticationError("oauth_no_email", fmt.Sprintf("The %s account does not have an email address", providerName))
+	}
+
+	email, err := valueobjects.NewEmail(identity.Email)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	user, err := uc.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); !ok {
+			return nil, nil, false, err
+		}
+
+		user, err = uc.createUser(ctx, identity, req)
+		if err != nil {
+			return nil, nil, false, err
+		}
+
+		account, err = entities.NewOAuthAccount(user.ID(), providerName, identity.SubjectID)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return user, account, true, nil
+	}
+
+	if !identity.EmailVerified {
+		return user, nil, false, nil
+	}
+
+	// Accounts created before subjects were stored get theirs on this login
+	account, err = uc.accountRepo.FindByUserIDAndProvider(ctx, user.ID(), providerName)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); !ok {
+			return nil, nil, false, err
+		}
+
+		account, err = entities.NewOAuthAccount(user.ID(), providerName, identity.SubjectID)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return user, account, false, nil
+	}
+
+	if err := account.AttachSubject(identity.SubjectID); err != nil {
+		return nil, nil, false, err
+	}
+	return user, account, false, nil
+}
+
+func (uc *LoginOAuthUseCase) createUser(ctx context.Context, identity *providers.OAuthIdentity, req LoginOAuthCallbackRequest) (*entities.User, error) {
+	firstName, lastName := identity.FirstName, identity.LastName
+	if firstName == "" && lastName == "" {
+		firstName, lastName = req.FirstName, req.LastName
+	}
+
+	user, err := entities.NewUser(identity.Email, firstName, lastName)
+	if err != nil {
+		return nil, err
+	}
+
+	if identity.EmailVerified {
+		user.VerifyEmail()
+	}
+
+	if err := uc.userRepo.Save(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// findOAuthState checks the state against the verification tokens table and
+// the provider whose callback carries it
+func findOAuthState(
+	ctx context.Context,
+	verificationRepo repositories.VerificationRepository,
+	state string,
+	provider entities.AccountProvider,
+) (*entities.VerificationToken, error) {
+	verificationToken, err := verificationRepo.FindByToken(ctx, state)
+	if err != nil {
+		return nil, errors.NewAuthenticationError("invalid_state", "OAuth state parameter not found or invalid")
+	}
+
+	if err := verificationToken.ValidateForOAuth(provider); err != nil {
+		return nil, err
+	}
+
+	return verificationToken, nil
+}
+
+// consumeOAuthState marks the state as used, so a callback cannot be replayed
+func consumeOAuthState(ctx context.Context, verificationRepo repositories.VerificationRepository, state *entities.VerificationToken) error {
+	if err := state.MarkAsUsed(); err != nil {
+		return err
+	}
+
+	return verificationRepo.Update(ctx, state)
+}
+
+// fetchOAuthIdentity exchanges the authorization code, with the PKCE verifier
+// stored with the state, and describes the user
+func fetchOAuthIdentity(
+	ctx context.Context,
+	provider providers.OAuthProvider,
+	providerName entities.AccountProvider,
+	code string,
+	codeVerifier string,
+) (*providers.OAuthTokens, *providers.OAuthIdentity, error) {
+	tokens, err := provider.ExchangeCode(ctx, code, codeVerifier)
+	if err != nil {
+		return nil, nil, errors.NewAuthenticationErrorWithCause("oauth_exchange_failed", fmt.Sprintf("Failed to exchange %s code", providerName), err)
+	}
+
+	identity, err := provider.GetIdentity(ctx, tokens)
+	if err != nil {
+		return nil, nil, errors.NewAuthenticationErrorWithCause("oauth_userinfo_failed", fmt.Sprintf("Failed to get %s user info", providerName), err)
+	}
+
+	if identity.SubjectID == "" {
+		return nil, nil, errors.NewAuthenticationError("oauth_userinfo_failed", fmt.Sprintf("The %s user info has no user ID", providerName))
+	}
+
+	return tokens, identity, nil
+}
+
+func toProviderCredentials(tokens *providers.OAuthTokens) entities.ProviderCredentials {
+	return entities.NewProviderCredentials(tokens.AccessToken, tokens.RefreshToken, tokens.ExpiresAt, tokens.Scope)
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+)
+
+// LoginThrottleConfig sets the lockout policies for failed password logins.
+// Email limits slow down guessing against one account; IP limits slow down
+// one client spraying many accounts
+type LoginThrottleConfig struct {
+	Email entities.LockoutPolicy
+	IP    entities.LockoutPolicy
+}
+
+// LoginThrottle tracks failed password logins per email and per IP. Wrong
+// two-factor codes count against the email too, so the second step cannot be
+// guessed faster than the password
+type LoginThrottle struct {
+	attemptRepo repositories.LoginAttemptRepository
+	clock       providers.Clock
+	config      LoginThrottleConfig
+}
+
+func NewLoginThrottle(
+	attemptRepo repositories.LoginAttemptRepository,
+	clock providers.Clock,
+	config LoginThrottleConfig,
+) *LoginThrottle {
+	return &LoginThrottle{
+		attemptRepo: attemptRepo,
+		clock:       clock,
+		config:      config,
+	}
+}
+
+// check fails with account_locked while the email or the IP has to wait
+func (t *LoginThrottle) check(ctx context.Context, email, ipAddress string) error {
+	now := t.clock.Now()
+
+	var retryAfter time.Duration
+	for _, key := range t.keys(email, ipAddress) {
+		attempts, err := t.attemptRepo.Find(ctx, key)
+		if err != nil {
+			return err
+		}
+		if wait := attempts.RetryAfter(now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter > 0 {
+		return errors.NewAccountLockedError(retryAfter)
+	}
+	return nil
+}
+
+// registerFailure counts a failed login, unknown emails included, so the
+// response does not reveal whether an account exists
+func (t *LoginThrottle) registerFailure(ctx context.Context, email, ipAddress string) error {
+	now := t.clock.Now()
+
+	if _, err := t.attemptRepo.RegisterFailure(ctx, emailAttemptKey(email), now, t.config.Email); err != nil {
+		return err
+	}
+
+	if ipAddress != "" {
+		if _, err := t.attemptRepo.RegisterFailure(ctx, ipAttemptKey(ipAddress), now, t.config.IP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerMFAFailure counts a wrong two-factor code against the email. The IP
+// counter is left alone: the client already proved it knows the password
+func (t *LoginThrottle) registerMFAFailure(ctx context.Context, email string) error {
+	_, err := t.attemptRepo.RegisterFailure(ctx, emailAttemptKey(email), t.clock.Now(), t.config.Email)
+	return err
+}
+
+// reset clears the email counter once the login is complete. The IP counter is
+// kept: an attacker owning one account must not be able to reset it
+func (t *LoginThrottle) reset(ctx context.Context, email string) error {
+	return t.attemptRepo.Reset(ctx, emailAttemptKey(email))
+}
+
+func (t *LoginThrottle) keys(email, ipAddress string) []string {
+	keys := []string{emailAttemptKey(email)}
+	if ipAddress != "" {
+		keys = append(keys, ipAttemptKey(ipAddress))
+	}
+	return keys
+}
+
+func emailAttemptKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptKey(ipAddress string) string {
+	return "ip:" + ipAddress
+}
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	repoAdapters "github.com/zandomed/sync-playlist-api/internal/infra/repositories"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+var testThrottleConfig = LoginThrottleConfig{
+	Email: entities.LockoutPolicy{
+		FreeAttempts:     3,
+		BaseDelay:        time.Second,
+		MaxDelay:         time.Minute,
+		LockoutThreshold: 5,
+		LockoutDuration:  15 * time.Minute,
+		ResetAfter:       time.Hour,
+	},
+	IP: entities.LockoutPolicy{
+		FreeAttempts:     10,
+		BaseDelay:        time.Second,
+		MaxDelay:         time.Minute,
+		LockoutThreshold: 20,
+		LockoutDuration:  time.Hour,
+		ResetAfter:       time.Hour,
+	},
+}
+
+func TestLoginThrottleLockoutExpiry(t *testing.T) {
+	const email, ip = "user@example.com", "203.0.113.7"
+
+	tests := []struct {
+		name string
+		// failures are registered one second apart, password ones first
+		passwordFailures int
+		mfaFailures      int
+		// wait is how long after the last failure the check runs
+		wait      time.Duration
+		wantRetry time.Duration
+	}{
+		{"free attempts", 3, 0, 0, 0},
+		{"first backoff", 4, 0, 0, time.Second},
+		{"backoff over", 4, 0, time.Second, 0},
+		{"locked at threshold", 5, 0, 0, 15 * time.Minute},
+		{"still locked", 5, 0, 14 * time.Minute, time.Minute},
+		{"lock expired", 5, 0, 15 * time.Minute, 0},
+		{"wrong codes count with wrong passwords", 3, 2, 0, 15 * time.Minute},
+		{"wrong codes alone lock the email", 0, 5, time.Minute, 14 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			clock := &fakeClock{now: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)}
+			throttle := NewLoginThrottle(repoAdapters.NewInMemoryLoginAttemptRepository(), clock, testThrottleConfig)
+
+			for i := 0; i < tt.passwordFailures; i++ {
+				if err := throttle.registerFailure(ctx, email, ip); err != nil {
+					t.Fatalf("registerFailure: %v", err)
+				}
+				clock.Advance(time.Second)
+			}
+			for i := 0; i < tt.mfaFailures; i++ {
+				if err := throttle.registerMFAFailure(ctx, email); err != nil {
+					t.Fatalf("registerMFAFailure: %v", err)
+				}
+				clock.Advance(time.Second)
+			}
+			clock.Advance(tt.wait - time.Second)
+
+			err := throttle.check(ctx, email, ip)
+			if tt.wantRetry == 0 {
+				if err != nil {
+					t.Fatalf("check: unexpected error %v", err)
+				}
+				return
+			}
+
+			authErr, ok := err.(*errors.AuthenticationError)
+			if !ok || authErr.Code() != "account_locked" {
+				t.Fatalf("check: error = %v, want account_locked", err)
+			}
+			if authErr.RetryAfter() != tt.wantRetry {
+				t.Errorf("RetryAfter = %s, want %s", authErr.RetryAfter(), tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestLoginThrottleResetKeepsIPCounter(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)}
+	config := testThrottleConfig
+	config.IP.LockoutThreshold = 2
+	throttle := NewLoginThrottle(repoAdapters.NewInMemoryLoginAttemptRepository(), clock, config)
+
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		if err := throttle.registerFailure(ctx, email, "203.0.113.7"); err != nil {
+			t.Fatalf("registerFailure: %v", err)
+		}
+	}
+
+	if err := throttle.reset(ctx, "a@example.com"); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+
+	tests := []struct {
+		email, ip  string
+		wantLocked bool
+	}{
+		{"a@example.com", "", false},
+		{"a@example.com", "203.0.113.7", true},
+		{"c@example.com", "198.51.100.1", false},
+	}
+
+	for _, tt := range tests {
+		err := throttle.check(ctx, tt.email, tt.ip)
+		if locked := err != nil; locked != tt.wantLocked {
+			t.Errorf("check(%s, %q) locked = %v, want %v (err %v)", tt.email, tt.ip, locked, tt.wantLocked, err)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package auth
 
 import (
 	"context"
-	"time"
 
 	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
 	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
@@ -11,27 +10,44 @@ import (
 )
 
 type LoginUserRequest struct {
-	Email    string
-	Password string
+	Email     string
+	Password  string
+	UserAgent string
+	IPAddress string
 }
 
 type LoginUserResponse struct {
 	AccessToken  string
 	RefreshToken string
 	UserID       string
+	// MFARequired is set instead of issuing tokens when the user has two-factor
+	// authentication enabled; MFAToken must then be redeemed with a code
+	MFARequired bool
+	MFAToken    string
 }
 
 type TokenGenerator interface {
-	GenerateAccessToken(userID string, email string) (string, error)
+	// GenerateAccessToken signs an access token; tokenID becomes its jti so it
+	// can be revoked individually. The role and its scopes are embedded so
+	// routes can be authorized without a database lookup
+	GenerateAccessToken(tokenID string, userID string, email string, role string, scopes []string) (string, error)
 	GenerateRefreshToken(userID string) (string, error)
+	// ValidateRefreshToken checks the signature and expiry of a refresh token
+	// and returns the user ID it was issued for
+	ValidateRefreshToken(token string) (string, error)
+	GetAccessTokenExpiration() int64
 	GetRefreshTokenExpiration() int64
 }
 
 type LoginUserUseCase struct {
-	userRepo    repositories.UserRepository
-	accountRepo repositories.AccountRepository
-	tokenRepo   repositories.TokenRepository
-	tokenGen    TokenGenerator
+	userRepo         repositories.UserRepository
+	accountRepo      repositories.AccountRepository
+	twoFactorRepo    repositories.TwoFactorRepository
+	verificationRepo repositories.VerificationRepository
+	issuer           *tokenIssuer
+	throttle         *LoginThrottle
+	policy           entities.AuthenticationPolicy
+	mfaConfig        MFAConfig
 }
 
 func NewLoginUserUseCase(
@@ -39,12 +55,21 @@ func NewLoginUserUseCase(
 	accountRepo repositories.AccountRepository,
 	tokenRepo repositories.TokenRepository,
 	tokenGen TokenGenerator,
+	twoFactorRepo repositories.TwoFactorRepository,
+	verificationRepo repositories.VerificationRepository,
+	throttle *LoginThrottle,
+	policy entities.AuthenticationPolicy,
+	mfaConfig MFAConfig,
 ) *LoginUserUseCase {
 	return &LoginUserUseCase{
-		userRepo:    userRepo,
-		accountRepo: accountRepo,
-		tokenRepo:   tokenRepo,
-		tokenGen:    tokenGen,
+		userRepo:         userRepo,
+		accountRepo:      accountRepo,
+		twoFactorRepo:    twoFactorRepo,
+		verificationRepo: verificationRepo,
+		issuer:           newTokenIssuer(tokenRepo, tokenGen),
+		throttle:         throttle,
+		policy:           policy,
+		mfaConfig:        mfaConfig,
 	}
 }
 
@@ -59,13 +84,18 @@ func (uc *LoginUserUseCase) Execute(ctx context.Context, req LoginUserRequest) (
 		return nil, err
 	}
 
+	// Locked keys are rejected before spending a bcrypt comparison
+	if err := uc.throttle.check(ctx, email.String(), req.IPAddress); err != nil {
+		return nil, err
+	}
+
 	account, err := uc.accountRepo.FindUserpassAccountByEmail(ctx, email)
 	if err != nil {
-		return nil, errors.NewAuthenticationError("invalid_credentials", "Invalid email or password")
+		return nil, uc.invalidCredentials(ctx, email, req.IPAddress)
 	}
 
 	if !account.Password().Verify(plainPassword) {
-		return nil, errors.NewAuthenticationError("invalid_credentials", "Invalid email or password")
+		return nil, uc.invalidCredentials(ctx, email, req.IPAddress)
 	}
 
 	user, err := uc.userRepo.FindByID(ctx, account.UserID())
@@ -73,40 +103,64 @@ func (uc *LoginUserUseCase) Execute(ctx context.Context, req LoginUserRequest) (
 		return nil, errors.NewAuthenticationError("user_not_found", "User not found")
 	}
 
-	if err := user.CanAuthenticate(); err != nil {
+	if err := user.CanAuthenticate(uc.policy); err != nil {
 		return nil, err
 	}
 
-	accessToken, err := uc.tokenGen.GenerateAccessToken(user.ID().String(), user.Email().String())
+	mfaRequired, err := uc.isMFAEnabled(ctx, user.ID())
 	if err != nil {
 		return nil, err
 	}
 
-	refreshTokenStr, err := uc.tokenGen.GenerateRefreshToken(user.ID().String())
-	if err != nil {
-		return nil, err
+	// With two-factor enabled the failures are kept until the code is
+	// accepted, so wrong codes keep adding to the same lockout
+	if mfaRequired {
+		challenge, err := entities.NewMFAChallengeToken(user.ID(), uc.mfaConfig.ChallengeExpiration)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := uc.verificationRepo.Save(ctx, challenge); err != nil {
+			return nil, err
+		}
+
+		return &LoginUserResponse{
+			UserID:      user.ID().String(),
+			MFARequired: true,
+			MFAToken:    challenge.Token(),
+		}, nil
 	}
 
-	refreshToken, err := entities.NewRefreshToken(
-		user.ID(),
-		refreshTokenStr,
-		uc.getRefreshTokenExpirationTime(),
-	)
-	if err != nil {
+	if err := uc.throttle.reset(ctx, email.String()); err != nil {
 		return nil, err
 	}
 
-	if err := uc.tokenRepo.SaveRefreshToken(ctx, refreshToken); err != nil {
+	tokens, err := uc.issuer.issue(ctx, user, valueobjects.NewDeviceInfo(req.UserAgent, req.IPAddress))
+	if err != nil {
 		return nil, err
 	}
 
 	return &LoginUserResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshTokenStr,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 		UserID:       user.ID().String(),
 	}, nil
 }
 
-func (uc *LoginUserUseCase) getRefreshTokenExpirationTime() time.Time {
-	return time.Now().Add(time.Duration(uc.tokenGen.GetRefreshTokenExpiration()) * time.Second)
+func (uc *LoginUserUseCase) invalidCredentials(ctx context.Context, email valueobjects.Email, ipAddress string) error {
+	if err := uc.throttle.registerFailure(ctx, email.String(), ipAddress); err != nil {
+		return err
+	}
+	return errors.NewAuthenticationError("invalid_credentials", "Invalid email or password")
+}
+
+func (uc *LoginUserUseCase) isMFAEnabled(ctx context.Context, userID valueobjects.UserID) (bool, error) {
+	twoFactor, err := uc.twoFactorRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); ok {
+			return false, nil
+		}
+		return false, err
+	}
+	return twoFactor.IsEnabled(), nil
 }
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+type LogoutRequest struct {
+	UserID       string
+	RefreshToken string
+	// AccessTokenID and AccessTokenExpiresAt describe the access token used to
+	// authenticate the request, which is revoked as well
+	AccessTokenID        string
+	AccessTokenExpiresAt time.Time
+}
+
+type LogoutAllRequest struct {
+	UserID               string
+	AccessTokenID        string
+	AccessTokenExpiresAt time.Time
+}
+
+type LogoutResponse struct {
+	Success bool
+	Message string
+}
+
+type LogoutUseCase struct {
+	tokenRepo        repositories.TokenRepository
+	revokedTokenRepo repositories.RevokedTokenRepository
+	tokenGen         TokenGenerator
+}
+
+func NewLogoutUseCase(
+	tokenRepo repositories.TokenRepository,
+	revokedTokenRepo repositories.RevokedTokenRepository,
+	tokenGen TokenGenerator,
+) *LogoutUseCase {
+	return &LogoutUseCase{
+		tokenRepo:        tokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		tokenGen:         tokenGen,
+	}
+}
+
+func (uc *LogoutUseCase) Execute(ctx context.Context, req LogoutRequest) (*LogoutResponse, error) {
+	userID, err := valueobjects.ParseUserID(req.UserID)
+	if err != nil {
+		return nil, errors.NewDomainError("invalid_user_id", "Invalid user ID")
+	}
+
+	refreshToken, err := uc.tokenRepo.FindRefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); !ok {
+			return nil, err
+		}
+		// Already revoked or rotated: logging out is idempotent
+		refreshToken = nil
+	}
+
+	if refreshToken != nil {
+		if !refreshToken.UserID().Equals(userID) {
+			return nil, errors.NewAuthenticationError("invalid_refresh_token", "Refresh token does not belong to the authenticated user")
+		}
+
+		if err := uc.tokenRepo.DeleteRefreshToken(ctx, refreshToken.Token()); err != nil {
+			return nil, err
+		}
+
+		if refreshToken.AccessTokenID() != "" {
+			expiresAt := time.Now().Add(time.Duration(uc.tokenGen.GetAccessTokenExpiration()) * time.Second)
+			if err := uc.revokedTokenRepo.Revoke(ctx, refreshToken.AccessTokenID(), userID, expiresAt); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if req.AccessTokenID != "" {
+		if err := uc.revokedTokenRepo.Revoke(ctx, req.AccessTokenID, userID, req.AccessTokenExpiresAt); err != nil {
+			return nil, err
+		}
+	}
+
+	return &LogoutResponse{
+		Success: true,
+		Message: "Logged out successfully",
+	}, nil
+}
+
+type LogoutAllUseCase struct {
+	tokenRepo        repositories.TokenRepository
+	revokedTokenRepo repositories.RevokedTokenRepository
+	tokenGen         TokenGenerator
+}
+
+func NewLogoutAllUseCase(
+	tokenRepo repositories.TokenRepository,
+	revokedTokenRepo repositories.RevokedTokenRepository,
+	tokenGen TokenGenerator,
+) *LogoutAllUseCase {
+	return &LogoutAllUseCase{
+		tokenRepo:        tokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		tokenGen:         tokenGen,
+	}
+}
+
+func (uc *LogoutAllUseCase) Execute(ctx context.Context, req LogoutAllRequest) (*LogoutResponse, error) {
+	userID, err := valueobjects.ParseUserID(req.UserID)
+	if err != nil {
+		return nil, errors.NewDomainError("invalid_user_id", "Invalid user ID")
+	}
+
+	expiresAt := time.Now().Add(time.Duration(uc.tokenGen.GetAccessTokenExpiration()) * time.Second)
+	if err := revokeUserSessions(ctx, uc.tokenRepo, uc.revokedTokenRepo, userID, expiresAt); err != nil {
+		return nil, err
+	}
+
+	if req.AccessTokenID != "" {
+		if err := uc.revokedTokenRepo.Revoke(ctx, req.AccessTokenID, userID, req.AccessTokenExpiresAt); err != nil {
+			return nil, err
+		}
+	}
+
+	return &LogoutResponse{
+		Success: true,
+		Message: "Logged out from all sessions",
+	}, nil
+}
+
+// revokeUserSessions deletes every refresh token of the user and denylists the
+// access tokens issued alongside them, so they stop working immediately
+func revokeUserSessions(
+	ctx context.Context,
+	tokenRepo repositories.TokenRepository,
+	revokedTokenRepo repositories.RevokedTokenRepository,
+	userID valueobjects.UserID,
+	accessTokenExpiresAt time.Time,
+) error {
+	refreshTokens, err := tokenRepo.FindUserRefreshTokens(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, refreshToken := range refreshTokens {
+		if refreshToken.AccessTokenID() == "" {
+			continue
+		}
+		if err := revokedTokenRepo.Revoke(ctx, refreshToken.AccessTokenID(), userID, accessTokenExpiresAt); err != nil {
+			return err
+		}
+	}
+
+	return tokenRepo.DeleteUserRefreshTokens(ctx, userID)
+}
+
+// revokeSession deletes the session and denylists the access token issued
+// with its current refresh token
+func revokeSession(
+	ctx context.Context,
+	tokenRepo repositories.TokenRepository,
+	revokedTokenRepo repositories.RevokedTokenRepository,
+	session *entities.RefreshToken,
+	accessTokenExpiresAt time.Time,
+) error {
+	if err := tokenRepo.DeleteSession(ctx, session.SessionID()); err != nil {
+		return err
+	}
+
+	if session.AccessTokenID() == "" {
+		return nil
+	}
+	return revokedTokenRepo.Revoke(ctx, session.AccessTokenID(), session.UserID(), accessTokenExpiresAt)
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+)
+
+// MailThrottleConfig limits the emails anyone can ask the API to send. Email
+// limits protect a mailbox from being flooded; IP limits slow down one client
+// going through many addresses
+type MailThrottleConfig struct {
+	Email entities.LockoutPolicy
+	IP    entities.LockoutPolicy
+}
+
+// MailThrottle counts requests that send an email, such as password resets
+// and verification resends, in the login attempt store under keys of their own
+type MailThrottle struct {
+	attemptRepo repositories.LoginAttemptRepository
+	clock       providers.Clock
+	config      MailThrottleConfig
+}
+
+func NewMailThrottle(
+	attemptRepo repositories.LoginAttemptRepository,
+	clock providers.Clock,
+	config MailThrottleConfig,
+) *MailThrottle {
+	return &MailThrottle{
+		attemptRepo: attemptRepo,
+		clock:       clock,
+		config:      config,
+	}
+}
+
+// allow fails with rate_limited while the email or the IP has to wait, and
+// otherwise counts the request. Unknown emails are counted as well, so the
+// limit does not reveal whether an account exists
+func (t *MailThrottle) allow(ctx context.Context, email, ipAddress string) error {
+	now := t.clock.Now()
+
+	keys := []string{mailEmailKey(email)}
+	policies := []entities.LockoutPolicy{t.config.Email}
+	if ipAddress != "" {
+		keys = append(keys, mailIPKey(ipAddress))
+		policies = append(policies, t.config.IP)
+	}
+
+	var retryAfter time.Duration
+	for _, key := range keys {
+		attempts, err := t.attemptRepo.Find(ctx, key)
+		if err != nil {
+			return err
+		}
+		if wait := attempts.RetryAfter(now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter > 0 {
+		return errors.NewRateLimitedError(retryAfter)
+	}
+
+	for i, key := range keys {
+		if _, err := t.attemptRepo.RegisterFailure(ctx, key, now, policies[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mailEmailKey(email string) string {
+	return "mail:" + emailAttemptKey(email)
+}
+
+func mailIPKey(ipAddress string) string {
+	return "mail:" + ipAttemptKey(ipAddress)
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	repoAdapters "github.com/zandomed/sync-playlist-api/internal/infra/repositories"
+)
+
+var testMailThrottleConfig = MailThrottleConfig{
+	Email: entities.LockoutPolicy{
+		FreeAttempts: 1,
+		BaseDelay:    time.Minute,
+		MaxDelay:     time.Hour,
+		ResetAfter:   time.Hour,
+	},
+	IP: entities.LockoutPolicy{
+		FreeAttempts: 3,
+		BaseDelay:    30 * time.Second,
+		MaxDelay:     time.Hour,
+		ResetAfter:   time.Hour,
+	},
+}
+
+func TestMailThrottle(t *testing.T) {
+	type request struct {
+		email   string
+		ip      string
+		wait    time.Duration
+		wantErr string
+	}
+
+	tests := []struct {
+		name     string
+		requests []request
+	}{
+		{
+			name: "repeated requests for one email wait longer each time",
+			requests: []request{
+				{email: "user@example.com", ip: "203.0.113.7"},
+				{email: "user@example.com", ip: "203.0.113.7"},
+				{email: "user@example.com", ip: "203.0.113.7", wantErr: "rate_limited"},
+				{email: "user@example.com", ip: "203.0.113.7", wait: time.Minute},
+				{email: "user@example.com", ip: "203.0.113.7", wait: time.Minute, wantErr: "rate_limited"},
+				{email: "user@example.com", ip: "203.0.113.7", wait: time.Minute},
+			},
+		},
+		{
+			name: "requests from other addresses do not reach the limited mailbox",
+			requests: []request{
+				{email: "user@example.com", ip: "203.0.113.7"},
+				{email: "user@example.com", ip: "198.51.100.4"},
+				{email: "user@example.com", ip: "192.0.2.1", wantErr: "rate_limited"},
+			},
+		},
+		{
+			name: "one IP going through many emails",
+			requests: []request{
+				{email: "a@example.com", ip: "203.0.113.7"},
+				{email: "b@example.com", ip: "203.0.113.7"},
+				{email: "c@example.com", ip: "203.0.113.7"},
+				{email: "d@example.com", ip: "203.0.113.7"},
+				{email: "e@example.com", ip: "203.0.113.7", wantErr: "rate_limited"},
+				{email: "e@example.com", ip: "198.51.100.4"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)}
+			attempts := repoAdapters.NewInMemoryLoginAttemptRepository()
+			throttle := NewMailThrottle(attempts, clock, testMailThrottleConfig)
+			logins := NewLoginThrottle(attempts, clock, testThrottleConfig)
+
+			for i, r := range tt.requests {
+				clock.Advance(r.wait)
+
+				err := throttle.allow(context.Background(), r.email, r.ip)
+				if r.wantErr == "" && err != nil {
+					t.Fatalf("request %d: unexpected error: %v", i, err)
+				}
+				if r.wantErr != "" {
+					assertAuthErrorCode(t, err, r.wantErr)
+				}
+
+				// Email requests never count against logins
+				if err := logins.check(context.Background(), r.email, r.ip); err != nil {
+					t.Fatalf("request %d: login throttled: %v", i, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+// providerTokenRefreshMargin renews access tokens slightly before they expire
+// so a call started with the token does not fail halfway
+const providerTokenRefreshMargin = time.Minute
+
+// ProviderCredentialService hands out valid provider access tokens for a
+// user's linked accounts, refreshing them when they are about to expire
+type ProviderCredentialService struct {
+	accountRepo repositories.AccountRepository
+	registry    *providers.OAuthProviderRegistry
+	clock       providers.Clock
+
+	mu       sync.Mutex
+	inflight map[string]*credentialRefresh
+}
+
+// credentialRefresh is a refresh in progress; concurrent callers for the same
+// account wait on it instead of spending the refresh token twice
+type credentialRefresh struct {
+	done        chan struct{}
+	accessToken string
+	err         error
+}
+
+func NewProviderCredentialService(
+	accountRepo repositories.AccountRepository,
+	registry *providers.OAuthProviderRegistry,
+	clock providers.Clock,
+) *ProviderCredentialService {
+	return &ProviderCredentialService{
+		accountRepo: accountRepo,
+		registry:    registry,
+		clock:       clock,
+		inflight:    make(map[string]*credentialRefresh),
+	}
+}
+
+// GetAccessToken returns an access token for the user's account with provider.
+// When the provider revoked the refresh token the account is flagged and a
+// provider_reauth_required error is returned until the user links it again
+func (s *ProviderCredentialService) GetAccessToken(ctx context.Context, userID valueobjects.UserID, provider entities.AccountProvider) (string, error) {
+	account, err := s.accountRepo.FindByUserIDAndProvider(ctx, userID, provider)
+	if err != nil {
+		return "", err
+	}
+
+	if account.ReauthRequired() {
+		return "", reauthRequiredError()
+	}
+
+	credentials := account.Credentials()
+	if credentials.AccessToken() != "" && !credentials.AccessTokenExpiresWithin(providerTokenRefreshMargin, s.clock.Now()) {
+		return credentials.AccessToken(), nil
+	}
+
+	return s.refresh(ctx, userID, provider, account.ID().String())
+}
+
+// GetMusicUserToken returns the Music-User-Token stored on the user's
+// apple_music account. Apple does not let apps renew it: once it expires or
+// is missing the user has to authorize Apple Music again
+func (s *ProviderCredentialService) GetMusicUserToken(ctx context.Context, userID valueobjects.UserID) (string, error) {
+	account, err := s.findMusicUserTokenAccount(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if account.MusicUserToken() == "" {
+		return "", appleMusicAuthorizationRequiredError()
+	}
+
+	return account.MusicUserToken(), nil
+}
+
+// findMusicUserTokenAccount finds the account holding the Music-User-Token.
+// Tokens stored before apple_music existed live on the apple account; they
+// are encrypted for that account and cannot be moved
+func (s *ProviderCredentialService) findMusicUserTokenAccount(ctx context.Context, userID valueobjects.UserID) (*entities.Account, error) {
+	account, err := s.accountRepo.FindByUserIDAndProvider(ctx, userID, entities.AppleMusicProvider)
+	if _, ok := err.(*errors.NotFoundError); ok {
+		account, err = s.accountRepo.FindByUserIDAndProvider(ctx, userID, entities.AppleProvider)
+	}
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); ok {
+			return nil, appleMusicAuthorizationRequiredError()
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+// refresh runs a single refresh per account; callers that arrive while one
+// is running share its result
+func (s *ProviderCredentialService) refresh(ctx context.Context, userID valueobjects.UserID, provider entities.AccountProvider, key string) (string, error) {
+	s.mu.Lock()
+	if call, ok := s.inflight[key]; ok {
+		s.mu.Unlock()
+
+		select {
+		case <-call.done:
+			return call.accessToken, call.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	call := &credentialRefresh{done: make(chan struct{})}
+	s.inflight[key] = call
+	s.mu.Unlock()
+
+	// The refresh outlives a cancelled caller: once the provider rotates the
+	// refresh token, the new one must be stored or the account is lost
+	go func() {
+		call.accessToken, call.err = s.doRefresh(context.WithoutCancel(ctx), userID, provider)
+
+		s.mu.Lock()
+		delete(s.inflight, key)
+		s.mu.Unlock()
+		close(call.done)
+	}()
+
+	select {
+	case <-call.done:
+		return call.accessToken, call.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (s *ProviderCredentialService) doRefresh(ctx context.Context, userID valueobjects.UserID, provider entities.AccountProvider) (string, error) {
+	// Re-read the account: another instance may have refreshed it already
+	account, err := s.accountRepo.FindByUserIDAndProvider(ctx, userID, provider)
+	if err != nil {
+		return "", err
+	}
+
+	if account.ReauthRequired() {
+		return "", reauthRequiredError()
+	}
+
+	credentials := account.Credentials()
+	if credentials.AccessToken() != "" && !credentials.AccessTokenExpiresWithin(providerTokenRefreshMargin, s.clock.Now()) {
+		return credentials.AccessToken(), nil
+	}
+
+	oauthProvider, err := s.registry.Get(provider)
+	if err != nil {
+		return "", err
+	}
+
+	refresher, ok := oauthProvider.(providers.OAuthTokenRefresher)
+	if !ok || credentials.RefreshToken() == "" {
+		return "", s.requireReauth(ctx, account)
+	}
+
+	tokens, err := refresher.RefreshToken(ctx, credentials.RefreshToken())
+	if err != nil {
+		if stderrors.Is(err, providers.ErrRefreshTokenRevoked) {
+			return "", s.requireReauth(ctx, account)
+		}
+		return "", fmt.Errorf("failed to refresh %s token: %w", provider, err)
+	}
+
+	// Providers that do not rotate refresh tokens return an empty one, in
+	// which case UpdateCredentials keeps the stored token
+	if err := account.UpdateCredentials(toProviderCredentials(tokens)); err != nil {
+		return "", err
+	}
+
+	if err := s.accountRepo.Save(ctx, account); err != nil {
+		return "", err
+	}
+
+	return tokens.AccessToken, nil
+}
+
+// RequireReauth flags the user's account with provider after the provider
+// rejected its access token and there is nothing to refresh it with. It
+// returns the provider_reauth_required error for the caller to report.
+// For apple_music the rejected Music-User-Token is forgotten instead
+func (s *ProviderCredentialService) RequireReauth(ctx context.Context, userID valueobjects.UserID, provider entities.AccountProvider) error {
+	if provider == entities.AppleMusicProvider {
+		return s.forgetMusicUserToken(ctx, userID)
+	}
+
+	account, err := s.accountRepo.FindByUserIDAndProvider(ctx, userID, provider)
+	if err != nil {
+		return err
+	}
+	return s.requireReauth(ctx, account)
+}
+
+// forgetMusicUserToken leaves the OAuth credentials of a legacy apple account
+// untouched: only the Music-User-Token was rejected
+func (s *ProviderCredentialService) forgetMusicUserToken(ctx context.Context, userID valueobjects.UserID) error {
+	account, err := s.findMusicUserTokenAccount(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	account.ForgetMusicUserToken()
+	if err := s.accountRepo.Save(ctx, account); err != nil {
+		return err
+	}
+	return appleMusicAuthorizationRequiredError()
+}
+
+func (s *ProviderCredentialService) requireReauth(ctx context.Context, account *entities.Account) error {
+	account.RequireReauth()
+	if err := s.accountRepo.Save(ctx, account); err != nil {
+		return err
+	}
+	return reauthRequiredError()
+}
+
+func appleMusicAuthorizationRequiredError() error {
+	return errors.NewAuthenticationError("apple_music_authorization_required", "Apple Music must be authorized")
+}
+
+func reauthRequiredError() error {
+	return errors.NewAuthenticationError("provider_reauth_required", "The provider account must be linked again")
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+type RefreshTokenRequest struct {
+	RefreshToken string
+	UserAgent    string
+	IPAddress    string
+}
+
+type RefreshTokenResponse struct {
+	AccessToken  string
+	RefreshToken string
+	UserID       string
+}
+
+type RefreshTokenUseCase struct {
+	userRepo         repositories.UserRepository
+	tokenRepo        repositories.TokenRepository
+	revokedTokenRepo repositories.RevokedTokenRepository
+	tokenGen         TokenGenerator
+	issuer           *tokenIssuer
+	policy           entities.AuthenticationPolicy
+}
+
+func NewRefreshTokenUseCase(
+	userRepo repositories.UserRepository,
+	tokenRepo repositories.TokenRepository,
+	revokedTokenRepo repositories.RevokedTokenRepository,
+	tokenGen TokenGenerator,
+	policy entities.AuthenticationPolicy,
+) *RefreshTokenUseCase {
+	return &RefreshTokenUseCase{
+		userRepo:         userRepo,
+		tokenRepo:        tokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		tokenGen:         tokenGen,
+		issuer:           newTokenIssuer(tokenRepo, tokenGen),
+		policy:           policy,
+	}
+}
+
+func (uc *RefreshTokenUseCase) Execute(ctx context.Context, req RefreshTokenRequest) (*RefreshTokenResponse, error) {
+	// Only tokens we signed ourselves are considered
+	userIDStr, err := uc.tokenGen.ValidateRefreshToken(req.RefreshToken)
+	if err != nil {
+		return nil, errors.NewAuthenticationError("invalid_refresh_token", "Invalid or expired refresh token")
+	}
+
+	userID, err := valueobjects.ParseUserID(userIDStr)
+	if err != nil {
+		return nil, errors.NewAuthenticationError("invalid_refresh_token", "Invalid refresh token")
+	}
+
+	storedToken, err := uc.tokenRepo.FindRefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); !ok {
+			return nil, err
+		}
+		return nil, uc.notStored(ctx, req.RefreshToken)
+	}
+
+	if !storedToken.UserID().Equals(userID) {
+		return nil, errors.NewAuthenticationError("invalid_refresh_token", "Invalid refresh token")
+	}
+
+	if !storedToken.IsValid() {
+		if err := uc.tokenRepo.DeleteRefreshToken(ctx, storedToken.Token()); err != nil {
+			return nil, err
+		}
+		return nil, errors.NewAuthenticationError("token_expired", "Refresh token has expired")
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, errors.NewAuthenticationError("user_not_found", "User not found")
+	}
+
+	if err := user.CanAuthenticate(uc.policy); err != nil {
+		return nil, err
+	}
+
+	// Rotation: the presented token is consumed atomically with storing the
+	// new one, so of two concurrent refreshes only one gets a pair
+	tokens, err := uc.issuer.rotate(ctx, user, storedToken, valueobjects.NewDeviceInfo(req.UserAgent, req.IPAddress))
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); ok {
+			return nil, uc.reused(ctx, storedToken.SessionID())
+		}
+		return nil, err
+	}
+
+	if storedToken.AccessTokenID() != "" {
+		if err := uc.revokedTokenRepo.Revoke(ctx, storedToken.AccessTokenID(), userID, uc.issuer.accessTokenExpirationTime()); err != nil {
+			return nil, err
+		}
+	}
+
+	return &RefreshTokenResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		UserID:       user.ID().String(),
+	}, nil
+}
+
+// notStored handles a correctly signed token that is no longer stored. Only a
+// token that was rotated is a replay; tokens deleted on logout, session
+// revocation or a password reset are merely invalid
+func (uc *RefreshTokenUseCase) notStored(ctx context.Context, token string) error {
+	sessionID, err := uc.tokenRepo.FindRotatedSession(ctx, token)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); !ok {
+			return err
+		}
+		return errors.NewAuthenticationError("invalid_refresh_token", "Invalid or expired refresh token")
+	}
+	return uc.reused(ctx, sessionID)
+}
+
+// reused handles a rotated token presented again: either the legitimate client
+// or someone who copied the token holds the newer one, so the session the
+// token family belongs to is revoked
+func (uc *RefreshTokenUseCase) reused(ctx context.Context, sessionID valueobjects.SessionID) error {
+	session, err := uc.tokenRepo.FindSession(ctx, sessionID)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); !ok {
+			return err
+		}
+		session = nil
+	}
+
+	if session != nil {
+		if err := revokeSession(ctx, uc.tokenRepo, uc.revokedTokenRepo, session, uc.issuer.accessTokenExpirationTime()); err != nil {
+			return err
+		}
+	}
+	return errors.NewAuthenticationError("refresh_token_reused", "Refresh token has already been used")
+}
@@ -0,0 +1,394 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+// fakeTokenGenerator signs refresh tokens as "refresh:<n>:<userID>"
+type fakeTokenGenerator struct {
+	mu   sync.Mutex
+	next int
+}
+
+func (g *fakeTokenGenerator) GenerateAccessToken(tokenID, userID, email, role string, scopes []string) (string, error) {
+	return "access:" + tokenID, nil
+}
+
+func (g *fakeTokenGenerator) GenerateRefreshToken(userID string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.next++
+	return fmt.Sprintf("refresh:%d:%s", g.next, userID), nil
+}
+
+func (g *fakeTokenGenerator) ValidateRefreshToken(token string) (string, error) {
+	parts := strings.Split(token, ":")
+	if len(parts) != 3 || parts[0] != "refresh" {
+		return "", fmt.Errorf("invalid token")
+	}
+	return parts[2], nil
+}
+
+func (g *fakeTokenGenerator) GetAccessTokenExpiration() int64  { return 900 }
+func (g *fakeTokenGenerator) GetRefreshTokenExpiration() int64 { return 3600 }
+
+type fakeUserRepository struct {
+	users map[string]*entities.User
+}
+
+func (r *fakeUserRepository) Save(ctx context.Context, user *entities.User) error {
+	r.users[user.ID().String()] = user
+	return nil
+}
+
+func (r *fakeUserRepository) FindByID(ctx context.Context, id valueobjects.UserID) (*entities.User, error) {
+	if user, ok := r.users[id.String()]; ok {
+		return user, nil
+	}
+	return nil, errors.NewNotFoundError("user", "User not found")
+}
+
+func (r *fakeUserRepository) FindByEmail(ctx context.Context, email valueobjects.Email) (*entities.User, error) {
+	for _, user := range r.users {
+		if user.Email().Equals(email) {
+			return user, nil
+		}
+	}
+	return nil, errors.NewNotFoundError("user", "User not found")
+}
+
+func (r *fakeUserRepository) Exists(ctx context.Context, email valueobjects.Email) (bool, error) {
+	_, err := r.FindByEmail(ctx, email)
+	return err == nil, nil
+}
+
+func (r *fakeUserRepository) Delete(ctx context.Context, id valueobjects.UserID) error {
+	delete(r.users, id.String())
+	return nil
+}
+
+type fakeTokenRepository struct {
+	tokens  map[string]*entities.RefreshToken
+	rotated map[string]valueobjects.SessionID
+	// beforeRotate runs inside RotateRefreshToken, to interleave a concurrent request
+	beforeRotate func()
+}
+
+func (r *fakeTokenRepository) SaveRefreshToken(ctx context.Context, token *entities.RefreshToken) error {
+	r.tokens[token.Token()] = token
+	return nil
+}
+
+func (r *fakeTokenRepository) FindRefreshToken(ctx context.Context, token string) (*entities.RefreshToken, error) {
+	if stored, ok := r.tokens[token]; ok {
+		return stored, nil
+	}
+	return nil, errors.NewNotFoundError("refresh_token", "Refresh token not found")
+}
+
+func (r *fakeTokenRepository) FindUserRefreshTokens(ctx context.Context, userID valueobjects.UserID) ([]*entities.RefreshToken, error) {
+	var tokens []*entities.RefreshToken
+	for _, token := range r.tokens {
+		if token.UserID().Equals(userID) {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *fakeTokenRepository) FindSession(ctx context.Context, sessionID valueobjects.SessionID) (*entities.RefreshToken, error) {
+	for _, token := range r.tokens {
+		if token.SessionID().Equals(sessionID) {
+			return token, nil
+		}
+	}
+	return nil, errors.NewNotFoundError("session", "Session not found")
+}
+
+func (r *fakeTokenRepository) RotateRefreshToken(ctx context.Context, previous string, next *entities.RefreshToken) error {
+	if r.beforeRotate != nil {
+		r.beforeRotate()
+	}
+	if _, ok := r.tokens[previous]; !ok {
+		return errors.NewNotFoundError("refresh_token", "Refresh token not found")
+	}
+	r.rotated[previous] = r.tokens[previous].SessionID()
+	delete(r.tokens, previous)
+	r.tokens[next.Token()] = next
+	return nil
+}
+
+func (r *fakeTokenRepository) FindRotatedSession(ctx context.Context, token string) (valueobjects.SessionID, error) {
+	if sessionID, ok := r.rotated[token]; ok {
+		return sessionID, nil
+	}
+	return valueobjects.SessionID{}, errors.NewNotFoundError("refresh_token", "Refresh token was not rotated")
+}
+
+func (r *fakeTokenRepository) DeleteRefreshToken(ctx context.Context, token string) error {
+	delete(r.tokens, token)
+	return nil
+}
+
+func (r *fakeTokenRepository) DeleteUserRefreshTokens(ctx context.Context, userID valueobjects.UserID) error {
+	for key, token := range r.tokens {
+		if token.UserID().Equals(userID) {
+			delete(r.tokens, key)
+		}
+	}
+	return nil
+}
+
+func (r *fakeTokenRepository) DeleteSession(ctx context.Context, sessionID valueobjects.SessionID) error {
+	for key, token := range r.tokens {
+		if token.SessionID().Equals(sessionID) {
+			delete(r.tokens, key)
+		}
+	}
+	return nil
+}
+
+func (r *fakeTokenRepository) CleanupExpiredTokens(ctx context.Context) error {
+	return nil
+}
+
+type fakeRevokedTokenRepository struct {
+	revoked map[string]bool
+}
+
+func (r *fakeRevokedTokenRepository) Revoke(ctx context.Context, tokenID string, userID valueobjects.UserID, expiresAt time.Time) error {
+	r.revoked[tokenID] = true
+	return nil
+}
+
+func (r *fakeRevokedTokenRepository) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	return r.revoked[tokenID], nil
+}
+
+func (r *fakeRevokedTokenRepository) CleanupExpired(ctx context.Context) error {
+	return nil
+}
+
+func TestRefreshTokenReuseDetection(t *testing.T) {
+	tests := []struct {
+		name string
+		// run presents tokens to the use case and returns the outcome of the
+		// last request
+		run            func(t *testing.T, f *refreshFixture) error
+		wantCode       string
+		wantSessionCut bool
+	}{
+		{
+			name: "first rotation",
+			run: func(t *testing.T, f *refreshFixture) error {
+				_, err := f.refresh(f.original)
+				return err
+			},
+		},
+		{
+			name: "replay of a rotated token",
+			run: func(t *testing.T, f *refreshFixture) error {
+				if _, err := f.refresh(f.original); err != nil {
+					t.Fatalf("first refresh: %v", err)
+				}
+				_, err := f.refresh(f.original)
+				return err
+			},
+			wantCode:       "refresh_token_reused",
+			wantSessionCut: true,
+		},
+		{
+			name: "concurrent rotation of the same token",
+			run: func(t *testing.T, f *refreshFixture) error {
+				// Another request consumes the token between the lookup and the rotation
+				f.tokens.beforeRotate = func() {
+					f.tokens.beforeRotate = nil
+					if _, err := f.refresh(f.original); err != nil {
+						t.Fatalf("concurrent refresh: %v", err)
+					}
+				}
+				_, err := f.refresh(f.original)
+				return err
+			},
+			wantCode:       "refresh_token_reused",
+			wantSessionCut: true,
+		},
+		{
+			name: "refresh after logout",
+			run: func(t *testing.T, f *refreshFixture) error {
+				logout := NewLogoutUseCase(f.tokens, f.revoked, &fakeTokenGenerator{})
+				if _, err := logout.Execute(context.Background(), LogoutRequest{UserID: f.user.ID().String(), RefreshToken: f.original}); err != nil {
+					t.Fatalf("logout: %v", err)
+				}
+				_, err := f.refresh(f.original)
+				return err
+			},
+			wantCode: "invalid_refresh_token",
+		},
+		{
+			name: "refresh after session revoke",
+			run: func(t *testing.T, f *refreshFixture) error {
+				f.revokeSession(t)
+				_, err := f.refresh(f.original)
+				return err
+			},
+			wantCode: "invalid_refresh_token",
+		},
+		{
+			name: "replay of a rotated token after session revoke",
+			run: func(t *testing.T, f *refreshFixture) error {
+				if _, err := f.refresh(f.original); err != nil {
+					t.Fatalf("first refresh: %v", err)
+				}
+				f.revokeSession(t)
+				_, err := f.refresh(f.original)
+				return err
+			},
+			wantCode: "refresh_token_reused",
+		},
+		{
+			name: "unknown token signed for another user",
+			run: func(t *testing.T, f *refreshFixture) error {
+				forged := strings.Replace(f.original, f.user.ID().String(), valueobjects.NewUserID().String(), 1)
+				_, err := f.refresh(forged)
+				return err
+			},
+			wantCode: "invalid_refresh_token",
+		},
+		{
+			name: "token not signed by us",
+			run: func(t *testing.T, f *refreshFixture) error {
+				_, err := f.refresh("garbage")
+				return err
+			},
+			wantCode: "invalid_refresh_token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newRefreshFixture(t)
+
+			err := tt.run(t, f)
+			assertAuthErrorCode(t, err, tt.wantCode)
+
+			if tt.wantSessionCut {
+				if f.sessionTokens() != 0 {
+					t.Errorf("session of the replayed token was not revoked")
+				}
+				if !f.revoked.revoked[f.originalAccessTokenID] {
+					t.Errorf("access token of the session was not revoked")
+				}
+			}
+			// Sessions on other devices are never affected
+			if _, ok := f.tokens.tokens[f.otherDevice]; !ok {
+				t.Errorf("session on another device was revoked")
+			}
+		})
+	}
+}
+
+type refreshFixture struct {
+	uc                    *RefreshTokenUseCase
+	tokens                *fakeTokenRepository
+	revoked               *fakeRevokedTokenRepository
+	user                  *entities.User
+	original              string
+	originalAccessTokenID string
+	sessionID             valueobjects.SessionID
+	// otherDevice is the refresh token of a second session of the user
+	otherDevice string
+}
+
+func newRefreshFixture(t *testing.T) *refreshFixture {
+	t.Helper()
+
+	user, err := entities.NewUser("user@example.com", "Ada", "Lovelace")
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+
+	users := &fakeUserRepository{users: map[string]*entities.User{user.ID().String(): user}}
+	tokens := &fakeTokenRepository{
+		tokens:  make(map[string]*entities.RefreshToken),
+		rotated: make(map[string]valueobjects.SessionID),
+	}
+	revoked := &fakeRevokedTokenRepository{revoked: make(map[string]bool)}
+	tokenGen := &fakeTokenGenerator{}
+	issuer := newTokenIssuer(tokens, tokenGen)
+
+	issued, err := issuer.issue(context.Background(), user, valueobjects.NewDeviceInfo("test", "203.0.113.7"))
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	other, err := issuer.issue(context.Background(), user, valueobjects.NewDeviceInfo("other", "198.51.100.4"))
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+
+	return &refreshFixture{
+		uc:                    NewRefreshTokenUseCase(users, tokens, revoked, tokenGen, entities.AuthenticationPolicy{}),
+		tokens:                tokens,
+		revoked:               revoked,
+		user:                  user,
+		original:              issued.RefreshToken,
+		originalAccessTokenID: strings.TrimPrefix(issued.AccessToken, "access:"),
+		sessionID:             tokens.tokens[issued.RefreshToken].SessionID(),
+		otherDevice:           other.RefreshToken,
+	}
+}
+
+// revokeSession revokes the session of the original token the way
+// DELETE /me/sessions/:id does
+func (f *refreshFixture) revokeSession(t *testing.T) {
+	t.Helper()
+
+	revoke := NewRevokeSessionUseCase(f.tokens, f.revoked, &fakeTokenGenerator{})
+	_, err := revoke.Execute(context.Background(), RevokeSessionRequest{UserID: f.user.ID().String(), SessionID: f.sessionID.String()})
+	if err != nil {
+		t.Fatalf("revoke session: %v", err)
+	}
+}
+
+// sessionTokens counts the stored tokens of the original session
+func (f *refreshFixture) sessionTokens() int {
+	count := 0
+	for _, token := range f.tokens.tokens {
+		if token.SessionID().Equals(f.sessionID) {
+			count++
+		}
+	}
+	return count
+}
+
+func (f *refreshFixture) refresh(token string) (*RefreshTokenResponse, error) {
+	return f.uc.Execute(context.Background(), RefreshTokenRequest{RefreshToken: token, UserAgent: "test"})
+}
+
+func assertAuthErrorCode(t *testing.T, err error, want string) {
+	t.Helper()
+
+	if want == "" {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+
+	authErr, ok := err.(*errors.AuthenticationError)
+	if !ok {
+		t.Fatalf("error = %v, want authentication error %s", err, want)
+	}
+	if authErr.Code() != want {
+		t.Errorf("error code = %s, want %s", authErr.Code(), want)
+	}
+}
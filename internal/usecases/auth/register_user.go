@@ -5,6 +5,7 @@ import (
 
 	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
 	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
 	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
 	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
 )
@@ -18,17 +19,28 @@ type RegisterUserRequest struct {
 
 type RegisterUserResponse struct {
 	UserID string
+	// VerificationEmailSent is false when the email could not be delivered;
+	// the user can request a new one through the resend endpoint
+	VerificationEmailSent bool
 }
 
 type RegisterUserUseCase struct {
 	userRepo    repositories.UserRepository
 	accountRepo repositories.AccountRepository
+	sender      *emailVerificationSender
 }
 
-func NewRegisterUserUseCase(userRepo repositories.UserRepository, accountRepo repositories.AccountRepository) *RegisterUserUseCase {
+func NewRegisterUserUseCase(
+	userRepo repositories.UserRepository,
+	accountRepo repositories.AccountRepository,
+	verificationRepo repositories.VerificationRepository,
+	mailer providers.Mailer,
+	verificationConfig EmailVerificationConfig,
+) *RegisterUserUseCase {
 	return &RegisterUserUseCase{
 		userRepo:    userRepo,
 		accountRepo: accountRepo,
+		sender:      newEmailVerificationSender(verificationRepo, mailer, verificationConfig),
 	}
 }
 
@@ -72,7 +84,12 @@ func (uc *RegisterUserUseCase) Execute(ctx context.Context, req RegisterUserRequ
 		return nil, err
 	}
 
+	// The account already exists at this point, so a delivery failure must not
+	// fail the registration
+	verificationEmailSent := uc.sender.send(ctx, user) == nil
+
 	return &RegisterUserResponse{
-		UserID: user.ID().String(),
+		UserID:                user.ID().String(),
+		VerificationEmailSent: verificationEmailSent,
 	}, nil
 }
\ No newline at end of file
@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+// PasswordResetConfig configures the links sent to reset a password
+type PasswordResetConfig struct {
+	// URL of the frontend page that asks for the new password; the token is
+	// appended as the "token" query parameter
+	URL        string
+	Expiration time.Duration
+}
+
+type ForgotPasswordRequest struct {
+	Email     string
+	IPAddress string
+}
+
+type ForgotPasswordResponse struct {
+	Success bool
+	Message string
+}
+
+type ForgotPasswordUseCase struct {
+	userRepo         repositories.UserRepository
+	accountRepo      repositories.AccountRepository
+	verificationRepo repositories.VerificationRepository
+	mailer           providers.Mailer
+	throttle         *MailThrottle
+	config           PasswordResetConfig
+}
+
+// NewForgotPasswordUseCase expects a mailer that does not report delivery
+// errors, such as an asynchronous one: they only happen for existing accounts
+func NewForgotPasswordUseCase(
+	userRepo repositories.UserRepository,
+	accountRepo repositories.AccountRepository,
+	verificationRepo repositories.VerificationRepository,
+	mailer providers.Mailer,
+	throttle *MailThrottle,
+	config PasswordResetConfig,
+) *ForgotPasswordUseCase {
+	return &ForgotPasswordUseCase{
+		userRepo:         userRepo,
+		accountRepo:      accountRepo,
+		verificationRepo: verificationRepo,
+		mailer:           mailer,
+		throttle:         throttle,
+		config:           config,
+	}
+}
+
+// Execute answers the same way whether or not the email has a password
+// account, so the endpoint cannot be used to enumerate users. Requests are
+// limited per email and per IP, so it cannot be used to flood a mailbox either
+func (uc *ForgotPasswordUseCase) Execute(ctx context.Context, req ForgotPasswordRequest) (*ForgotPasswordResponse, error) {
+	response := &ForgotPasswordResponse{
+		Success: true,
+		Message: "If an account exists for this email, a password reset link has been sent",
+	}
+
+	email, err := valueobjects.NewEmail(req.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.throttle.allow(ctx, email.String(), req.IPAddress); err != nil {
+		return nil, err
+	}
+
+	account, err := uc.accountRepo.FindUserpassAccountByEmail(ctx, email)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); ok {
+			return response, nil
+		}
+		return nil, err
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, account.UserID())
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the latest link is usable
+	if err := uc.verificationRepo.DeleteByUser(ctx, user.ID(), entities.PasswordResetToken); err != nil {
+		return nil, err
+	}
+
+	token, err := entities.NewPasswordResetToken(user.ID(), uc.config.Expiration)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.verificationRepo.Save(ctx, token); err != nil {
+		return nil, err
+	}
+
+	link, err := withTokenParam(uc.config.URL, token.Token())
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.mailer.Send(ctx, providers.EmailMessage{
+		To:      user.Email().String(),
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nThe link expires in %s and can only be used once. If you did not request a reset, you can ignore this email.\n",
+			user.Profile().Name(),
+			link,
+			uc.config.Expiration,
+		),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+type ResetPasswordRequest struct {
+	Token       string
+	NewPassword string
+}
+
+type ResetPasswordResponse struct {
+	Success bool
+	Message string
+}
+
+type ResetPasswordUseCase struct {
+	userRepo         repositories.UserRepository
+	accountRepo      repositories.AccountRepository
+	verificationRepo repositories.VerificationRepository
+	tokenRepo        repositories.TokenRepository
+	revokedTokenRepo repositories.RevokedTokenRepository
+	tokenGen         TokenGenerator
+}
+
+func NewResetPasswordUseCase(
+	userRepo repositories.UserRepository,
+	accountRepo repositories.AccountRepository,
+	verificationRepo repositories.VerificationRepository,
+	tokenRepo repositories.TokenRepository,
+	revokedTokenRepo repositories.RevokedTokenRepository,
+	tokenGen TokenGenerator,
+) *ResetPasswordUseCase {
+	return &ResetPasswordUseCase{
+		userRepo:         userRepo,
+		accountRepo:      accountRepo,
+		verificationRepo: verificationRepo,
+		tokenRepo:        tokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		tokenGen:         tokenGen,
+	}
+}
+
+func (uc *ResetPasswordUseCase) Execute(ctx context.Context, req ResetPasswordRequest) (*ResetPasswordResponse, error) {
+	verificationToken, err := uc.verificationRepo.FindByToken(ctx, req.Token)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); ok {
+			return nil, errors.NewAuthenticationError("invalid_token", "Invalid password reset token")
+		}
+		return nil, err
+	}
+
+	if err := verificationToken.ValidateForPasswordReset(); err != nil {
+		return nil, err
+	}
+
+	plainPassword, err := valueobjects.NewPlainPassword(req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := plainPassword.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	userID := *verificationToken.UserID()
+
+	account, err := uc.accountRepo.FindByUserIDAndProvider(ctx, userID, entities.UserpassProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	// Consume the token before changing anything so it cannot be replayed
+	if err := verificationToken.MarkAsUsed(); err != nil {
+		return nil, err
+	}
+
+	if err := uc.verificationRepo.Update(ctx, verificationToken); err != nil {
+		return nil, err
+	}
+
+	if err := account.ChangePassword(hashedPassword); err != nil {
+		return nil, err
+	}
+
+	if err := uc.accountRepo.Save(ctx, account); err != nil {
+		return nil, err
+	}
+
+	// Opening the link proves ownership of the mailbox
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsEmailVerified() {
+		user.VerifyEmail()
+		if err := uc.userRepo.Save(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	// Whoever knew the old password may still hold a session
+	expiresAt := time.Now().Add(time.Duration(uc.tokenGen.GetAccessTokenExpiration()) * time.Second)
+	if err := revokeUserSessions(ctx, uc.tokenRepo, uc.revokedTokenRepo, userID, expiresAt); err != nil {
+		return nil, err
+	}
+
+	return &ResetPasswordResponse{
+		Success: true,
+		Message: "Password has been reset, please log in again",
+	}, nil
+}
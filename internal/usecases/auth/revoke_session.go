@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+type RevokeSessionRequest struct {
+	UserID    string
+	SessionID string
+}
+
+type RevokeSessionResponse struct {
+	Success bool
+	Message string
+}
+
+type RevokeSessionUseCase struct {
+	tokenRepo        repositories.TokenRepository
+	revokedTokenRepo repositories.RevokedTokenRepository
+	tokenGen         TokenGenerator
+}
+
+func NewRevokeSessionUseCase(
+	tokenRepo repositories.TokenRepository,
+	revokedTokenRepo repositories.RevokedTokenRepository,
+	tokenGen TokenGenerator,
+) *RevokeSessionUseCase {
+	return &RevokeSessionUseCase{
+		tokenRepo:        tokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		tokenGen:         tokenGen,
+	}
+}
+
+func (uc *RevokeSessionUseCase) Execute(ctx context.Context, req RevokeSessionRequest) (*RevokeSessionResponse, error) {
+	userID, err := valueobjects.ParseUserID(req.UserID)
+	if err != nil {
+		return nil, errors.NewDomainError("invalid_user_id", "Invalid user ID")
+	}
+
+	sessionID, err := valueobjects.ParseSessionID(req.SessionID)
+	if err != nil {
+		return nil, errors.NewValidationError("id", "invalid_session_id", "Invalid session ID")
+	}
+
+	session, err := uc.tokenRepo.FindSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sessions of other users are reported as missing to avoid leaking them
+	if !session.UserID().Equals(userID) {
+		return nil, errors.NewNotFoundError("session", "Session not found")
+	}
+
+	expiresAt := time.Now().Add(time.Duration(uc.tokenGen.GetAccessTokenExpiration()) * time.Second)
+	if err := revokeSession(ctx, uc.tokenRepo, uc.revokedTokenRepo, session, expiresAt); err != nil {
+		return nil, err
+	}
+
+	return &RevokeSessionResponse{
+		Success: true,
+		Message: "Session revoked successfully",
+	}, nil
+}
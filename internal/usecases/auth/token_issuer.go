@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+type issuedTokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// tokenIssuer creates an access/refresh token pair and stores the refresh side.
+// It is shared by every use case that starts or renews a session
+type tokenIssuer struct {
+	tokenRepo repositories.TokenRepository
+	tokenGen  TokenGenerator
+}
+
+func newTokenIssuer(tokenRepo repositories.TokenRepository, tokenGen TokenGenerator) *tokenIssuer {
+	return &tokenIssuer{
+		tokenRepo: tokenRepo,
+		tokenGen:  tokenGen,
+	}
+}
+
+// issue starts a new session for the user on the given device
+func (i *tokenIssuer) issue(ctx context.Context, user *entities.User, device valueobjects.DeviceInfo) (*issuedTokens, error) {
+	return i.save(ctx, user, func(refreshToken, accessTokenID string) (*entities.RefreshToken, error) {
+		return entities.NewRefreshToken(user.ID(), refreshToken, accessTokenID, device, i.refreshTokenExpirationTime())
+	}, i.tokenRepo.SaveRefreshToken)
+}
+
+// rotate replaces the refresh token of an existing session, consuming the
+// previous one in the same transaction. It returns a NotFoundError when the
+// previous token was already consumed, and then nothing is issued
+func (i *tokenIssuer) rotate(
+	ctx context.Context,
+	user *entities.User,
+	previous *entities.RefreshToken,
+	device valueobjects.DeviceInfo,
+) (*issuedTokens, error) {
+	return i.save(ctx, user, func(refreshToken, accessTokenID string) (*entities.RefreshToken, error) {
+		return previous.Rotate(refreshToken, accessTokenID, device, i.refreshTokenExpirationTime())
+	}, func(ctx context.Context, next *entities.RefreshToken) error {
+		return i.tokenRepo.RotateRefreshToken(ctx, previous.Token(), next)
+	})
+}
+
+func (i *tokenIssuer) save(
+	ctx context.Context,
+	user *entities.User,
+	newRefreshToken func(refreshToken, accessTokenID string) (*entities.RefreshToken, error),
+	store func(ctx context.Context, token *entities.RefreshToken) error,
+) (*issuedTokens, error) {
+	accessTokenID := valueobjects.NewTokenID().String()
+
+	accessToken, err := i.tokenGen.GenerateAccessToken(
+		accessTokenID,
+		user.ID().String(),
+		user.Email().String(),
+		string(user.Role()),
+		user.Role().Scopes(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshTokenStr, err := i.tokenGen.GenerateRefreshToken(user.ID().String())
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := newRefreshToken(refreshTokenStr, accessTokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store(ctx, refreshToken); err != nil {
+		return nil, err
+	}
+
+	return &issuedTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshTokenStr,
+	}, nil
+}
+
+// accessTokenExpirationTime is the latest moment an access token issued now
+// can still be valid, used to bound denylist entries
+func (i *tokenIssuer) accessTokenExpirationTime() time.Time {
+	return time.Now().Add(time.Duration(i.tokenGen.GetAccessTokenExpiration()) * time.Second)
+}
+
+func (i *tokenIssuer) refreshTokenExpirationTime() time.Time {
+	return time.Now().Add(time.Duration(i.tokenGen.GetRefreshTokenExpiration()) * time.Second)
+}
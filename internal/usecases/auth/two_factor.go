@@ -0,0 +1,318 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+// MFAConfig configures TOTP two-factor authentication
+type MFAConfig struct {
+	// Issuer is the name authenticator apps display next to the account
+	Issuer string
+	// ChallengeExpiration bounds the time between the password and code steps
+	ChallengeExpiration time.Duration
+}
+
+type StartTOTPEnrollmentRequest struct {
+	UserID string
+}
+
+type StartTOTPEnrollmentResponse struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+type StartTOTPEnrollmentUseCase struct {
+	userRepo      repositories.UserRepository
+	accountRepo   repositories.AccountRepository
+	twoFactorRepo repositories.TwoFactorRepository
+	clock         providers.Clock
+	config        MFAConfig
+}
+
+func NewStartTOTPEnrollmentUseCase(
+	userRepo repositories.UserRepository,
+	accountRepo repositories.AccountRepository,
+	twoFactorRepo repositories.TwoFactorRepository,
+	clock providers.Clock,
+	config MFAConfig,
+) *StartTOTPEnrollmentUseCase {
+	return &StartTOTPEnrollmentUseCase{
+		userRepo:      userRepo,
+		accountRepo:   accountRepo,
+		twoFactorRepo: twoFactorRepo,
+		clock:         clock,
+		config:        config,
+	}
+}
+
+// Execute generates a new secret. Calling it again before confirming replaces
+// the pending secret
+func (uc *StartTOTPEnrollmentUseCase) Execute(ctx context.Context, req StartTOTPEnrollmentRequest) (*StartTOTPEnrollmentResponse, error) {
+	userID, err := valueobjects.ParseUserID(req.UserID)
+	if err != nil {
+		return nil, errors.NewDomainError("invalid_user_id", "Invalid user ID")
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The second factor protects password logins only
+	if _, err := uc.accountRepo.FindByUserIDAndProvider(ctx, userID, entities.UserpassProvider); err != nil {
+		if _, ok := err.(*errors.NotFoundError); ok {
+			return nil, errors.NewDomainError("no_password_account", "Two-factor authentication requires a password account")
+		}
+		return nil, err
+	}
+
+	existing, err := uc.twoFactorRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); !ok {
+			return nil, err
+		}
+		existing = nil
+	}
+
+	if existing != nil && existing.IsEnabled() {
+		return nil, errors.NewDomainError("mfa_already_enabled", "Two-factor authentication is already enabled")
+	}
+
+	twoFactor, err := entities.NewTwoFactor(userID, uc.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.twoFactorRepo.Save(ctx, twoFactor); err != nil {
+		return nil, err
+	}
+
+	return &StartTOTPEnrollmentResponse{
+		Secret:          twoFactor.Secret().String(),
+		ProvisioningURI: twoFactor.Secret().ProvisioningURI(uc.config.Issuer, user.Email().String()),
+	}, nil
+}
+
+type ConfirmTOTPEnrollmentRequest struct {
+	UserID string
+	Code   string
+}
+
+type ConfirmTOTPEnrollmentResponse struct {
+	RecoveryCodes []string
+}
+
+type ConfirmTOTPEnrollmentUseCase struct {
+	twoFactorRepo repositories.TwoFactorRepository
+	clock         providers.Clock
+}
+
+func NewConfirmTOTPEnrollmentUseCase(twoFactorRepo repositories.TwoFactorRepository, clock providers.Clock) *ConfirmTOTPEnrollmentUseCase {
+	return &ConfirmTOTPEnrollmentUseCase{
+		twoFactorRepo: twoFactorRepo,
+		clock:         clock,
+	}
+}
+
+func (uc *ConfirmTOTPEnrollmentUseCase) Execute(ctx context.Context, req ConfirmTOTPEnrollmentRequest) (*ConfirmTOTPEnrollmentResponse, error) {
+	userID, err := valueobjects.ParseUserID(req.UserID)
+	if err != nil {
+		return nil, errors.NewDomainError("invalid_user_id", "Invalid user ID")
+	}
+
+	twoFactor, err := uc.twoFactorRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := twoFactor.Confirm(req.Code, uc.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.twoFactorRepo.Save(ctx, twoFactor); err != nil {
+		return nil, err
+	}
+
+	return &ConfirmTOTPEnrollmentResponse{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+type DisableTOTPRequest struct {
+	UserID string
+	Code   string // TOTP or recovery code
+}
+
+type DisableTOTPResponse struct {
+	Success bool
+	Message string
+}
+
+type DisableTOTPUseCase struct {
+	twoFactorRepo repositories.TwoFactorRepository
+	clock         providers.Clock
+}
+
+func NewDisableTOTPUseCase(twoFactorRepo repositories.TwoFactorRepository, clock providers.Clock) *DisableTOTPUseCase {
+	return &DisableTOTPUseCase{
+		twoFactorRepo: twoFactorRepo,
+		clock:         clock,
+	}
+}
+
+func (uc *DisableTOTPUseCase) Execute(ctx context.Context, req DisableTOTPRequest) (*DisableTOTPResponse, error) {
+	userID, err := valueobjects.ParseUserID(req.UserID)
+	if err != nil {
+		return nil, errors.NewDomainError("invalid_user_id", "Invalid user ID")
+	}
+
+	twoFactor, err := uc.twoFactorRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A stolen access token alone must not be enough to remove the second factor
+	if twoFactor.IsEnabled() {
+		if err := twoFactor.Verify(req.Code, uc.clock.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.twoFactorRepo.Delete(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return &DisableTOTPResponse{
+		Success: true,
+		Message: "Two-factor authentication disabled",
+	}, nil
+}
+
+type VerifyMFARequest struct {
+	MFAToken  string
+	Code      string // TOTP or recovery code
+	UserAgent string
+	IPAddress string
+}
+
+type VerifyMFAResponse struct {
+	AccessToken            string
+	RefreshToken           string
+	UserID                 string
+	RemainingRecoveryCodes int
+}
+
+// VerifyMFAUseCase completes a login that LoginUserUseCase suspended with an
+// MFA challenge. Wrong codes go through the same throttle as wrong passwords
+type VerifyMFAUseCase struct {
+	userRepo         repositories.UserRepository
+	verificationRepo repositories.VerificationRepository
+	twoFactorRepo    repositories.TwoFactorRepository
+	issuer           *tokenIssuer
+	throttle         *LoginThrottle
+	clock            providers.Clock
+	policy           entities.AuthenticationPolicy
+}
+
+func NewVerifyMFAUseCase(
+	userRepo repositories.UserRepository,
+	verificationRepo repositories.VerificationRepository,
+	twoFactorRepo repositories.TwoFactorRepository,
+	tokenRepo repositories.TokenRepository,
+	tokenGen TokenGenerator,
+	throttle *LoginThrottle,
+	clock providers.Clock,
+	policy entities.AuthenticationPolicy,
+) *VerifyMFAUseCase {
+	return &VerifyMFAUseCase{
+		userRepo:         userRepo,
+		verificationRepo: verificationRepo,
+		twoFactorRepo:    twoFactorRepo,
+		issuer:           newTokenIssuer(tokenRepo, tokenGen),
+		throttle:         throttle,
+		clock:            clock,
+		policy:           policy,
+	}
+}
+
+func (uc *VerifyMFAUseCase) Execute(ctx context.Context, req VerifyMFARequest) (*VerifyMFAResponse, error) {
+	challenge, err := uc.verificationRepo.FindByToken(ctx, req.MFAToken)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); ok {
+			return nil, errors.NewAuthenticationError("invalid_mfa_token", "Invalid or expired MFA challenge")
+		}
+		return nil, err
+	}
+
+	if err := challenge.ValidateForMFAChallenge(); err != nil {
+		return nil, err
+	}
+
+	userID := *challenge.UserID()
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, errors.NewAuthenticationError("user_not_found", "User not found")
+	}
+
+	email := user.Email().String()
+	if err := uc.throttle.check(ctx, email, req.IPAddress); err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := uc.twoFactorRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := twoFactor.Verify(req.Code, uc.clock.Now()); err != nil {
+		if _, ok := err.(*errors.AuthenticationError); ok {
+			if err := uc.throttle.registerMFAFailure(ctx, email); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	// Verify ran on a snapshot; the conditional write is what stops a
+	// concurrent request from accepting the same code
+	if err := uc.twoFactorRepo.RecordUse(ctx, twoFactor); err != nil {
+		return nil, err
+	}
+
+	if err := challenge.MarkAsUsed(); err != nil {
+		return nil, err
+	}
+
+	if err := uc.verificationRepo.Update(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	if err := uc.throttle.reset(ctx, email); err != nil {
+		return nil, err
+	}
+
+	if err := user.CanAuthenticate(uc.policy); err != nil {
+		return nil, err
+	}
+
+	tokens, err := uc.issuer.issue(ctx, user, valueobjects.NewDeviceInfo(req.UserAgent, req.IPAddress))
+	if err != nil {
+		return nil, err
+	}
+
+	return &VerifyMFAResponse{
+		AccessToken:            tokens.AccessToken,
+		RefreshToken:           tokens.RefreshToken,
+		UserID:                 user.ID().String(),
+		RemainingRecoveryCodes: twoFactor.RemainingRecoveryCodes(),
+	}, nil
+}
@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
+	"github.com/zandomed/sync-playlist-api/internal/domain/errors"
+	"github.com/zandomed/sync-playlist-api/internal/domain/providers"
+	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
+)
+
+// EmailVerificationConfig configures the links sent to verify an email address
+type EmailVerificationConfig struct {
+	// URL of the frontend page that submits the token to the API; the token is
+	// appended as the "token" query parameter
+	URL        string
+	Expiration time.Duration
+}
+
+// emailVerificationSender issues email verification tokens and mails them.
+// It is shared by registration and the resend endpoint
+type emailVerificationSender struct {
+	verificationRepo repositories.VerificationRepository
+	mailer           providers.Mailer
+	config           EmailVerificationConfig
+}
+
+func newEmailVerificationSender(
+	verificationRepo repositories.VerificationRepository,
+	mailer providers.Mailer,
+	config EmailVerificationConfig,
+) *emailVerificationSender {
+	return &emailVerificationSender{
+		verificationRepo: verificationRepo,
+		mailer:           mailer,
+		config:           config,
+	}
+}
+
+// send replaces any outstanding verification link of the user with a new one
+func (s *emailVerificationSender) send(ctx context.Context, user *entities.User) error {
+	if err := s.verificationRepo.DeleteByUser(ctx, user.ID(), entities.EmailVerificationToken); err != nil {
+		return err
+	}
+
+	token, err := entities.NewEmailVerificationToken(user.ID(), s.config.Expiration)
+	if err != nil {
+		return err
+	}
+
+	if err := s.verificationRepo.Save(ctx, token); err != nil {
+		return err
+	}
+
+	link, err := withTokenParam(s.config.URL, token.Token())
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, providers.EmailMessage{
+		To:      user.Email().String(),
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s. If you did not create an account, you can ignore this email.\n",
+			user.Profile().Name(),
+			link,
+			s.config.Expiration,
+		),
+	})
+}
+
+func withTokenParam(rawURL, token string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+type VerifyEmailRequest struct {
+	Token string
+}
+
+type VerifyEmailResponse struct {
+	UserID string
+	Email  string
+}
+
+type VerifyEmailUseCase struct {
+	userRepo         repositories.UserRepository
+	verificationRepo repositories.VerificationRepository
+}
+
+func NewVerifyEmailUseCase(userRepo repositories.UserRepository, verificationRepo repositories.VerificationRepository) *VerifyEmailUseCase {
+	return &VerifyEmailUseCase{
+		userRepo:         userRepo,
+		verificationRepo: verificationRepo,
+	}
+}
+
+func (uc *VerifyEmailUseCase) Execute(ctx context.Context, req VerifyEmailRequest) (*VerifyEmailResponse, error) {
+	verificationToken, err := uc.verificationRepo.FindByToken(ctx, req.Token)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); ok {
+			return nil, errors.NewAuthenticationError("invalid_token", "Invalid verification token")
+		}
+		return nil, err
+	}
+
+	if err := verificationToken.ValidateForEmailVerification(); err != nil {
+		return nil, err
+	}
+
+	if err := verificationToken.MarkAsUsed(); err != nil {
+		return nil, err
+	}
+
+	if err := uc.verificationRepo.Update(ctx, verificationToken); err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, *verificationToken.UserID())
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsEmailVerified() {
+		user.VerifyEmail()
+		if err := uc.userRepo.Save(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	return &VerifyEmailResponse{
+		UserID: user.ID().String(),
+		Email:  user.Email().String(),
+	}, nil
+}
+
+type ResendVerificationEmailRequest struct {
+	Email     string
+	IPAddress string
+}
+
+type ResendVerificationEmailResponse struct {
+	Success bool
+	Message string
+}
+
+type ResendVerificationEmailUseCase struct {
+	userRepo repositories.UserRepository
+	sender   *emailVerificationSender
+	throttle *MailThrottle
+}
+
+// NewResendVerificationEmailUseCase expects a mailer that does not report
+// delivery errors, such as an asynchronous one: they only happen for
+// unverified accounts
+func NewResendVerificationEmailUseCase(
+	userRepo repositories.UserRepository,
+	verificationRepo repositories.VerificationRepository,
+	mailer providers.Mailer,
+	throttle *MailThrottle,
+	config EmailVerificationConfig,
+) *ResendVerificationEmailUseCase {
+	return &ResendVerificationEmailUseCase{
+		userRepo: userRepo,
+		sender:   newEmailVerificationSender(verificationRepo, mailer, config),
+		throttle: throttle,
+	}
+}
+
+// Execute answers the same way whether or not the email belongs to an
+// unverified account, so the endpoint cannot be used to enumerate users.
+// Requests are limited per email and per IP, like forgot-password
+func (uc *ResendVerificationEmailUseCase) Execute(ctx context.Context, req ResendVerificationEmailRequest) (*ResendVerificationEmailResponse, error) {
+	response := &ResendVerificationEmailResponse{
+		Success: true,
+		Message: "If the account exists and is not verified, a verification email has been sent",
+	}
+
+	email, err := valueobjects.NewEmail(req.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.throttle.allow(ctx, email.String(), req.IPAddress); err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); ok {
+			return response, nil
+		}
+		return nil, err
+	}
+
+	if user.IsEmailVerified() {
+		return response, nil
+	}
+
+	if err := uc.sender.send(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
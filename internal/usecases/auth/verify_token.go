@@ -3,25 +3,48 @@ package auth
 import (
 	"context"
 
+	"github.com/zandomed/sync-playlist-api/internal/domain/entities"
 	"github.com/zandomed/sync-playlist-api/internal/domain/repositories"
+	"github.com/zandomed/sync-playlist-api/internal/domain/valueobjects"
 )
 
 type VerifyTokenRequest struct {
 	Token string
+	// CodeVerifier redeems codes of flows started with a code challenge
+	CodeVerifier string
+	UserAgent    string
+	IPAddress    string
 }
 
 type VerifyTokenResponse struct {
-	Valid  bool
-	UserID string
+	Valid        bool
+	UserID       string
+	AccessToken  string
+	RefreshToken string
 }
 
+// VerifyTokenUseCase exchanges the one-time code the OAuth callback hands to
+// the frontend for a session. The tokens are only issued here, so they never
+// travel in a redirect URL
 type VerifyTokenUseCase struct {
+	userRepo         repositories.UserRepository
 	verificationRepo repositories.VerificationRepository
+	issuer           *tokenIssuer
+	policy           entities.AuthenticationPolicy
 }
 
-func NewVerifyTokenUseCase(verificationRepo repositories.VerificationRepository) *VerifyTokenUseCase {
+func NewVerifyTokenUseCase(
+	userRepo repositories.UserRepository,
+	verificationRepo repositories.VerificationRepository,
+	tokenRepo repositories.TokenRepository,
+	tokenGen TokenGenerator,
+	policy entities.AuthenticationPolicy,
+) *VerifyTokenUseCase {
 	return &VerifyTokenUseCase{
+		userRepo:         userRepo,
 		verificationRepo: verificationRepo,
+		issuer:           newTokenIssuer(tokenRepo, tokenGen),
+		policy:           policy,
 	}
 }
 
@@ -43,6 +66,14 @@ func (uc *VerifyTokenUseCase) Execute(ctx context.Context, req VerifyTokenReques
 		}, nil
 	}
 
+	// Checked before marking it used, so a wrong verifier cannot burn the code
+	if !verificationToken.IssuedToClient(req.CodeVerifier) {
+		return &VerifyTokenResponse{
+			Valid:  false,
+			UserID: "",
+		}, nil
+	}
+
 	// Mark the token as used
 	if err := verificationToken.MarkAsUsed(); err != nil {
 		return &VerifyTokenResponse{
@@ -59,8 +90,25 @@ func (uc *VerifyTokenUseCase) Execute(ctx context.Context, req VerifyTokenReques
 		}, nil
 	}
 
+	user, err := uc.userRepo.FindByID(ctx, *verificationToken.UserID())
+	if err != nil {
+		return nil, err
+	}
+
+	// The user may have been locked between the callback and this exchange
+	if err := user.CanAuthenticate(uc.policy); err != nil {
+		return nil, err
+	}
+
+	tokens, err := uc.issuer.issue(ctx, user, valueobjects.NewDeviceInfo(req.UserAgent, req.IPAddress))
+	if err != nil {
+		return nil, err
+	}
+
 	return &VerifyTokenResponse{
-		Valid:  true,
-		UserID: verificationToken.UserID().String(),
+		Valid:        true,
+		UserID:       user.ID().String(),
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 	}, nil
 }
@@ -5,32 +5,144 @@ import authUC "github.com/zandomed/sync-playlist-api/internal/usecases/auth"
 type AuthUseCases struct {
 	RegisterUserUseCase  *authUC.RegisterUserUseCase
 	LoginUserPassUseCase *authUC.LoginUserUseCase
-	LoginGoogleUseCase   *authUC.LoginGoogleUseCase
-	LoginSpotifyUseCase  *authUC.LoginSpotifyUseCase
-	LinkSpotifyUseCase   *authUC.LinkSpotifyAccountUseCase
-	GetUrlSpotifyUseCase *authUC.GetUrlSpotifyUseCase
-	GetUrlGoogleUseCase  *authUC.GetUrlGoogleUseCase
+	LoginOAuthUseCase    *authUC.LoginOAuthUseCase
+	LinkOAuthUseCase     *authUC.LinkOAuthAccountUseCase
+	GetOAuthURLUseCase   *authUC.GetOAuthURLUseCase
 	VerifyTokenUseCase   *authUC.VerifyTokenUseCase
+	RefreshTokenUseCase  *authUC.RefreshTokenUseCase
+	LogoutUseCase        *authUC.LogoutUseCase
+	LogoutAllUseCase     *authUC.LogoutAllUseCase
+
+	VerifyEmailUseCase             *authUC.VerifyEmailUseCase
+	ResendVerificationEmailUseCase *authUC.ResendVerificationEmailUseCase
+
+	ForgotPasswordUseCase *authUC.ForgotPasswordUseCase
+	ResetPasswordUseCase  *authUC.ResetPasswordUseCase
+	ChangePasswordUseCase *authUC.ChangePasswordUseCase
 }
 
 func NewAuthUseCases(
 	registerUserUC *authUC.RegisterUserUseCase,
 	loginUserUC *authUC.LoginUserUseCase,
-	loginGoogleUC *authUC.LoginGoogleUseCase,
-	loginSpotifyUC *authUC.LoginSpotifyUseCase,
-	linkSpotifyUC *authUC.LinkSpotifyAccountUseCase,
-	getUrlSpotifyUC *authUC.GetUrlSpotifyUseCase,
-	getUrlGoogleUC *authUC.GetUrlGoogleUseCase,
+	loginOAuthUC *authUC.LoginOAuthUseCase,
+	linkOAuthUC *authUC.LinkOAuthAccountUseCase,
+	getOAuthURLUC *authUC.GetOAuthURLUseCase,
 	verifyTokenUC *authUC.VerifyTokenUseCase,
+	refreshTokenUC *authUC.RefreshTokenUseCase,
+	logoutUC *authUC.LogoutUseCase,
+	logoutAllUC *authUC.LogoutAllUseCase,
+	verifyEmailUC *authUC.VerifyEmailUseCase,
+	resendVerificationEmailUC *authUC.ResendVerificationEmailUseCase,
+	forgotPasswordUC *authUC.ForgotPasswordUseCase,
+	resetPasswordUC *authUC.ResetPasswordUseCase,
+	changePasswordUC *authUC.ChangePasswordUseCase,
 ) *AuthUseCases {
 	return &AuthUseCases{
 		RegisterUserUseCase:  registerUserUC,
 		LoginUserPassUseCase: loginUserUC,
-		LoginGoogleUseCase:   loginGoogleUC,
-		LoginSpotifyUseCase:  loginSpotifyUC,
-		LinkSpotifyUseCase:   linkSpotifyUC,
-		GetUrlSpotifyUseCase: getUrlSpotifyUC,
-		GetUrlGoogleUseCase:  getUrlGoogleUC,
+		LoginOAuthUseCase:    loginOAuthUC,
+		LinkOAuthUseCase:     linkOAuthUC,
+		GetOAuthURLUseCase:   getOAuthURLUC,
 		VerifyTokenUseCase:   verifyTokenUC,
+		RefreshTokenUseCase:  refreshTokenUC,
+		LogoutUseCase:        logoutUC,
+		LogoutAllUseCase:     logoutAllUC,
+
+		VerifyEmailUseCase:             verifyEmailUC,
+		ResendVerificationEmailUseCase: resendVerificationEmailUC,
+
+		ForgotPasswordUseCase: forgotPasswordUC,
+		ResetPasswordUseCase:  resetPasswordUC,
+		ChangePasswordUseCase: changePasswordUC,
+	}
+}
+
+type SessionUseCases struct {
+	ListSessionsUseCase  *authUC.ListSessionsUseCase
+	RevokeSessionUseCase *authUC.RevokeSessionUseCase
+}
+
+func NewSessionUseCases(
+	listSessionsUC *authUC.ListSessionsUseCase,
+	revokeSessionUC *authUC.RevokeSessionUseCase,
+) *SessionUseCases {
+	return &SessionUseCases{
+		ListSessionsUseCase:  listSessionsUC,
+		RevokeSessionUseCase: revokeSessionUC,
+	}
+}
+
+type MFAUseCases struct {
+	StartTOTPEnrollmentUseCase   *authUC.StartTOTPEnrollmentUseCase
+	ConfirmTOTPEnrollmentUseCase *authUC.ConfirmTOTPEnrollmentUseCase
+	DisableTOTPUseCase           *authUC.DisableTOTPUseCase
+	VerifyMFAUseCase             *authUC.VerifyMFAUseCase
+}
+
+func NewMFAUseCases(
+	startTOTPEnrollmentUC *authUC.StartTOTPEnrollmentUseCase,
+	confirmTOTPEnrollmentUC *authUC.ConfirmTOTPEnrollmentUseCase,
+	disableTOTPUC *authUC.DisableTOTPUseCase,
+	verifyMFAUC *authUC.VerifyMFAUseCase,
+) *MFAUseCases {
+	return &MFAUseCases{
+		StartTOTPEnrollmentUseCase:   startTOTPEnrollmentUC,
+		ConfirmTOTPEnrollmentUseCase: confirmTOTPEnrollmentUC,
+		DisableTOTPUseCase:           disableTOTPUC,
+		VerifyMFAUseCase:             verifyMFAUC,
+	}
+}
+
+type AccountUseCases struct {
+	ListLinkedAccountsUseCase          *authUC.ListLinkedAccountsUseCase
+	UnlinkAccountUseCase               *authUC.UnlinkAccountUseCase
+	GetAppleMusicDeveloperTokenUseCase *authUC.GetAppleMusicDeveloperTokenUseCase
+	SetAppleMusicTokenUseCase          *authUC.SetAppleMusicTokenUseCase
+}
+
+func NewAccountUseCases(
+	listLinkedAccountsUC *authUC.ListLinkedAccountsUseCase,
+	unlinkAccountUC *authUC.UnlinkAccountUseCase,
+	getAppleMusicDeveloperTokenUC *authUC.GetAppleMusicDeveloperTokenUseCase,
+	setAppleMusicTokenUC *authUC.SetAppleMusicTokenUseCase,
+) *AccountUseCases {
+	return &AccountUseCases{
+		ListLinkedAccountsUseCase:          listLinkedAccountsUC,
+		UnlinkAccountUseCase:               unlinkAccountUC,
+		GetAppleMusicDeveloperTokenUseCase: getAppleMusicDeveloperTokenUC,
+		SetAppleMusicTokenUseCase:          setAppleMusicTokenUC,
+	}
+}
+
+type AdminUseCases struct {
+	ChangeUserRoleUseCase *authUC.ChangeUserRoleUseCase
+}
+
+func NewAdminUseCases(
+	changeUserRoleUC *authUC.ChangeUserRoleUseCase,
+) *AdminUseCases {
+	return &AdminUseCases{
+		ChangeUserRoleUseCase: changeUserRoleUC,
+	}
+}
+
+type APIKeyUseCases struct {
+	CreateAPIKeyUseCase *authUC.CreateAPIKeyUseCase
+	ListAPIKeysUseCase  *authUC.ListAPIKeysUseCase
+	UpdateAPIKeyUseCase *authUC.UpdateAPIKeyUseCase
+	DeleteAPIKeyUseCase *authUC.DeleteAPIKeyUseCase
+}
+
+func NewAPIKeyUseCases(
+	createAPIKeyUC *authUC.CreateAPIKeyUseCase,
+	listAPIKeysUC *authUC.ListAPIKeysUseCase,
+	updateAPIKeyUC *authUC.UpdateAPIKeyUseCase,
+	deleteAPIKeyUC *authUC.DeleteAPIKeyUseCase,
+) *APIKeyUseCases {
+	return &APIKeyUseCases{
+		CreateAPIKeyUseCase: createAPIKeyUC,
+		ListAPIKeysUseCase:  listAPIKeysUC,
+		UpdateAPIKeyUseCase: updateAPIKeyUC,
+		DeleteAPIKeyUseCase: deleteAPIKeyUC,
 	}
 }
@@ -0,0 +1,10 @@
+-- migrations/004_add_token_revocation/down.sql
+-- Created at: 2026-10-17 09:12:41
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_revoked_access_tokens_expires_at;
+
+-- Drop table
+DROP TABLE IF EXISTS revoked_access_tokens;
+
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS access_token_jti;
@@ -0,0 +1,16 @@
+-- migrations/004_add_token_revocation/up.sql
+-- Created at: 2026-10-17 09:12:41
+
+-- jti del access token emitido junto a cada refresh token, para poder revocarlo
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS access_token_jti TEXT;
+
+-- Denylist de access tokens revocados antes de su expiración
+CREATE TABLE IF NOT EXISTS revoked_access_tokens (
+    jti TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Índices para performance
+CREATE INDEX IF NOT EXISTS idx_revoked_access_tokens_expires_at ON revoked_access_tokens(expires_at);
@@ -0,0 +1,12 @@
+-- migrations/005_add_session_metadata/down.sql
+-- Created at: 2026-10-17 10:03:18
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_refresh_tokens_session_id;
+
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS last_used_at,
+    DROP COLUMN IF EXISTS device_label,
+    DROP COLUMN IF EXISTS ip_address,
+    DROP COLUMN IF EXISTS user_agent,
+    DROP COLUMN IF EXISTS session_id;
@@ -0,0 +1,13 @@
+-- migrations/005_add_session_metadata/up.sql
+-- Created at: 2026-10-17 10:03:18
+
+-- Metadata de sesión: el session_id se conserva al rotar el refresh token
+ALTER TABLE refresh_tokens
+    ADD COLUMN IF NOT EXISTS session_id UUID NOT NULL DEFAULT uuid_generate_v4(),
+    ADD COLUMN IF NOT EXISTS user_agent TEXT,
+    ADD COLUMN IF NOT EXISTS ip_address VARCHAR(45),
+    ADD COLUMN IF NOT EXISTS device_label VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+
+-- Índices para performance
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens(session_id);
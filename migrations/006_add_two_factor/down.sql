@@ -0,0 +1,5 @@
+-- migrations/006_add_two_factor/down.sql
+-- Created at: 2026-10-17 11:24:05
+
+DROP TABLE IF EXISTS two_factor_recovery_codes;
+DROP TABLE IF EXISTS user_two_factor;
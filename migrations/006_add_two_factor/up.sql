@@ -0,0 +1,22 @@
+-- migrations/006_add_two_factor/up.sql
+-- Created at: 2026-10-17 11:24:05
+
+-- Segundo factor TOTP; enabled pasa a true al confirmar el primer código
+CREATE TABLE IF NOT EXISTS user_two_factor (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    totp_secret TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Códigos de recuperación de un solo uso, guardados como hash SHA-256
+CREATE TABLE IF NOT EXISTS two_factor_recovery_codes (
+    user_id UUID NOT NULL REFERENCES user_two_factor(user_id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (user_id, code_hash)
+);
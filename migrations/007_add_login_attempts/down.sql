@@ -0,0 +1,4 @@
+-- migrations/007_add_login_attempts/down.sql
+-- Created at: 2026-10-17 12:08:47
+
+DROP TABLE IF EXISTS login_attempts;
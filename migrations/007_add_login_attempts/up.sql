@@ -0,0 +1,13 @@
+-- migrations/007_add_login_attempts/up.sql
+-- Created at: 2026-10-17 12:08:47
+
+-- Intentos fallidos de login por clave ("email:..." o "ip:...") para backoff y bloqueo temporal
+CREATE TABLE IF NOT EXISTS login_attempts (
+    key TEXT PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP WITH TIME ZONE,
+    locked_until TIMESTAMP WITH TIME ZONE
+);
+
+-- Índices para performance
+CREATE INDEX IF NOT EXISTS idx_login_attempts_last_failure_at ON login_attempts(last_failure_at);
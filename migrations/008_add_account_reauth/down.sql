@@ -0,0 +1,5 @@
+-- migrations/008_add_account_reauth/down.sql
+-- Created at: 2026-10-17 13:21:40
+
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS reauth_required;
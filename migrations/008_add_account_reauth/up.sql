@@ -0,0 +1,6 @@
+-- migrations/008_add_account_reauth/up.sql
+-- Created at: 2026-10-17 13:21:40
+
+-- El proveedor revocó el refresh token: el usuario debe volver a autorizar la cuenta
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS reauth_required BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,5 @@
+-- migrations/009_add_oauth_state_intent/down.sql
+-- Created at: 2026-10-17 14:05:12
+
+ALTER TABLE verification_tokens
+    DROP COLUMN IF EXISTS intent;
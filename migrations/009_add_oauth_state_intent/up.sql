@@ -0,0 +1,6 @@
+-- migrations/009_add_oauth_state_intent/up.sql
+-- Created at: 2026-10-17 14:05:12
+
+-- Intención del flujo OAuth ("login" o "link"); en "link" user_id es el usuario que vincula la cuenta
+ALTER TABLE verification_tokens
+    ADD COLUMN IF NOT EXISTS intent VARCHAR(20);
@@ -0,0 +1,7 @@
+-- migrations/010_add_account_subject/down.sql
+-- Created at: 2026-10-17 14:48:30
+
+DROP INDEX IF EXISTS idx_accounts_provider_subject;
+
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS provider_subject;
@@ -0,0 +1,12 @@
+-- migrations/010_add_account_subject/up.sql
+-- Created at: 2026-10-17 14:48:30
+
+-- Identificador estable del usuario en el proveedor (id de Spotify, sub de Google/Apple)
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS provider_subject TEXT;
+
+-- Una identidad del proveedor solo puede pertenecer a un usuario.
+-- Las cuentas anteriores no tienen subject hasta su próximo login
+CREATE UNIQUE INDEX IF NOT EXISTS idx_accounts_provider_subject
+    ON accounts(provider, provider_subject)
+    WHERE provider_subject IS NOT NULL;
@@ -0,0 +1,5 @@
+-- migrations/011_add_oauth_pkce/down.sql
+-- Created at: 2026-10-17 15:32:07
+
+ALTER TABLE verification_tokens
+    DROP COLUMN IF EXISTS code_verifier;
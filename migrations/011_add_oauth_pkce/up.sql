@@ -0,0 +1,6 @@
+-- migrations/011_add_oauth_pkce/up.sql
+-- Created at: 2026-10-17 15:32:07
+
+-- Code verifier PKCE del flujo OAuth, guardado junto al state que lo inició
+ALTER TABLE verification_tokens
+    ADD COLUMN IF NOT EXISTS code_verifier TEXT;
@@ -0,0 +1,7 @@
+-- migrations/012_add_user_roles/down.sql
+-- Created at: 2026-10-17 15:48:20
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS role;
+
+DROP TYPE IF EXISTS user_roles;
@@ -0,0 +1,8 @@
+-- migrations/012_add_user_roles/up.sql
+-- Created at: 2026-10-17 15:48:20
+
+-- Rol del usuario, incluido en los access tokens
+CREATE TYPE user_roles AS ENUM ('user', 'support', 'admin');
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role user_roles NOT NULL DEFAULT 'user';
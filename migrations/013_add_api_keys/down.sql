@@ -0,0 +1,4 @@
+-- migrations/013_add_api_keys/down.sql
+-- Created at: 2026-10-17 16:10:34
+
+DROP TABLE IF EXISTS api_keys;
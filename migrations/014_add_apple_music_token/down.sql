@@ -0,0 +1,5 @@
+-- migrations/014_add_apple_music_token/down.sql
+-- Created at: 2026-10-17 16:42:18
+
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS music_user_token;
@@ -0,0 +1,6 @@
+-- migrations/014_add_apple_music_token/up.sql
+-- Created at: 2026-10-17 16:42:18
+
+-- Music-User-Token de MusicKit para cuentas apple, cifrado como los tokens OAuth
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS music_user_token TEXT;
@@ -0,0 +1,5 @@
+-- migrations/015_add_youtube_provider/down.sql
+-- Created at: 2026-10-17 17:05:51
+
+-- Postgres no permite quitar valores de un enum: solo se borran las cuentas
+DELETE FROM accounts WHERE provider = 'youtube';
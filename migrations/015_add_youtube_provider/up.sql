@@ -0,0 +1,5 @@
+-- migrations/015_add_youtube_provider/up.sql
+-- Created at: 2026-10-17 17:05:51
+
+-- accounts.provider es VARCHAR; el enum se mantiene como catálogo de proveedores
+ALTER TYPE providers ADD VALUE IF NOT EXISTS 'youtube';
@@ -0,0 +1,5 @@
+-- migrations/016_add_deezer_tidal_providers/down.sql
+-- Created at: 2026-10-17 17:38:12
+
+-- Postgres no permite quitar valores de un enum: solo se borran las cuentas
+DELETE FROM accounts WHERE provider IN ('deezer', 'tidal');
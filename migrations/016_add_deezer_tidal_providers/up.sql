@@ -0,0 +1,6 @@
+-- migrations/016_add_deezer_tidal_providers/up.sql
+-- Created at: 2026-10-17 17:38:12
+
+-- accounts.provider es VARCHAR; el enum se mantiene como catálogo de proveedores
+ALTER TYPE providers ADD VALUE IF NOT EXISTS 'deezer';
+ALTER TYPE providers ADD VALUE IF NOT EXISTS 'tidal';
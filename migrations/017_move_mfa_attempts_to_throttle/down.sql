@@ -0,0 +1,5 @@
+-- migrations/017_move_mfa_attempts_to_throttle/down.sql
+-- Created at: 2026-10-17 17:52:40
+
+ALTER TABLE user_two_factor
+    ADD COLUMN IF NOT EXISTS failed_attempts INTEGER NOT NULL DEFAULT 0;
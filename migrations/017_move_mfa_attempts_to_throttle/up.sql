@@ -0,0 +1,7 @@
+-- migrations/017_move_mfa_attempts_to_throttle/up.sql
+-- Created at: 2026-10-17 17:52:40
+
+-- Los códigos MFA erróneos cuentan ahora en login_attempts bajo la clave del email.
+-- totp_secret pasa a guardarse cifrado; los secretos en claro se cifran al leerlos
+ALTER TABLE user_two_factor
+    DROP COLUMN IF EXISTS failed_attempts;
@@ -0,0 +1,6 @@
+-- migrations/018_add_oauth_state_binding/down.sql
+-- Created at: 2026-10-17 17:58:21
+
+ALTER TABLE verification_tokens
+    DROP COLUMN IF EXISTS browser_nonce,
+    DROP COLUMN IF EXISTS provider;
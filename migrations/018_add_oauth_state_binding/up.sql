@@ -0,0 +1,8 @@
+-- migrations/018_add_oauth_state_binding/up.sql
+-- Created at: 2026-10-17 17:58:21
+
+-- Proveedor para el que se emitió el state OAuth y nonce guardado en una cookie
+-- del navegador que inició el flujo; el callback debe presentar ambos
+ALTER TABLE verification_tokens
+    ADD COLUMN IF NOT EXISTS provider VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS browser_nonce TEXT;
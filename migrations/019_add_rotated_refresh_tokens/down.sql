@@ -0,0 +1,6 @@
+-- migrations/019_add_rotated_refresh_tokens/down.sql
+-- Created at: 2026-10-17 18:04:36
+
+DROP INDEX IF EXISTS idx_rotated_refresh_tokens_expires_at;
+
+DROP TABLE IF EXISTS rotated_refresh_tokens;
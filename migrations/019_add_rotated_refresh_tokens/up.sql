@@ -0,0 +1,15 @@
+-- migrations/019_add_rotated_refresh_tokens/up.sql
+-- Created at: 2026-10-17 18:04:36
+
+-- Hash SHA-256 de los refresh tokens ya rotados, guardado hasta su expiración.
+-- Volver a presentar uno de ellos revoca la sesión; los tokens borrados por
+-- logout o revocación no dejan rastro y solo son inválidos
+CREATE TABLE IF NOT EXISTS rotated_refresh_tokens (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    session_id UUID NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Índices para performance
+CREATE INDEX IF NOT EXISTS idx_rotated_refresh_tokens_expires_at ON rotated_refresh_tokens(expires_at);
@@ -0,0 +1,5 @@
+-- migrations/020_add_apple_music_provider/down.sql
+-- Created at: 2026-10-17 18:12:47
+
+-- Postgres no permite quitar valores de un enum: solo se borran las cuentas
+DELETE FROM accounts WHERE provider = 'apple_music';
@@ -0,0 +1,6 @@
+-- migrations/020_add_apple_music_provider/up.sql
+-- Created at: 2026-10-17 18:12:47
+
+-- Cuenta propia para el Music-User-Token, sin depender de Sign in with Apple.
+-- Los tokens ya guardados en cuentas apple se siguen leyendo desde ahí
+ALTER TYPE providers ADD VALUE IF NOT EXISTS 'apple_music';
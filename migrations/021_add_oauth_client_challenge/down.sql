@@ -0,0 +1,5 @@
+-- migrations/021_add_oauth_client_challenge/down.sql
+-- Created at: 2026-10-17 18:21:09
+
+ALTER TABLE verification_tokens
+    DROP COLUMN IF EXISTS client_challenge;
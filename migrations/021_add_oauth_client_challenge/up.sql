@@ -0,0 +1,7 @@
+-- migrations/021_add_oauth_client_challenge/up.sql
+-- Created at: 2026-10-17 18:21:09
+
+-- Challenge S256 de los clientes sin cookies (apps nativas): liga el state OAuth
+-- y el código de un solo uso al cliente que conoce el verifier
+ALTER TABLE verification_tokens
+    ADD COLUMN IF NOT EXISTS client_challenge TEXT;
@@ -0,0 +1,193 @@
+// Package envelope encrypts small secrets (OAuth tokens) at rest with envelope
+// encryption: every value gets its own random data key, which is wrapped with
+// a long-lived key-encryption key identified by a key ID. Rotating the
+// key-encryption key only requires adding a new one; values written with
+// retired keys stay readable until they are rewritten
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zandomed/sync-playlist-api/internal/config"
+)
+
+const (
+	// version prefixes every ciphertext so the format can evolve
+	version = "v1"
+	// keySize selects AES-256 for both data and key-encryption keys
+	keySize = 32
+)
+
+var ErrMalformedCiphertext = errors.New("envelope: malformed ciphertext")
+
+// Cipher encrypts with the active key and decrypts with any known key
+type Cipher struct {
+	activeKeyID string
+	keys        map[string][]byte
+}
+
+// NewCipher builds a cipher from 32-byte key-encryption keys indexed by key ID
+func NewCipher(activeKeyID string, keys map[string][]byte) (*Cipher, error) {
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("envelope: active key %q not found", activeKeyID)
+	}
+
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ".") {
+			return nil, fmt.Errorf("envelope: invalid key id %q", id)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("envelope: key %q must be %d bytes", id, keySize)
+		}
+	}
+
+	return &Cipher{activeKeyID: activeKeyID, keys: keys}, nil
+}
+
+// Load reads the keys from cfg.Keys, a comma separated list of
+// "<kid>:<base64 key>" pairs. With a single key cfg.ActiveKeyID may be left
+// empty. Errors name entries by position so key material never reaches logs
+func Load(cfg *config.TokenEncryptionConfig) (*Cipher, error) {
+	keys := make(map[string][]byte)
+
+	for i, pair := range strings.Split(cfg.Keys, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		id, encoded, found := strings.Cut(pair, ":")
+		if !found {
+			return nil, fmt.Errorf("envelope: key entry %d must be <kid>:<base64 key>", i+1)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("envelope: key %q is not valid base64: %w", id, err)
+		}
+		keys[id] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("envelope: no encryption keys configured")
+	}
+
+	activeKeyID := cfg.ActiveKeyID
+	if activeKeyID == "" && len(keys) == 1 {
+		for id := range keys {
+			activeKeyID = id
+		}
+	}
+
+	return NewCipher(activeKeyID, keys)
+}
+
+// Encrypt seals plaintext and returns "v1.<kid>.<wrapped data key>.<ciphertext>".
+// associatedData is authenticated but not stored: the same value must be
+// passed to Decrypt, which binds the ciphertext to its owner
+func (c *Cipher) Encrypt(plaintext, associatedData []byte) (string, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("envelope: failed to generate data key: %w", err)
+	}
+
+	wrappedKey, err := seal(c.keys[c.activeKeyID], dataKey, []byte(c.activeKeyID))
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := seal(dataKey, plaintext, associatedData)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{
+		version,
+		c.activeKeyID,
+		base64.RawURLEncoding.EncodeToString(wrappedKey),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+	}, "."), nil
+}
+
+// Decrypt opens a value produced by Encrypt with any of the configured keys
+func (c *Cipher) Decrypt(encoded string, associatedData []byte) ([]byte, error) {
+	parts := strings.Split(encoded, ".")
+	if len(parts) != 4 || parts[0] != version {
+		return nil, ErrMalformedCiphertext
+	}
+
+	keyID := parts[1]
+	key, ok := c.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("envelope: unknown key %q", keyID)
+	}
+
+	wrappedKey, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedCiphertext
+	}
+
+	ciphertext, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil, ErrMalformedCiphertext
+	}
+
+	dataKey, err := open(key, wrappedKey, []byte(keyID))
+	if err != nil {
+		return nil, err
+	}
+
+	return open(dataKey, ciphertext, associatedData)
+}
+
+// ActiveKeyID is the key new values are encrypted with
+func (c *Cipher) ActiveKeyID() string {
+	return c.activeKeyID
+}
+
+// seal encrypts with AES-GCM and prepends the random nonce
+func seal(key, plaintext, associatedData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("envelope: failed to generate nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+func open(key, sealed, associatedData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformedCiphertext
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, associatedData)
+	if err != nil {
+		return nil, errors.New("envelope: message authentication failed")
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("envelope: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
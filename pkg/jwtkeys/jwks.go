@@ -0,0 +1,88 @@
+package jwtkeys
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"sort"
+)
+
+// JSONWebKey is the public part of a key as described in RFC 7517
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC and OKP
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS publishes every asymmetric key of the ring. Retired keys stay in the set
+// so tokens they signed can be verified until they expire
+func (r *KeyRing) JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+
+	for _, key := range r.keys {
+		if jwk, ok := toJSONWebKey(key); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	// Active key first, then by kid, so the output is stable
+	sort.Slice(set.Keys, func(i, j int) bool {
+		if set.Keys[i].KeyID == r.active.id {
+			return true
+		}
+		if set.Keys[j].KeyID == r.active.id {
+			return false
+		}
+		return set.Keys[i].KeyID < set.Keys[j].KeyID
+	})
+
+	return set
+}
+
+func toJSONWebKey(key *Key) (JSONWebKey, bool) {
+	jwk := JSONWebKey{
+		KeyID:     key.id,
+		Use:       "sig",
+		Algorithm: key.method.Alg(),
+	}
+
+	switch publicKey := key.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = encodeBase64URL(publicKey.N.Bytes())
+		jwk.E = encodeBase64URL(big.NewInt(int64(publicKey.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		jwk.KeyType = "EC"
+		jwk.Curve = publicKey.Curve.Params().Name
+		jwk.X = encodeBase64URL(publicKey.X.FillBytes(make([]byte, size)))
+		jwk.Y = encodeBase64URL(publicKey.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = encodeBase64URL(publicKey)
+	default:
+		// Symmetric keys are secrets and must never be published
+		return JSONWebKey{}, false
+	}
+
+	return jwk, true
+}
+
+func encodeBase64URL(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,298 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/zandomed/sync-playlist-api/internal/config"
+)
+
+// Key is a single signing/verification key identified by its kid
+type Key struct {
+	id         string
+	method     jwt.SigningMethod
+	signingKey interface{} // nil for verification-only (retired) keys
+	verifyKey  interface{}
+}
+
+func (k *Key) ID() string {
+	return k.id
+}
+
+func (k *Key) Algorithm() string {
+	return k.method.Alg()
+}
+
+func (k *Key) CanSign() bool {
+	return k.signingKey != nil
+}
+
+// NewHMACKey creates a symmetric HS256 key. Symmetric keys are never published
+func NewHMACKey(id string, secret []byte) *Key {
+	return &Key{
+		id:         id,
+		method:     jwt.SigningMethodHS256,
+		signingKey: secret,
+		verifyKey:  secret,
+	}
+}
+
+// ParsePrivateKeyPEM parses an RSA (RS256), P-256 (ES256) or Ed25519 (EdDSA)
+// private key in PKCS#8, PKCS#1 or SEC 1 form
+func ParsePrivateKeyPEM(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %q: no PEM block found", id)
+	}
+
+	var privateKey interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		privateKey, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("key %q: unsupported PEM block type %q", id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", id, err)
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("key %q: unsupported private key type %T", id, privateKey)
+	}
+
+	method, err := methodForPublicKey(signer.Public())
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", id, err)
+	}
+
+	return &Key{
+		id:         id,
+		method:     method,
+		signingKey: privateKey,
+		verifyKey:  signer.Public(),
+	}, nil
+}
+
+// ParsePublicKeyPEM parses a PKIX public key. Such keys can only verify
+// tokens, which is what retired keys are kept for
+func ParsePublicKeyPEM(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %q: no PEM block found", id)
+	}
+
+	if block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("key %q: unsupported PEM block type %q", id, block.Type)
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", id, err)
+	}
+
+	method, err := methodForPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", id, err)
+	}
+
+	return &Key{
+		id:        id,
+		method:    method,
+		verifyKey: publicKey,
+	}, nil
+}
+
+func methodForPublicKey(publicKey crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return nil, errors.New("only P-256 curves are supported for ES256")
+		}
+		return jwt.SigningMethodES256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// KeyRing holds the active signing key and the retired keys that are still
+// accepted for verification, all addressed by kid
+type KeyRing struct {
+	active *Key
+	keys   map[string]*Key
+	// legacy verifies tokens issued before kids were introduced (no kid header)
+	legacy *Key
+}
+
+func NewKeyRing(active *Key, retired ...*Key) (*KeyRing, error) {
+	if active == nil || !active.CanSign() {
+		return nil, errors.New("active key must be able to sign")
+	}
+
+	ring := &KeyRing{
+		active: active,
+		keys:   map[string]*Key{active.id: active},
+	}
+
+	for _, key := range retired {
+		if _, exists := ring.keys[key.id]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", key.id)
+		}
+		ring.keys[key.id] = key
+	}
+
+	return ring, nil
+}
+
+// WithLegacyKey accepts tokens without a kid header when they verify with key
+func (r *KeyRing) WithLegacyKey(key *Key) *KeyRing {
+	r.legacy = key
+	return r
+}
+
+// Load builds the key ring from configuration. With JWT_KEYS_DIR unset the
+// ring falls back to HS256 with JWT_SECRET. Otherwise every <kid>.pem file in
+// the directory is loaded and JWT_ACTIVE_KEY_ID selects the signing key
+func Load(cfg *config.JWTConfig) (*KeyRing, error) {
+	secretKey := NewHMACKey("", []byte(cfg.Secret))
+
+	if cfg.KeysDir == "" {
+		ring, err := NewKeyRing(secretKey)
+		if err != nil {
+			return nil, err
+		}
+		return ring.WithLegacyKey(secretKey), nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(cfg.KeysDir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list JWT keys: %w", err)
+	}
+
+	var active *Key
+	var retired []*Key
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT key %s: %w", file, err)
+		}
+
+		id := strings.TrimSuffix(filepath.Base(file), ".pem")
+
+		var key *Key
+		if strings.Contains(string(data), "PUBLIC KEY") {
+			key, err = ParsePublicKeyPEM(id, data)
+		} else {
+			key, err = ParsePrivateKeyPEM(id, data)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if id == cfg.ActiveKeyID {
+			active = key
+		} else {
+			retired = append(retired, key)
+		}
+	}
+
+	if active == nil {
+		return nil, fmt.Errorf("active JWT key %q not found in %s", cfg.ActiveKeyID, cfg.KeysDir)
+	}
+
+	ring, err := NewKeyRing(active, retired...)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.AcceptLegacySecret {
+		ring.WithLegacyKey(secretKey)
+	}
+
+	return ring, nil
+}
+
+// Sign signs the claims with the active key and stamps its kid in the header
+func (r *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(r.active.method, claims)
+	if r.active.id != "" {
+		token.Header["kid"] = r.active.id
+	}
+	return token.SignedString(r.active.signingKey)
+}
+
+// Keyfunc resolves the verification key of a token from its kid, rejecting
+// tokens whose algorithm does not match the key (algorithm confusion)
+func (r *KeyRing) Keyfunc(token *jwt.Token) (interface{}, error) {
+	var key *Key
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		key = r.legacy
+	} else {
+		key = r.keys[kid]
+	}
+
+	if key == nil {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+	}
+
+	return key.verifyKey, nil
+}
+
+// ValidMethods lists the algorithms of every key in the ring, for use with
+// jwt.WithValidMethods
+func (r *KeyRing) ValidMethods() []string {
+	seen := make(map[string]bool)
+	var methods []string
+
+	keys := make([]*Key, 0, len(r.keys)+1)
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	if r.legacy != nil {
+		keys = append(keys, r.legacy)
+	}
+
+	for _, key := range keys {
+		if !seen[key.method.Alg()] {
+			seen[key.method.Alg()] = true
+			methods = append(methods, key.method.Alg())
+		}
+	}
+	return methods
+}
+
+// Parse verifies a token against the ring and fills claims
+func (r *KeyRing) Parse(tokenString string, claims jwt.Claims, options ...jwt.ParserOption) (*jwt.Token, error) {
+	options = append(options, jwt.WithValidMethods(r.ValidMethods()))
+	return jwt.ParseWithClaims(tokenString, claims, r.Keyfunc, options...)
+}
@@ -0,0 +1,218 @@
+package jwtkeys
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRefreshInterval stops tokens with made-up kids from forcing a fetch of
+// the remote key set on every request
+const minRefreshInterval = time.Minute
+
+// JWKSFetcher downloads a JSON Web Key Set. Tests inject a fetcher that
+// returns fixed keys instead of calling the network
+type JWKSFetcher func(ctx context.Context) (*JSONWebKeySet, error)
+
+// NewHTTPJWKSFetcher fetches the key set published at url
+func NewHTTPJWKSFetcher(client *http.Client, url string) JWKSFetcher {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return func(ctx context.Context) (*JSONWebKeySet, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+		}
+
+		var set JSONWebKeySet
+		if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+			return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+		}
+		return &set, nil
+	}
+}
+
+// RemoteKeySet verifies tokens signed by a third party (e.g. Apple) with the
+// keys it publishes. Keys are cached for ttl and refetched early when a token
+// references an unknown kid, which is how providers announce rotations
+type RemoteKeySet struct {
+	fetcher JWKSFetcher
+	ttl     time.Duration
+
+	mu        sync.Mutex
+	keys      map[string]*Key
+	fetchedAt time.Time
+}
+
+func NewRemoteKeySet(fetcher JWKSFetcher, ttl time.Duration) *RemoteKeySet {
+	return &RemoteKeySet{
+		fetcher: fetcher,
+		ttl:     ttl,
+		keys:    make(map[string]*Key),
+	}
+}
+
+// Parse verifies a token against the remote keys and fills claims
+func (s *RemoteKeySet) Parse(ctx context.Context, tokenString string, claims jwt.Claims, options ...jwt.ParserOption) (*jwt.Token, error) {
+	keyfunc := func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, fmt.Errorf("token has no kid header")
+		}
+
+		key, err := s.key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+		}
+		return key.verifyKey, nil
+	}
+
+	// Remote keys are always asymmetric, so HMAC tokens are never accepted
+	options = append(options, jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}))
+	return jwt.ParseWithClaims(tokenString, claims, keyfunc, options...)
+}
+
+func (s *RemoteKeySet) key(ctx context.Context, kid string) (*Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	key, known := s.keys[kid]
+	stale := now.Sub(s.fetchedAt) > s.ttl
+
+	if stale || (!known && now.Sub(s.fetchedAt) > minRefreshInterval) {
+		if err := s.refresh(ctx, now); err != nil {
+			// A stale cache is better than failing every login while the
+			// provider is unreachable
+			if !known {
+				return nil, err
+			}
+		} else {
+			key, known = s.keys[kid]
+		}
+	}
+
+	if !known {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (s *RemoteKeySet) refresh(ctx context.Context, now time.Time) error {
+	set, err := s.fetcher(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*Key, len(set.Keys))
+	for _, jwk := range set.Keys {
+		// Keys we cannot use (encryption keys, unsupported types) are skipped
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.Key()
+		if err != nil {
+			continue
+		}
+		keys[key.id] = key
+	}
+
+	s.keys = keys
+	s.fetchedAt = now
+	return nil
+}
+
+// Key converts the JSON Web Key into a verification-only Key
+func (jwk JSONWebKey) Key() (*Key, error) {
+	var publicKey interface{}
+
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := decodeBase64URL(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: invalid modulus: %w", jwk.KeyID, err)
+		}
+		e, err := decodeBase64URL(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: invalid exponent: %w", jwk.KeyID, err)
+		}
+		publicKey = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	case "EC":
+		if jwk.Curve != "P-256" {
+			return nil, fmt.Errorf("key %q: unsupported curve %q", jwk.KeyID, jwk.Curve)
+		}
+		x, err := decodeBase64URL(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: invalid x coordinate: %w", jwk.KeyID, err)
+		}
+		y, err := decodeBase64URL(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: invalid y coordinate: %w", jwk.KeyID, err)
+		}
+		publicKey = &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+	case "OKP":
+		if jwk.Curve != "Ed25519" {
+			return nil, fmt.Errorf("key %q: unsupported curve %q", jwk.KeyID, jwk.Curve)
+		}
+		x, err := decodeBase64URL(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("key %q: invalid public key", jwk.KeyID)
+		}
+		publicKey = ed25519.PublicKey(x)
+	default:
+		return nil, fmt.Errorf("key %q: unsupported key type %q", jwk.KeyID, jwk.KeyType)
+	}
+
+	method, err := methodForPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", jwk.KeyID, err)
+	}
+
+	if jwk.Algorithm != "" && jwk.Algorithm != method.Alg() {
+		return nil, fmt.Errorf("key %q: algorithm %s does not match key type", jwk.KeyID, jwk.Algorithm)
+	}
+
+	return &Key{
+		id:        jwk.KeyID,
+		method:    method,
+		verifyKey: publicKey,
+	}, nil
+}
+
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}